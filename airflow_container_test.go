@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	goversion "github.com/hashicorp/go-version"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// airflowContainerVersionEnv selects the apache/airflow image tag to
+	// start for the acceptance test run, e.g. "2.9.3" or "3.0.2".
+	airflowContainerVersionEnv = "AIRFLOW_TEST_CONTAINER_VERSION"
+
+	airflowContainerUsername = "airflow"
+	airflowContainerPassword = "airflow"
+	airflowContainerPort     = "8080/tcp"
+)
+
+// TestMain starts a disposable Airflow instance for the acceptance test run
+// when TF_ACC and AIRFLOW_TEST_CONTAINER_VERSION are set and no external
+// AIRFLOW_BASE_ENDPOINT is configured.
+func TestMain(m *testing.M) {
+	version := os.Getenv(airflowContainerVersionEnv)
+	if os.Getenv("TF_ACC") == "" || version == "" || os.Getenv("AIRFLOW_BASE_ENDPOINT") != "" {
+		os.Exit(m.Run())
+	}
+
+	ctx := context.Background()
+	container, endpoint, err := startAirflowContainer(ctx, version)
+	if err != nil {
+		log.Fatalf("failed to start Airflow %s container: %s", version, err)
+	}
+
+	os.Setenv("AIRFLOW_BASE_ENDPOINT", endpoint)
+	if airflowContainerIsAirflow3(version) {
+		// The API of Airflow 3 only accepts the tokens it issues.
+		token, err := airflowContainerToken(endpoint)
+		if err != nil {
+			_ = container.Terminate(ctx)
+			log.Fatalf("failed to get a token of the Airflow %s container: %s", version, err)
+		}
+		os.Setenv("AIRFLOW_OAUTH2_TOKEN", token)
+	} else {
+		os.Setenv("AIRFLOW_API_USERNAME", airflowContainerUsername)
+		os.Setenv("AIRFLOW_API_PASSWORD", airflowContainerPassword)
+	}
+
+	code := m.Run()
+
+	if err := container.Terminate(ctx); err != nil {
+		log.Printf("[WARN] failed to terminate Airflow container: %s", err)
+	}
+
+	os.Exit(code)
+}
+
+// startAirflowContainer runs the given apache/airflow image version with the
+// admin user of the acceptance tests and the example DAGs loaded, and returns
+// the container together with its base endpoint. Airflow 2 runs in
+// standalone mode with basic auth enabled. Airflow 3 runs the api-server
+// next to the scheduler, DAG processor and triggerer, with the FAB auth
+// manager so that users and roles can be managed.
+func startAirflowContainer(ctx context.Context, version string) (testcontainers.Container, string, error) {
+	env := map[string]string{
+		"AIRFLOW__CORE__LOAD_EXAMPLES":                  "True",
+		"_AIRFLOW_DB_MIGRATE":                           "true",
+		"_AIRFLOW_DB_UPGRADE":                           "true",
+		"_AIRFLOW_WWW_USER_CREATE":                      "true",
+		"_AIRFLOW_WWW_USER_USERNAME":                    airflowContainerUsername,
+		"_AIRFLOW_WWW_USER_PASSWORD":                    airflowContainerPassword,
+		"AIRFLOW__SCHEDULER__MIN_FILE_PROCESS_INTERVAL": "5",
+	}
+	cmd := []string{"standalone"}
+	health := "/health"
+
+	if airflowContainerIsAirflow3(version) {
+		env["AIRFLOW__CORE__AUTH_MANAGER"] = "airflow.providers.fab.auth_manager.fab_auth_manager.FabAuthManager"
+		env["AIRFLOW__FAB__AUTH_BACKENDS"] = "airflow.providers.fab.auth_manager.api.auth.backend.basic_auth,airflow.providers.fab.auth_manager.api.auth.backend.session"
+		// The token of the provider has to outlive the test run.
+		env["AIRFLOW__API_AUTH__JWT_EXPIRATION_TIME"] = "7200"
+		env["AIRFLOW__API__WORKERS"] = "2"
+		cmd = []string{"bash", "-c", "airflow scheduler & airflow dag-processor & airflow triggerer & exec airflow api-server"}
+		health = "/api/v2/monitor/health"
+	} else {
+		env["AIRFLOW__API__AUTH_BACKENDS"] = "airflow.api.auth.backend.basic_auth,airflow.api.auth.backend.session"
+		env["AIRFLOW__WEBSERVER__WORKERS"] = "2"
+	}
+
+	req := testcontainers.ContainerRequest{
+		Image:        fmt.Sprintf("apache/airflow:%s", version),
+		ExposedPorts: []string{airflowContainerPort},
+		Cmd:          cmd,
+		Env:          env,
+		WaitingFor: wait.ForHTTP(health).
+			WithPort(airflowContainerPort).
+			WithStartupTimeout(5 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, airflowContainerPort, "http")
+	if err != nil {
+		_ = container.Terminate(ctx)
+		return nil, "", err
+	}
+
+	return container, endpoint, nil
+}
+
+// airflowContainerIsAirflow3 reports whether the image version is Airflow 3
+// or later, e.g. "3.0.2" or "3.0.2-python3.12".
+func airflowContainerIsAirflow3(version string) bool {
+	v, err := goversion.NewVersion(version)
+	return err == nil && !v.LessThan(airflow3)
+}
+
+// airflowContainerToken returns an API token of the admin user of the
+// acceptance tests, which Airflow 3 issues for its password.
+func airflowContainerToken(endpoint string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"username": airflowContainerUsername,
+		"password": airflowContainerPassword,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(endpoint+"/auth/token", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("POST /auth/token: %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+	return token.AccessToken, nil
+}
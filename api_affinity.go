@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+type backendAffinityContextKey struct{}
+
+// withBackendAffinity attaches a cookie jar to the context of an operation.
+// Load balancers in front of several webserver replicas pin clients to a
+// backend with a sticky session cookie, so replaying the cookies they set
+// within the operation makes its reads see its own writes.
+func withBackendAffinity(ctx context.Context) context.Context {
+	// cookiejar.New only fails for an invalid public suffix list.
+	jar, _ := cookiejar.New(nil)
+	return context.WithValue(ctx, backendAffinityContextKey{}, jar)
+}
+
+// backendAffinityTransport replays the cookies of the operation a request is
+// made in. When header is set, the request also carries the correlation ID
+// of the operation in it, for load balancers that pin by hashing a header.
+type backendAffinityTransport struct {
+	next   http.RoundTripper
+	header string
+}
+
+func (t *backendAffinityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jar, ok := req.Context().Value(backendAffinityContextKey{}).(http.CookieJar)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for _, c := range jar.Cookies(req.URL) {
+		req.AddCookie(c)
+	}
+	if id, ok := req.Context().Value(correlationIdContextKey{}).(string); ok && t.header != "" {
+		req.Header.Set(t.header, id)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if resp != nil {
+		if cookies := resp.Cookies(); len(cookies) > 0 {
+			jar.SetCookies(req.URL, cookies)
+		}
+	}
+
+	return resp, err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestBackendAffinityTransport_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.BackendAffinity = true
+
+	var cookies []string
+	fake.handle(http.MethodGet, "/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("AWSALB")
+		if err != nil {
+			cookies = append(cookies, "")
+			http.SetCookie(w, &http.Cookie{Name: "AWSALB", Value: "backend-1", Path: "/"})
+		} else {
+			cookies = append(cookies, c.Value)
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"key": "foo", "value": "bar"})
+	})
+
+	read := func(d *schema.ResourceData, m interface{}) error {
+		pcfg := m.(ProviderConfig)
+		for i := 0; i < 2; i++ {
+			if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{})
+	for i := 0; i < 2; i++ {
+		if err := wrapOperation("airflow_variable", read)(d, m); err != nil {
+			t.Fatalf("operation: %s", err)
+		}
+	}
+
+	// Each operation starts without cookies and replays the ones it got.
+	expected := []string{"", "backend-1", "", "backend-1"}
+	if len(cookies) != len(expected) {
+		t.Fatalf("expected %d requests, got %v", len(expected), cookies)
+	}
+	for i := range expected {
+		if cookies[i] != expected[i] {
+			t.Fatalf("expected cookies %v, got %v", expected, cookies)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestProviderConfig_fakeClientCredentials(t *testing.T) {
+	fake := newFakeAirflow(t)
+
+	tokens := 0
+	expiresIn := 3600
+	fake.handle(http.MethodPost, "/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("unexpected grant type %q", got)
+		}
+		if id, secret, _ := r.BasicAuth(); id != "terraform" || secret != "secret" {
+			t.Errorf("unexpected client credentials %q", id)
+		}
+		if got := r.PostForm.Get("scope"); got != "airflow.api" {
+			t.Errorf("unexpected scope %q", got)
+		}
+		tokens++
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", tokens),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	})
+
+	var authorization []string
+	fake.handle(http.MethodGet, "/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"key": "foo", "value": "bar"})
+	})
+
+	pcfg := fake.providerConfigWith(t, map[string]interface{}{
+		"username":       "",
+		"password":       "",
+		"token_endpoint": fake.URL + "/oauth/token",
+		"client_id":      "terraform",
+		"client_secret":  "secret",
+		"scopes":         []interface{}{"airflow.api"},
+		"max_retries":    0,
+	})
+
+	// The token is reused by the generated client and raw requests alike.
+	if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+	if _, err := apiRequest(pcfg, http.MethodGet, "/variables/foo", url.Values{}, nil, nil); err != nil {
+		t.Fatalf("raw get variable: %s", err)
+	}
+	if tokens != 1 {
+		t.Fatalf("expected a single token request, got %d", tokens)
+	}
+	for _, got := range authorization {
+		if got != "Bearer token-1" {
+			t.Fatalf("unexpected authorization %q", got)
+		}
+	}
+
+	// Tokens about to expire are refreshed before the next call.
+	expiresIn = 1
+	pcfg = fake.providerConfigWith(t, map[string]interface{}{
+		"username":       "",
+		"password":       "",
+		"token_endpoint": fake.URL + "/oauth/token",
+		"client_id":      "terraform",
+		"client_secret":  "secret",
+		"scopes":         []interface{}{"airflow.api"},
+		"max_retries":    0,
+	})
+	for i := 0; i < 2; i++ {
+		if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+			t.Fatalf("get variable: %s", err)
+		}
+	}
+	if tokens != 3 {
+		t.Fatalf("expected expiring tokens to be refreshed, got %d token requests", tokens)
+	}
+	if got := authorization[len(authorization)-1]; got != "Bearer token-3" {
+		t.Fatalf("unexpected authorization %q", got)
+	}
+}
+
+func TestProviderConfig_fakeHeaders(t *testing.T) {
+	fake := newFakeAirflow(t)
+
+	var headers []http.Header
+	fake.handle(http.MethodGet, "/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"key": "foo", "value": "bar"})
+	})
+
+	pcfg := fake.providerConfigWith(t, map[string]interface{}{
+		"username":    "",
+		"password":    "",
+		"headers":     map[string]interface{}{"Authorization": "Bearer static", "X-Tenant": "data"},
+		"max_retries": 0,
+	})
+
+	if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+	if _, err := apiRequest(pcfg, http.MethodGet, "/variables/foo", url.Values{}, nil, nil); err != nil {
+		t.Fatalf("raw get variable: %s", err)
+	}
+	for _, h := range headers {
+		if h.Get("Authorization") != "Bearer static" || h.Get("X-Tenant") != "data" {
+			t.Fatalf("unexpected headers %v", h)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// circuitBreakerCooldown is how long requests are rejected once the circuit
+// breaker opened, before a single request is let through to probe whether
+// Airflow recovered.
+const circuitBreakerCooldown = 30 * time.Second
+
+// circuitBreakerTransport stops sending requests after a number of
+// consecutive failures, so that an unhealthy webserver isn't hammered by
+// every resource of a large configuration timing out on its own. Rejected
+// requests fail with a single diagnostic describing the failures.
+type circuitBreakerTransport struct {
+	next      http.RoundTripper
+	threshold int
+
+	mu          sync.Mutex
+	failures    int
+	lastFailure string
+	openedAt    time.Time
+	rejected    int
+	// probing is set while the request probing whether Airflow recovered is
+	// in flight, during which the breaker is half-open.
+	probing bool
+	now     func() time.Time
+}
+
+func newCircuitBreakerTransport(next http.RoundTripper, threshold int) http.RoundTripper {
+	if threshold <= 0 {
+		return next
+	}
+
+	return &circuitBreakerTransport{
+		next:      next,
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		t.record(fmt.Sprintf("%s %s: %s", req.Method, req.URL.Path, err))
+	case isUnavailableStatus(resp.StatusCode):
+		t.record(fmt.Sprintf("%s %s: %s", req.Method, req.URL.Path, resp.Status))
+	default:
+		t.reset()
+	}
+
+	return resp, err
+}
+
+// allow rejects requests while the circuit breaker is open. Once the
+// cooldown passed the breaker is half-open: a single request is let through
+// and the others are rejected until it completes. The breaker closes if it
+// succeeds and opens again right away if it fails.
+func (t *circuitBreakerTransport) allow() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.failures < t.threshold {
+		return nil
+	}
+	if !t.probing && t.now().Sub(t.openedAt) >= circuitBreakerCooldown {
+		t.probing = true
+		return nil
+	}
+
+	t.rejected++
+	if t.probing {
+		return fmt.Errorf("not sending request: the Airflow API failed %d times in a row, last failure: %s (%d requests rejected so far, waiting for a request probing whether it recovered)",
+			t.failures, t.lastFailure, t.rejected)
+	}
+	return fmt.Errorf("not sending request: the Airflow API failed %d times in a row, last failure: %s (%d requests rejected so far, retrying after %s)",
+		t.failures, t.lastFailure, t.rejected, t.openedAt.Add(circuitBreakerCooldown).Format(time.RFC3339))
+}
+
+func (t *circuitBreakerTransport) record(failure string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.probing = false
+	t.failures++
+	t.lastFailure = failure
+	if t.failures >= t.threshold {
+		if t.failures == t.threshold {
+			log.Printf("[WARN] Airflow API failed %d times in a row, rejecting requests for %s: %s", t.failures, circuitBreakerCooldown, failure)
+		}
+		t.openedAt = t.now()
+	}
+}
+
+func (t *circuitBreakerTransport) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.probing = false
+	t.failures = 0
+	t.rejected = 0
+}
+
+// isUnavailableStatus reports whether a status code means that the
+// webserver, or the proxy in front of it, can't serve requests at all.
+func isUnavailableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransport_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "bar"})
+	fake.failNext(http.MethodGet, "/variables", http.StatusServiceUnavailable, 3)
+
+	now := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreakerTransport(http.DefaultTransport, 3).(*circuitBreakerTransport)
+	breaker.now = func() time.Time { return now }
+	client := &http.Client{Transport: breaker}
+
+	get := func() (*http.Response, error) {
+		resp, err := client.Get(fake.URL + "/api/v1/variables/foo")
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	for i := 0; i < 3; i++ {
+		if resp, err := get(); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("request %d: expected the injected failure, got %v %v", i, resp, err)
+		}
+	}
+
+	_, err := get()
+	if err == nil || !strings.Contains(err.Error(), "failed 3 times in a row") || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected the request to be rejected with a diagnostic, got %v", err)
+	}
+	if got := fake.requestCount(http.MethodGet, "/variables"); got != 3 {
+		t.Fatalf("expected the rejected request not to be sent, got %d requests", got)
+	}
+
+	// Once the cooldown passed a request probes whether Airflow recovered.
+	now = now.Add(circuitBreakerCooldown)
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the probe to succeed, got %v %v", resp, err)
+	}
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the breaker to be closed again, got %v %v", resp, err)
+	}
+}
+
+func TestCircuitBreakerTransport_disabled(t *testing.T) {
+	if _, ok := newCircuitBreakerTransport(http.DefaultTransport, 0).(*circuitBreakerTransport); ok {
+		t.Fatal("expected no circuit breaker for a threshold of 0")
+	}
+}
+
+func TestCircuitBreakerTransport_halfOpen(t *testing.T) {
+	fake := newFakeAirflow(t)
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "bar"})
+	fake.failNext(http.MethodGet, "/variables", http.StatusServiceUnavailable, 1)
+
+	now := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreakerTransport(http.DefaultTransport, 1).(*circuitBreakerTransport)
+	breaker.now = func() time.Time { return now }
+	client := &http.Client{Transport: breaker}
+
+	// The probe is held by the server until the concurrent requests were
+	// rejected.
+	release := make(chan struct{})
+	fake.handle(http.MethodGet, "/variables/probe", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		writeFakeAirflowError(w, http.StatusServiceUnavailable, "still down")
+	})
+
+	get := func(key string) (*http.Response, error) {
+		resp, err := client.Get(fake.URL + "/api/v1/variables/" + key)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	if resp, err := get("foo"); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the injected failure, got %v %v", resp, err)
+	}
+
+	now = now.Add(circuitBreakerCooldown)
+	probe := make(chan error, 1)
+	go func() {
+		_, err := get("probe")
+		probe <- err
+	}()
+	for fake.requestCount(http.MethodGet, "/variables/probe") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := get("foo")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err == nil || !strings.Contains(err.Error(), "waiting for a request probing") {
+			t.Fatalf("expected the request to be rejected while probing, got %v", err)
+		}
+	}
+	if got := fake.requestCount(http.MethodGet, "/variables/foo"); got != 1 {
+		t.Fatalf("expected only the probe to be sent, got %d requests", got)
+	}
+
+	// The failed probe opens the breaker again for another cooldown.
+	close(release)
+	if err := <-probe; err != nil {
+		t.Fatalf("probe: %s", err)
+	}
+	if _, err := get("foo"); err == nil || !strings.Contains(err.Error(), "retrying after") {
+		t.Fatalf("expected the breaker to be open again, got %v", err)
+	}
+
+	// The next probe succeeds and closes it.
+	now = now.Add(circuitBreakerCooldown)
+	if resp, err := get("foo"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the probe to succeed, got %v %v", resp, err)
+	}
+	if resp, err := get("foo"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the breaker to be closed again, got %v %v", resp, err)
+	}
+}
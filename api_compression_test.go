@@ -0,0 +1,71 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestProviderConfig_gzipResponses checks that the transports of the provider
+// keep the gzip negotiation of the Go HTTP client intact, so that large lists
+// are transferred compressed when Airflow or a proxy in front of it supports
+// it.
+func TestProviderConfig_gzipResponses(t *testing.T) {
+	variables := make([]interface{}, 0, 100)
+	for i := 0; i < 100; i++ {
+		variables = append(variables, map[string]interface{}{"key": fmt.Sprintf("var-%03d", i), "value": strings.Repeat("x", 100)})
+	}
+
+	compressed := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"variables": variables, "total_entries": len(variables)})
+			return
+		}
+
+		compressed++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		json.NewEncoder(gz).Encode(map[string]interface{}{"variables": variables, "total_entries": len(variables)})
+	}))
+	t.Cleanup(srv.Close)
+
+	d := schema.TestResourceDataRaw(t, AirflowProvider().Schema, map[string]interface{}{
+		"base_endpoint": srv.URL,
+		"username":      "admin",
+		"password":      "admin",
+	})
+	m, err := providerConfigure(d)
+	if err != nil {
+		t.Fatalf("failed to configure provider: %s", err)
+	}
+	pcfg := m.(ProviderConfig)
+
+	list, _, err := pcfg.ApiClient.VariableApi.GetVariables(pcfg.AuthContext).Execute()
+	if err != nil {
+		t.Fatalf("list variables: %s", err)
+	}
+	if got := len(list.GetVariables()); got != 100 {
+		t.Fatalf("expected 100 variables, got %d", got)
+	}
+
+	var out map[string]interface{}
+	if _, err := apiRequest(pcfg, http.MethodGet, "/variables", nil, nil, &out); err != nil {
+		t.Fatalf("raw list variables: %s", err)
+	}
+	if got := len(out["variables"].([]interface{})); got != 100 {
+		t.Fatalf("expected 100 raw variables, got %d", got)
+	}
+
+	if compressed != 2 {
+		t.Fatalf("expected both responses to be compressed, got %d", compressed)
+	}
+}
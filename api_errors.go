@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// apiPermissionError adds the RBAC permission an API call lacks to its error
+// when Airflow denied it. Reads return it instead of removing the object from
+// state, as a 403 doesn't mean the object is gone: recreating it would fail
+// for the same reason, or succeed with a duplicate once the permission is
+// granted.
+func apiPermissionError(resp *http.Response, err error, permission string) error {
+	if err == nil || resp == nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: the user the provider authenticates as lacks the `%s` permission, grant it to one of its roles. The object is kept in state", err, permission)
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: Airflow rejected the credentials of the provider. The object is kept in state", err)
+	}
+
+	return err
+}
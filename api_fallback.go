@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// readFallbackCache remembers the read endpoints Airflow forbade, e.g. the
+// API gateway of a managed offering that only exposes list endpoints, so
+// that the other endpoint is used right away for the rest of the run.
+type readFallbackCache struct {
+	mu      sync.Mutex
+	blocked map[string]bool
+}
+
+func (c *readFallbackCache) isBlocked(endpoint string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocked[endpoint]
+}
+
+func (c *readFallbackCache) block(endpoint string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.blocked == nil {
+		c.blocked = map[string]bool{}
+	}
+	c.blocked[endpoint] = true
+}
+
+// getWithListFallback reads a single object with get. When Airflow forbids
+// it, the object is looked up in the list of its kind instead, and from then
+// on for all objects of the kind. An object missing from the list is
+// reported like a 404 of get. When the list is forbidden as well, the error
+// of get is returned, as the permission is missing altogether.
+func getWithListFallback[T any](pcfg ProviderConfig, kind, id string, get func() (T, *http.Response, error), list func(fn func(T) bool) error, key func(T) string) (T, *http.Response, error) {
+	endpoint := "get " + kind
+	if !pcfg.readFallbacks.isBlocked(endpoint) {
+		apiObject, resp, err := get()
+		if resp == nil || resp.StatusCode != http.StatusForbidden {
+			return apiObject, resp, err
+		}
+
+		found, foundResp, listErr := findInList(kind, id, list, key)
+		if listErr != nil && foundResp == nil {
+			return apiObject, resp, err
+		}
+
+		log.Printf("[INFO] Reading %s objects from the list endpoint, Airflow forbids reading them one by one", kind)
+		pcfg.readFallbacks.block(endpoint)
+		return found, foundResp, listErr
+	}
+
+	return findInList(kind, id, list, key)
+}
+
+func findInList[T any](kind, id string, list func(fn func(T) bool) error, key func(T) string) (T, *http.Response, error) {
+	var found T
+	exists := false
+	err := list(func(apiObject T) bool {
+		if key(apiObject) == id {
+			found, exists = apiObject, true
+		}
+		return !exists
+	})
+	if err != nil {
+		return found, nil, err
+	}
+	if !exists {
+		return found, &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}, fmt.Errorf("%s `%s` not found in the list of Airflow", kind, id)
+	}
+
+	return found, &http.Response{StatusCode: http.StatusOK, Status: "200 OK"}, nil
+}
+
+// listWithGetFallback calls fn for the listed objects of a kind, like
+// forEachPage does. When Airflow forbids the list, the objects with the
+// given keys are read one by one instead, and from then on for the kind.
+// Objects that don't exist are left out. When reading single objects is
+// forbidden as well, the error of the list is returned.
+func listWithGetFallback[T any](pcfg ProviderConfig, kind string, ids []string, list func(fn func(T) bool) (*http.Response, error), get func(id string) (T, *http.Response, error), fn func(T) bool) error {
+	endpoint := "list " + kind
+	if !pcfg.readFallbacks.isBlocked(endpoint) {
+		resp, err := list(fn)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+			return err
+		}
+
+		log.Printf("[INFO] Reading %s objects one by one, Airflow forbids listing them", kind)
+		if getErr := getEach(ids, get, fn); getErr != nil {
+			return err
+		}
+		pcfg.readFallbacks.block(endpoint)
+		return nil
+	}
+
+	return getEach(ids, get, fn)
+}
+
+func getEach[T any](ids []string, get func(id string) (T, *http.Response, error), fn func(T) bool) error {
+	for _, id := range ids {
+		apiObject, resp, err := get(id)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(apiObject) {
+			return nil
+		}
+	}
+	return nil
+}
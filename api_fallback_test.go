@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourcePool_fakeListFallback(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("pools", map[string]interface{}{"name": "listed", "slots": 3})
+	fake.seed("pools", map[string]interface{}{"name": "other", "slots": 1})
+	for _, name := range []string{"listed", "gone"} {
+		fake.handle(http.MethodGet, "/pools/"+name, func(w http.ResponseWriter, r *http.Request) {
+			writeFakeAirflowError(w, http.StatusForbidden, "Forbidden")
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{"name": "listed", "slots": 3})
+	d.SetId("listed")
+	if err := resourcePoolRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("slots").(int); got != 3 {
+		t.Fatalf("expected the pool to be read from the list, got %d slots", got)
+	}
+
+	// The list is used right away for the other pools of the run.
+	d = schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{"name": "gone", "slots": 1})
+	d.SetId("gone")
+	if err := resourcePoolRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected a pool missing from the list to be removed from state")
+	}
+	if got := fake.requestCount(http.MethodGet, "/pools/"); got != 1 {
+		t.Fatalf("expected a single forbidden GET, got %d", got)
+	}
+}
+
+func TestResourcePools_fakeGetFallback(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("pools", map[string]interface{}{"name": "tenant_a", "slots": 2})
+	fake.handle(http.MethodGet, "/pools", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowError(w, http.StatusForbidden, "Forbidden")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourcePools().Schema, map[string]interface{}{
+		"pool": []interface{}{
+			map[string]interface{}{"name": "tenant_a", "slots": 2, "description": ""},
+			map[string]interface{}{"name": "tenant_b", "slots": 1, "description": ""},
+		},
+	})
+	d.SetId("pools")
+	if err := resourcePoolsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("pool.#").(int); got != 1 || d.Get("pool.0.name") != "tenant_a" {
+		t.Fatalf("expected only the existing pool to be read one by one, got %v", d.Get("pool"))
+	}
+
+	// Without the permission to read single pools either, the error of the
+	// list is returned.
+	fake.handle(http.MethodGet, "/pools/tenant_a", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowError(w, http.StatusForbidden, "Forbidden")
+	})
+	m = fake.providerConfig(t)
+	if err := resourcePoolsRead(d, m); err == nil {
+		t.Fatal("expected the read to fail")
+	}
+}
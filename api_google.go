@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jws"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	googleAuthAccessToken = "access_token"
+	googleAuthIdToken     = "id_token"
+)
+
+var googleAuthModes = []string{googleAuthAccessToken, googleAuthIdToken}
+
+// googleMetadataUrl is the base URL of the tokens the metadata server of
+// Google Cloud hands out for the service account of the workload.
+var googleMetadataUrl = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default"
+
+const googleTokenUrl = "https://oauth2.googleapis.com/token"
+
+// googleCredentials is a credentials file of Google Cloud, either a service
+// account key or the application default credentials of gcloud.
+type googleCredentials struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyId string `json:"private_key_id"`
+	TokenUri     string `json:"token_uri"`
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// newGoogleTokenSource returns the source of the tokens API calls to Cloud
+// Composer are authenticated with: access tokens, which the Airflow API of
+// Composer 2 accepts, or ID tokens for the given audience, e.g. the OAuth
+// client ID of the IAP in front of Composer 1. Without credentials, the
+// application default credentials are used: the file that
+// GOOGLE_APPLICATION_CREDENTIALS points to, or else the service account of
+// the metadata server. Tokens are cached and renewed shortly before they
+// expire.
+func newGoogleTokenSource(mode, credentialsJson, audience string) (oauth2.TokenSource, error) {
+	if credentialsJson == "" {
+		if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the Google credentials of GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+			}
+			credentialsJson = string(b)
+		}
+	}
+	if credentialsJson == "" {
+		return oauth2.ReuseTokenSource(nil, &googleMetadataTokenSource{mode: mode, audience: audience}), nil
+	}
+
+	var creds googleCredentials
+	if err := json.Unmarshal([]byte(credentialsJson), &creds); err != nil {
+		return nil, fmt.Errorf("invalid Google credentials: %w", err)
+	}
+
+	switch creds.Type {
+	case "service_account":
+		tokenUrl := creds.TokenUri
+		if tokenUrl == "" {
+			tokenUrl = googleTokenUrl
+		}
+		cfg := &jwt.Config{
+			Email:        creds.ClientEmail,
+			PrivateKey:   []byte(creds.PrivateKey),
+			PrivateKeyID: creds.PrivateKeyId,
+			TokenURL:     tokenUrl,
+			Scopes:       []string{"https://www.googleapis.com/auth/cloud-platform"},
+		}
+		if mode == googleAuthIdToken {
+			cfg.Scopes = nil
+			cfg.PrivateClaims = map[string]interface{}{"target_audience": audience}
+			cfg.UseIDToken = true
+		}
+		return cfg.TokenSource(context.Background()), nil
+	case "authorized_user":
+		if mode == googleAuthIdToken {
+			return nil, fmt.Errorf("ID tokens for an audience require the credentials of a service account, not of a user")
+		}
+		cfg := &oauth2.Config{
+			ClientID:     creds.ClientId,
+			ClientSecret: creds.ClientSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: googleTokenUrl},
+		}
+		return cfg.TokenSource(context.Background(), &oauth2.Token{RefreshToken: creds.RefreshToken}), nil
+	default:
+		return nil, fmt.Errorf("unsupported type `%s` of Google credentials, expected `service_account` or `authorized_user`", creds.Type)
+	}
+}
+
+// googleMetadataTokenSource gets the tokens of the service account attached
+// to the workload, e.g. a Cloud Build worker or a GKE pod with Workload
+// Identity, from the metadata server.
+type googleMetadataTokenSource struct {
+	mode     string
+	audience string
+}
+
+func (s *googleMetadataTokenSource) Token() (*oauth2.Token, error) {
+	path := "/token"
+	if s.mode == googleAuthIdToken {
+		path = "/identity?" + url.Values{"audience": {s.audience}, "format": {"full"}}.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, googleMetadataUrl+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a Google token from the metadata server, no Google credentials were found: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to get a Google token from the metadata server: %s %s", resp.Status, body)
+	}
+
+	if s.mode == googleAuthIdToken {
+		idToken := strings.TrimSpace(string(body))
+		claims, err := jws.Decode(idToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID token returned by the metadata server: %w", err)
+		}
+		return &oauth2.Token{AccessToken: idToken, TokenType: "Bearer", Expiry: time.Unix(claims.Exp, 0)}, nil
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("invalid access token returned by the metadata server: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2/jws"
+)
+
+func TestProviderConfig_fakeGoogleServiceAccount(t *testing.T) {
+	fake := newFakeAirflow(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials, _ := json.Marshal(googleCredentials{
+		Type:        "service_account",
+		ClientEmail: "terraform@example.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenUri:    fake.URL + "/token",
+	})
+
+	var assertions []map[string]interface{}
+	fake.handle(http.MethodPost, "/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if err := jws.Verify(r.PostForm.Get("assertion"), &key.PublicKey); err != nil {
+			t.Errorf("invalid assertion: %s", err)
+		}
+		// The private claims aren't decoded by jws.
+		var claims map[string]interface{}
+		payload, _ := base64.RawURLEncoding.DecodeString(strings.Split(r.PostForm.Get("assertion"), ".")[1])
+		json.Unmarshal(payload, &claims)
+		assertions = append(assertions, claims)
+
+		if claims["target_audience"] != nil {
+			idToken, _ := jws.Encode(&jws.Header{Algorithm: "RS256", Typ: "JWT"}, &jws.ClaimSet{
+				Iss: claims["iss"].(string),
+				Aud: "iap-client-id",
+				Exp: time.Now().Add(time.Hour).Unix(),
+			}, key)
+			writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"id_token": idToken})
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 3600})
+	})
+
+	var authorization []string
+	fake.handle(http.MethodGet, "/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"key": "foo", "value": "bar"})
+	})
+
+	for _, mode := range googleAuthModes {
+		args := map[string]interface{}{
+			"username":           "",
+			"password":           "",
+			"google_auth":        mode,
+			"google_credentials": string(credentials),
+			"max_retries":        0,
+		}
+		if mode == googleAuthIdToken {
+			args["google_iap_audience"] = "iap-client-id"
+		}
+		pcfg := fake.providerConfigWith(t, args)
+
+		for i := 0; i < 2; i++ {
+			if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+				t.Fatalf("%s: get variable: %s", mode, err)
+			}
+		}
+	}
+
+	if len(assertions) != 2 {
+		t.Fatalf("expected a token per mode to be reused, got %d token requests", len(assertions))
+	}
+	if assertions[0]["scope"] != "https://www.googleapis.com/auth/cloud-platform" || assertions[0]["target_audience"] != nil {
+		t.Fatalf("unexpected access token assertion %v", assertions[0])
+	}
+	if assertions[1]["scope"] != nil || assertions[1]["target_audience"] != "iap-client-id" {
+		t.Fatalf("unexpected ID token assertion %v", assertions[1])
+	}
+	if authorization[0] != "Bearer access" || authorization[2] == "Bearer access" || authorization[2] == "" {
+		t.Fatalf("unexpected authorization %v", authorization)
+	}
+}
+
+func TestGoogleMetadataTokenSource_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+
+	metadataUrl := googleMetadataUrl
+	googleMetadataUrl = fake.URL + "/metadata"
+	t.Cleanup(func() { googleMetadataUrl = metadataUrl })
+
+	fake.handle(http.MethodGet, "/metadata/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 3600})
+	})
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	fake.handle(http.MethodGet, "/metadata/identity", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("audience"); got != "iap-client-id" {
+			t.Errorf("unexpected audience %q", got)
+		}
+		idToken, _ := jws.Encode(&jws.Header{Algorithm: "RS256", Typ: "JWT"}, &jws.ClaimSet{Aud: "iap-client-id", Exp: exp.Unix()}, key)
+		w.Write([]byte(idToken))
+	})
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	ts, err := newGoogleTokenSource(googleAuthAccessToken, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("access token: %s", err)
+	}
+	if token.AccessToken != "access" || !token.Valid() {
+		t.Fatalf("unexpected access token %v", token)
+	}
+
+	ts, err = newGoogleTokenSource(googleAuthIdToken, "", "iap-client-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err = ts.Token()
+	if err != nil {
+		t.Fatalf("ID token: %s", err)
+	}
+	if !token.Expiry.Equal(exp) {
+		t.Fatalf("expected the expiry of the ID token, got %s", token.Expiry)
+	}
+}
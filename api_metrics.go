@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// providerResourceType is the resource type of the API calls made outside of
+// the operations of resources and data sources, e.g. while configuring the
+// provider.
+const providerResourceType = "provider"
+
+type operationResourceTypeContextKey struct{}
+
+// apiCallStats holds the counters collected for a single API resource type.
+type apiCallStats struct {
+	Calls   int64
+	Errors  int64
+	Retries int64
+	Latency time.Duration
+}
+
+// apiMetrics collects API call statistics per resource type for a single
+// configured provider instance.
+type apiMetrics struct {
+	mu    sync.Mutex
+	stats map[string]*apiCallStats
+	// logged is set once the summary was logged, so that it is logged once
+	// per stop of the provider or shutdown of the plugin.
+	logged bool
+}
+
+var (
+	allAPIMetrics   []*apiMetrics
+	allAPIMetricsMu sync.Mutex
+)
+
+func newAPIMetrics() *apiMetrics {
+	m := &apiMetrics{
+		stats: map[string]*apiCallStats{},
+	}
+
+	allAPIMetricsMu.Lock()
+	allAPIMetrics = append(allAPIMetrics, m)
+	allAPIMetricsMu.Unlock()
+
+	return m
+}
+
+func (m *apiMetrics) entry(resourceType string) *apiCallStats {
+	s, ok := m.stats[resourceType]
+	if !ok {
+		s = &apiCallStats{}
+		m.stats[resourceType] = s
+	}
+	return s
+}
+
+func (m *apiMetrics) observe(resourceType string, latency time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.entry(resourceType)
+	s.Calls++
+	s.Latency += latency
+	if failed {
+		s.Errors++
+	}
+}
+
+func (m *apiMetrics) recordRetry(resourceType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entry(resourceType).Retries++
+}
+
+// snapshot returns a copy of the collected statistics.
+func (m *apiMetrics) snapshot() map[string]apiCallStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]apiCallStats, len(m.stats))
+	for k, v := range m.stats {
+		out[k] = *v
+	}
+	return out
+}
+
+// summary renders the statistics as a single line per resource type,
+// ordered by cumulative latency so the slowest resource types come first.
+func (m *apiMetrics) summary() string {
+	stats := m.snapshot()
+
+	types := make([]string, 0, len(stats))
+	for k := range stats {
+		types = append(types, k)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if stats[types[i]].Latency == stats[types[j]].Latency {
+			return types[i] < types[j]
+		}
+		return stats[types[i]].Latency > stats[types[j]].Latency
+	})
+
+	var b strings.Builder
+	for _, t := range types {
+		s := stats[t]
+		fmt.Fprintf(&b, "%s: calls=%d errors=%d retries=%d latency=%s\n", t, s.Calls, s.Errors, s.Retries, s.Latency.Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+// logAPIMetricsSummary logs the API call statistics of every provider
+// instance configured by this process with the provider logger of ctx, unless
+// they were logged already.
+func logAPIMetricsSummary(ctx context.Context) {
+	allAPIMetricsMu.Lock()
+	defer allAPIMetricsMu.Unlock()
+
+	for _, m := range allAPIMetrics {
+		s := m.summary()
+		if s == "" || m.markLogged() {
+			continue
+		}
+
+		tflog.Info(ctx, "Airflow API call summary:\n"+s)
+		for resourceType, stats := range m.snapshot() {
+			tflog.Debug(ctx, "Airflow API calls", map[string]interface{}{
+				"resource_type": resourceType,
+				"calls":         stats.Calls,
+				"errors":        stats.Errors,
+				"retries":       stats.Retries,
+				"latency_ms":    stats.Latency.Milliseconds(),
+			})
+		}
+	}
+}
+
+// markLogged reports whether the summary was logged before and marks it as
+// logged.
+func (m *apiMetrics) markLogged() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	logged := m.logged
+	m.logged = true
+	return logged
+}
+
+// metricsSummaryServer logs the API call summary when Terraform stops the
+// provider, e.g. on interrupt, with the logger of the stop request, so that
+// it is written while Terraform still reads the provider logs.
+type metricsSummaryServer struct {
+	tfprotov5.ProviderServer
+}
+
+func (s *metricsSummaryServer) StopProvider(ctx context.Context, req *tfprotov5.StopProviderRequest) (*tfprotov5.StopProviderResponse, error) {
+	logAPIMetricsSummary(ctx)
+	return s.ProviderServer.StopProvider(ctx, req)
+}
+
+// withOperationResourceType attaches the Terraform resource type of an
+// operation, e.g. "airflow_pool" or "data.airflow_pool", to the context of
+// its API calls, by which they are counted.
+func withOperationResourceType(ctx context.Context, resourceType string) context.Context {
+	return context.WithValue(ctx, operationResourceTypeContextKey{}, resourceType)
+}
+
+// operationResourceType returns the Terraform resource type of the operation
+// an API call is made in.
+func operationResourceType(ctx context.Context) string {
+	if resourceType, ok := ctx.Value(operationResourceTypeContextKey{}).(string); ok {
+		return resourceType
+	}
+	return providerResourceType
+}
+
+// metricsTransport records the latency and outcome of every API call.
+type metricsTransport struct {
+	next    http.RoundTripper
+	metrics *apiMetrics
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	failed := err != nil || resp.StatusCode >= 400
+	t.metrics.observe(operationResourceType(req.Context()), time.Since(start), failed)
+
+	return resp, err
+}
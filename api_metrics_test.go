@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestApiMetrics_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{
+		"max_retries":     2,
+		"retry_min_delay": "1ms",
+		"retry_max_delay": "5ms",
+	})
+
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "bar"})
+	fake.failNext(http.MethodGet, "/pools", http.StatusServiceUnavailable, 1)
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{})
+	err := wrapOperation("airflow_variable", func(d *schema.ResourceData, m interface{}) error {
+		pcfg := m.(ProviderConfig)
+		if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+			t.Fatalf("get variable: %s", err)
+		}
+		if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "missing").Execute(); err == nil {
+			t.Fatal("expected an error for a missing variable")
+		}
+		return nil
+	})(d, m)
+	if err != nil {
+		t.Fatalf("operation: %s", err)
+	}
+	err = wrapOperation("data.airflow_pools", func(d *schema.ResourceData, m interface{}) error {
+		pcfg := m.(ProviderConfig)
+		_, _, err := pcfg.ApiClient.PoolApi.GetPools(pcfg.AuthContext).Execute()
+		return err
+	})(d, m)
+	if err != nil {
+		t.Fatalf("operation: %s", err)
+	}
+	// Calls outside of an operation are counted for the provider.
+	if _, _, err := m.ApiClient.VariableApi.GetVariable(m.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+
+	stats := m.Metrics.snapshot()
+	if got := stats["airflow_variable"]; got.Calls != 2 || got.Errors != 1 || got.Retries != 0 {
+		t.Fatalf("unexpected airflow_variable stats: %+v", got)
+	}
+	// Every attempt counts as a call, so the retried one shows up next to
+	// its failed attempt.
+	if got := stats["data.airflow_pools"]; got.Calls != 2 || got.Errors != 1 || got.Retries != 1 {
+		t.Fatalf("unexpected data.airflow_pools stats: %+v", got)
+	}
+	if got := stats[providerResourceType]; got.Calls != 1 {
+		t.Fatalf("unexpected provider stats: %+v", got)
+	}
+
+	summary := m.Metrics.summary()
+	if !strings.Contains(summary, "airflow_variable: calls=2 errors=1 retries=0") || !strings.Contains(summary, "data.airflow_pools: calls=2 errors=1 retries=1") {
+		t.Fatalf("unexpected summary:\n%s", summary)
+	}
+	if fake.requestCount(http.MethodGet, "/variables") != 3 {
+		t.Fatal("expected three variable requests")
+	}
+
+	// Stopping the provider logs the summary, which isn't repeated once the
+	// plugin shuts down.
+	var output bytes.Buffer
+	server := &metricsSummaryServer{ProviderServer: schema.NewGRPCProviderServer(AirflowProvider())}
+	if _, err := server.StopProvider(tflogtest.RootLogger(context.Background(), &output), &tfprotov5.StopProviderRequest{}); err != nil {
+		t.Fatalf("stop provider: %s", err)
+	}
+	if !strings.Contains(output.String(), "Airflow API call summary") || !strings.Contains(output.String(), "data.airflow_pools: calls=2") || !strings.Contains(output.String(), `"retries":1`) {
+		t.Fatalf("expected the summary in the provider log, got:\n%s", output.String())
+	}
+
+	output.Reset()
+	logAPIMetricsSummary(tflogtest.RootLogger(context.Background(), &output))
+	if strings.Contains(output.String(), "data.airflow_pools: calls=2") {
+		t.Fatalf("expected the summary to be logged once, got:\n%s", output.String())
+	}
+}
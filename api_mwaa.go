@@ -0,0 +1,276 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mwaaApiEndpoint returns the endpoint of the MWAA API in a region that web
+// login tokens are created with.
+var mwaaApiEndpoint = func(region string) string {
+	return fmt.Sprintf("https://env.airflow.%s.amazonaws.com", region)
+}
+
+// awsCredentials are static AWS credentials, optionally of a session.
+type awsCredentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// mwaaSession logs into the webserver of an Amazon MWAA environment with a
+// web login token of the MWAA API and keeps the session cookie the
+// webserver hands out in exchange, which authenticates calls to the stable
+// API. Web login tokens are only valid for a minute, so the session is
+// renewed with a new one when it expires.
+type mwaaSession struct {
+	environment string
+	region      string
+	credentials awsCredentials
+	// webserver is the base URL the token is exchanged at. It defaults to
+	// the webserver hostname returned along with the token.
+	webserver string
+	client    *http.Client
+
+	mu      sync.Mutex
+	cookies []*http.Cookie
+}
+
+func newMwaaSession(environment, region, webserver string, credentials awsCredentials) *mwaaSession {
+	return &mwaaSession{
+		environment: environment,
+		region:      region,
+		credentials: credentials,
+		webserver:   webserver,
+		client: &http.Client{
+			Timeout: time.Minute,
+			// The login redirects to the UI, while only its cookie is
+			// needed.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// login creates a web login token and exchanges it for a session. It returns
+// the hostname of the webserver of the environment.
+func (s *mwaaSession) login() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.loginLocked()
+}
+
+func (s *mwaaSession) loginLocked() (string, error) {
+	req, err := http.NewRequest(http.MethodPost, mwaaApiEndpoint(s.region)+"/webtoken/"+url.PathEscape(s.environment), nil)
+	if err != nil {
+		return "", err
+	}
+	signAwsRequest(req, s.credentials, s.region, "airflow", time.Now())
+
+	var token struct {
+		WebServerHostname string
+		WebToken          string
+	}
+	if err := s.do(req, &token); err != nil {
+		return "", fmt.Errorf("failed to create a web login token for MWAA environment `%s`: %w", s.environment, err)
+	}
+
+	webserver := s.webserver
+	if webserver == "" {
+		webserver = "https://" + token.WebServerHostname
+	}
+	form := url.Values{"token": {token.WebToken}}
+	req, err = http.NewRequest(http.MethodPost, strings.TrimRight(webserver, "/")+"/aws_mwaa/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to log into MWAA environment `%s`: %w", s.environment, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to log into MWAA environment `%s`: %s %s", s.environment, resp.Status, body)
+	}
+
+	var cookies []*http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == "session" {
+			cookies = append(cookies, c)
+		}
+	}
+	if len(cookies) == 0 {
+		return "", fmt.Errorf("failed to log into MWAA environment `%s`: the webserver returned no session", s.environment)
+	}
+
+	log.Printf("[DEBUG] Logged into MWAA environment `%s`", s.environment)
+	s.cookies = cookies
+	return token.WebServerHostname, nil
+}
+
+func (s *mwaaSession) do(req *http.Request, out interface{}) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s", resp.Status, body)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// session returns the cookies of the current session, logging in if there
+// is none yet.
+func (s *mwaaSession) session() ([]*http.Cookie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cookies == nil {
+		if _, err := s.loginLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return s.cookies, nil
+}
+
+// renew logs in again after the given session expired. Concurrent calls
+// that hit the expiry of the same session log in only once.
+func (s *mwaaSession) renew(expired []*http.Cookie) ([]*http.Cookie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cookies) > 0 && len(expired) > 0 && s.cookies[0] != expired[0] {
+		return s.cookies, nil
+	}
+	if _, err := s.loginLocked(); err != nil {
+		return nil, err
+	}
+	return s.cookies, nil
+}
+
+// mwaaTransport authenticates API calls with the session of an MWAA
+// environment and sends a call once more with a new session when Airflow
+// rejects it because the session expired.
+type mwaaTransport struct {
+	next    http.RoundTripper
+	session *mwaaSession
+}
+
+func (t *mwaaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cookies, err := t.session.session()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withCookies(req, req.Body, cookies))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	body := req.Body
+	if body != nil && body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, nil
+		}
+		if body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+
+	log.Printf("[DEBUG] The session of MWAA environment `%s` expired, logging in again", t.session.environment)
+	if cookies, err = t.session.renew(cookies); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(withCookies(req, body, cookies))
+}
+
+func withCookies(req *http.Request, body io.ReadCloser, cookies []*http.Cookie) *http.Request {
+	req = req.Clone(req.Context())
+	req.Body = body
+	for _, c := range cookies {
+		req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return req
+}
+
+// signAwsRequest signs a request without a body with AWS Signature Version 4.
+func signAwsRequest(req *http.Request, credentials awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(nil)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := []byte("AWS4" + credentials.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSha256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", credentials.AccessKeyId, scope, signedHeaders, signature))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
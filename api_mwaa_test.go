@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignAwsRequest checks the signature against the get-vanilla case of
+// the AWS Signature Version 4 test suite.
+func TestSignAwsRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := awsCredentials{
+		AccessKeyId:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	signAwsRequest(req, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("unexpected authorization\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestProviderConfig_fakeMwaa(t *testing.T) {
+	fake := newFakeAirflow(t)
+
+	endpoint := mwaaApiEndpoint
+	mwaaApiEndpoint = func(region string) string { return fake.URL + "/mwaa/" + region }
+	t.Cleanup(func() { mwaaApiEndpoint = endpoint })
+
+	tokens := 0
+	fake.handle(http.MethodPost, "/mwaa/eu-west-1/webtoken/prod", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/airflow/aws4_request") {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if got := r.Header.Get("X-Amz-Security-Token"); got != "session" {
+			t.Errorf("unexpected session token %q", got)
+		}
+		tokens++
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"WebServerHostname": "prod.eu-west-1.airflow.amazonaws.com",
+			"WebToken":          "web-token",
+		})
+	})
+	sessions := 0
+	fake.handle(http.MethodPost, "/aws_mwaa/login", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("token"); got != "web-token" {
+			t.Errorf("unexpected web token %q", got)
+		}
+		sessions++
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "session-" + strings.Repeat("x", sessions)})
+		http.Redirect(w, r, "/home", http.StatusFound)
+	})
+
+	// The first session expires after a call.
+	fake.handle(http.MethodGet, "/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil || c.Value != "session-"+strings.Repeat("x", sessions) || r.Header.Get("Authorization") != "" {
+			writeFakeAirflowError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"key": "foo", "value": "bar"})
+	})
+
+	pcfg := fake.providerConfigWith(t, map[string]interface{}{
+		"username":              "",
+		"password":              "",
+		"mwaa_environment_name": "prod",
+		"aws_region":            "eu-west-1",
+		"aws_access_key_id":     "AKID",
+		"aws_secret_access_key": "secret",
+		"aws_session_token":     "session",
+		"max_retries":           0,
+	})
+	if tokens != 1 || sessions != 1 {
+		t.Fatalf("expected a login when configuring, got %d tokens and %d sessions", tokens, sessions)
+	}
+
+	if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+	if tokens != 1 {
+		t.Fatalf("expected the session to be reused, got %d tokens", tokens)
+	}
+
+	// Expire the session, which is renewed by the next call.
+	sessions++
+	if _, _, err := pcfg.ApiClient.VariableApi.GetVariable(pcfg.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable after the session expired: %s", err)
+	}
+	if tokens != 2 {
+		t.Fatalf("expected a new web login token, got %d tokens", tokens)
+	}
+}
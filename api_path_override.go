@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type apiPathOverridesContextKey struct{}
+
+func apiPathOverridesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validateApiPath,
+		},
+	}
+}
+
+// wrapApiPathOverrides adds the api_path_overrides argument to a typed
+// resource, so that a single instance can be driven against endpoints of a
+// fork or plugin that mirror the core API under another path. The overrides
+// are attached to the context of the API calls of each operation and applied
+// by apiPathTransport. It must be applied before wrapOperations.
+func wrapApiPathOverrides(r *schema.Resource) {
+	r.Schema["api_path_overrides"] = apiPathOverridesSchema()
+
+	wrap := func(f operationFunc) operationFunc {
+		return func(d *schema.ResourceData, m interface{}) error {
+			pcfg, ok := m.(ProviderConfig)
+			v := d.Get("api_path_overrides").(map[string]interface{})
+			if !ok || len(v) == 0 {
+				return f(d, m)
+			}
+
+			overrides := make(map[string]string, len(v))
+			for from, to := range v {
+				if err := validateApiPathPrefix(from); err != nil {
+					return fmt.Errorf("invalid key `%s` of api_path_overrides: %w", from, err)
+				}
+				overrides[strings.TrimRight(from, "/")] = strings.TrimRight(to.(string), "/")
+			}
+
+			pcfg.AuthContext = context.WithValue(pcfg.AuthContext, apiPathOverridesContextKey{}, overrides)
+			return f(d, pcfg)
+		}
+	}
+
+	if r.Create != nil {
+		r.Create = schema.CreateFunc(wrap(operationFunc(r.Create)))
+	}
+	if r.Read != nil {
+		r.Read = schema.ReadFunc(wrap(operationFunc(r.Read)))
+	}
+	if r.Update != nil {
+		r.Update = schema.UpdateFunc(wrap(operationFunc(r.Update)))
+	}
+	if r.Delete != nil {
+		r.Delete = schema.DeleteFunc(wrap(operationFunc(r.Delete)))
+	}
+}
+
+func validateApiPath(v interface{}, k string) ([]string, []error) {
+	if err := validateApiPathPrefix(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %w", k, err)}
+	}
+	return nil, nil
+}
+
+func validateApiPathPrefix(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("must start with /")
+	}
+	if strings.ContainsAny(path, "?#") {
+		return fmt.Errorf("must not contain a query or fragment")
+	}
+	return nil
+}
+
+// apiPathTransport rewrites the path of requests made within an operation
+// with api_path_overrides. The longest matching prefix wins, and prefixes
+// only match whole path segments, so `/api/v1/pools` doesn't match
+// `/api/v1/poolsets`.
+type apiPathTransport struct {
+	next http.RoundTripper
+	// basePath is the path of the base_endpoint, which overrides are
+	// relative to.
+	basePath string
+}
+
+func (t *apiPathTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	overrides, ok := req.Context().Value(apiPathOverridesContextKey{}).(map[string]string)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	path := strings.TrimPrefix(req.URL.EscapedPath(), t.basePath)
+	match := ""
+	for from := range overrides {
+		if len(from) > len(match) && (path == from || strings.HasPrefix(path, from+"/")) {
+			match = from
+		}
+	}
+	if match == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	rewritten := t.basePath + overrides[match] + path[len(match):]
+	unescaped, err := url.PathUnescape(rewritten)
+	if err != nil {
+		return nil, fmt.Errorf("invalid overridden API path %s: %w", rewritten, err)
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Path = unescaped
+	req.URL.RawPath = rewritten
+	return t.next.RoundTrip(req)
+}
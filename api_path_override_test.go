@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestApiPathOverrides_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "core"})
+
+	fake.handle(http.MethodGet, "/tenant/api/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"key": "foo", "value": "tenant"})
+	})
+
+	r := resourceVariable()
+	wrapApiPathOverrides(r)
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"key": "foo",
+		"api_path_overrides": map[string]interface{}{
+			"/api/v1/variables":    "/tenant/api/variables",
+			"/api/v1/variablesets": "/unused",
+		},
+	})
+	d.SetId("foo")
+	if err := r.Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("value").(string); got != "tenant" {
+		t.Fatalf("expected the overridden endpoint to be called, got value %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"key": "foo"})
+	d.SetId("foo")
+	if err := r.Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("value").(string); got != "core" {
+		t.Fatalf("expected the core endpoint without overrides, got value %q", got)
+	}
+}
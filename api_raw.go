@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"golang.org/x/oauth2"
+)
+
+// apiRequest calls an endpoint of the Airflow API that isn't covered by the
+// generated client, e.g. because it was added in a later Airflow version.
+// path is relative to the API base path, like the paths of the client, and
+// its segments must already be escaped. The request is authenticated and
+// sent through the same HTTP client as the generated client. The JSON
+// response is decoded into out if it isn't nil.
+func apiRequest(pcfg ProviderConfig, method, path string, query url.Values, body, out interface{}) (*http.Response, error) {
+	u, err := apiUrl(pcfg, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendRequest(pcfg, method, u, path, body, out)
+}
+
+// apiRawRequest is like apiRequest, but returns the response and its body
+// whatever the status code, for callers that handle error responses
+// themselves.
+func apiRawRequest(pcfg ProviderConfig, method, path string, query url.Values, body interface{}) (*http.Response, []byte, error) {
+	u, err := apiUrl(pcfg, path, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sendRawRequest(pcfg, method, u, body)
+}
+
+func apiUrl(pcfg ProviderConfig, path string, query url.Values) (*url.URL, error) {
+	cfg := pcfg.ApiClient.GetConfig()
+
+	u, err := url.Parse(cfg.Servers[0].URL + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API path %s: %w", path, err)
+	}
+	u.Scheme = cfg.Scheme
+	u.Host = cfg.Host
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// uiRequest calls an endpoint served next to the API rather than under its
+// base path, like the private endpoints of the Airflow 3 UI. path is relative
+// to the base_endpoint of the provider and authenticated like apiRequest.
+func uiRequest(pcfg ProviderConfig, method, path string, query url.Values, out interface{}) (*http.Response, error) {
+	u, err := uiUrl(pcfg, path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendRequest(pcfg, method, u, path, nil, out)
+}
+
+func uiUrl(pcfg ProviderConfig, path string, query url.Values) (*url.URL, error) {
+	cfg := pcfg.ApiClient.GetConfig()
+
+	u, err := url.Parse(strings.TrimSuffix(cfg.Servers[0].URL, "/api/v1") + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UI path %s: %w", path, err)
+	}
+	u.Scheme = cfg.Scheme
+	u.Host = cfg.Host
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// sendRequest sends a request built by apiRequest or uiRequest. path is only
+// used in errors.
+func sendRequest(pcfg ProviderConfig, method string, u *url.URL, path string, body, out interface{}) (*http.Response, error) {
+	resp, respBody, err := sendRawRequest(pcfg, method, u, body)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return resp, fmt.Errorf("%s %s: %s %s", method, path, resp.Status, respBody)
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp, fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// sendRawRequest sends an authenticated request and returns the response
+// along with its body, whatever its status code.
+func sendRawRequest(pcfg ProviderConfig, method string, u *url.URL, body interface{}) (*http.Response, []byte, error) {
+	cfg := pcfg.ApiClient.GetConfig()
+
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(pcfg.AuthContext, method, u.String(), reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	for k, v := range cfg.DefaultHeader {
+		req.Header.Set(k, v)
+	}
+
+	if tokenSource, ok := pcfg.AuthContext.Value(airflow.ContextOAuth2).(oauth2.TokenSource); ok {
+		token, err := tokenSource.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		token.SetAuthHeader(req)
+	}
+	if auth, ok := pcfg.AuthContext.Value(airflow.ContextBasicAuth).(airflow.BasicAuth); ok {
+		req.SetBasicAuth(auth.UserName, auth.Password)
+	}
+	if token, ok := pcfg.AuthContext.Value(airflow.ContextAccessToken).(string); ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, nil, err
+	}
+
+	return resp, respBody, nil
+}
+
+// extractJSONPath returns the value at a dotted path of a decoded JSON value,
+// e.g. `name` or `items[0].id`. It covers the subset of JMESPath that is
+// needed to pick an ID out of an API response.
+func extractJSONPath(v interface{}, path string) (interface{}, error) {
+	for _, part := range strings.FieldsFunc(strings.ReplaceAll(path, "]", ""), func(r rune) bool { return r == '.' || r == '[' }) {
+		switch obj := v.(type) {
+		case map[string]interface{}:
+			inner, ok := obj[part]
+			if !ok {
+				return nil, fmt.Errorf("`%s` not found in %s", part, path)
+			}
+			v = inner
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(obj) {
+				return nil, fmt.Errorf("invalid index `%s` in %s", part, path)
+			}
+			v = obj[i]
+		default:
+			return nil, fmt.Errorf("cannot look up `%s` of a scalar in %s", part, path)
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryTransport retries API calls that were throttled or hit an unavailable
+// webserver, e.g. the 429 and 503 responses managed offerings such as MWAA
+// and Cloud Composer return under load, with exponential backoff. Throttled
+// calls are always retried as Airflow didn't handle them. Calls that may
+// have been handled, i.e. that failed with a 502, 503 or 504 or a dropped
+// connection, are only retried when they are idempotent, and creates are
+// recovered by reading the object back instead.
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+	minDelay   time.Duration
+	maxDelay   time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+	// metrics counts the retries, if set.
+	metrics *apiMetrics
+}
+
+func newRetryTransport(next http.RoundTripper, maxRetries int, minDelay, maxDelay time.Duration, metrics *apiMetrics) http.RoundTripper {
+	if maxRetries <= 0 {
+		return next
+	}
+
+	return &retryTransport{
+		next:       next,
+		maxRetries: maxRetries,
+		minDelay:   minDelay,
+		maxDelay:   maxDelay,
+		sleep:      sleepContext,
+		metrics:    metrics,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.maxRetries || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		// Requests with a body can only be sent again when it can be
+		// rewound, which is the case for the JSON bodies of the client.
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		delay := t.delay(attempt, resp)
+		if err != nil {
+			log.Printf("[WARN] %s %s failed, retrying in %s (%d/%d): %s", req.Method, req.URL.Path, delay, attempt+1, t.maxRetries, err)
+		} else {
+			log.Printf("[WARN] %s %s returned %s, retrying in %s (%d/%d)", req.Method, req.URL.Path, resp.Status, delay, attempt+1, t.maxRetries)
+			resp.Body.Close()
+		}
+
+		if t.metrics != nil {
+			t.metrics.recordRetry(operationResourceType(req.Context()))
+		}
+		if sleepErr := t.sleep(req.Context(), delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
+
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if req.Context().Err() != nil || !isIdempotentMethod(req.Method) {
+		return false
+	}
+	return err != nil || isUnavailableStatus(resp.StatusCode)
+}
+
+// delay returns how long to wait before the next attempt: the backoff of
+// the attempt, or the Retry-After of the response when it asks for longer,
+// capped at maxDelay.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	delay := t.minDelay << attempt
+	if delay <= 0 || delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && after > delay {
+			delay = after
+		}
+	}
+	if delay > t.maxDelay {
+		delay = t.maxDelay
+	}
+
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return at.Sub(now), true
+	}
+	return 0, false
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
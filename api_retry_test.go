@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/airflow-client-go/airflow"
+)
+
+func TestRetryTransport_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{
+		"max_retries":     2,
+		"retry_min_delay": "1ms",
+		"retry_max_delay": "5ms",
+	})
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "bar"})
+
+	// Throttled calls are retried until they succeed.
+	fake.failNext(http.MethodGet, "/variables/foo", http.StatusTooManyRequests, 2)
+	if _, _, err := m.ApiClient.VariableApi.GetVariable(m.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+	if got := fake.requestCount(http.MethodGet, "/variables/foo"); got != 3 {
+		t.Fatalf("expected 3 requests, got %d", got)
+	}
+
+	// The last response is returned when the retries are exhausted.
+	fake.failNext(http.MethodGet, "/variables/foo", http.StatusServiceUnavailable, 3)
+	_, resp, err := m.ApiClient.VariableApi.GetVariable(m.AuthContext, "foo").Execute()
+	if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 after the retries, got %v", err)
+	}
+	if got := fake.requestCount(http.MethodGet, "/variables/foo"); got != 6 {
+		t.Fatalf("expected 6 requests, got %d", got)
+	}
+
+	// Creates that were throttled are sent again with their body.
+	fake.failNext(http.MethodPost, "/variables", http.StatusTooManyRequests, 1)
+	key, value := "created", "value"
+	if _, _, err := m.ApiClient.VariableApi.PostVariables(m.AuthContext).Variable(airflow.Variable{Key: &key, Value: &value}).Execute(); err != nil {
+		t.Fatalf("post variable: %s", err)
+	}
+	if got := fake.object("variables", "created"); got == nil || got["value"] != "value" {
+		t.Fatalf("expected the variable to be created, got %v", got)
+	}
+
+	// Creates that may have been handled aren't sent again.
+	fake.failNext(http.MethodPost, "/variables", http.StatusServiceUnavailable, 1)
+	key = "other"
+	if _, _, err := m.ApiClient.VariableApi.PostVariables(m.AuthContext).Variable(airflow.Variable{Key: &key, Value: &value}).Execute(); err == nil {
+		t.Fatal("expected the create to fail")
+	}
+	if got := fake.requestCount(http.MethodPost, "/variables"); got != 3 {
+		t.Fatalf("expected 3 creates, got %d", got)
+	}
+}
+
+func TestRetryTransport_retryAfter(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		switch attempts {
+		case 1:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	transport := newRetryTransport(http.DefaultTransport, 3, 100*time.Millisecond, 10*time.Second, nil).(*retryTransport)
+	var waits []time.Duration
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	resp.Body.Close()
+
+	// The Retry-After of the first response is longer than the backoff,
+	// the one of the second is capped at the maximum delay.
+	if want := []time.Duration{2 * time.Second, 10 * time.Second}; !reflect.DeepEqual(waits, want) {
+		t.Fatalf("expected waits %v, got %v", want, waits)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "5", want: 5 * time.Second, ok: true},
+		{value: now.Add(time.Minute).Format(http.TimeFormat), want: time.Minute, ok: true},
+		{value: "soon", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
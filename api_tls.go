@@ -0,0 +1,22 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// newTlsTransport returns the transport the API calls are sent through. With
+// a tls_server_name, that name is sent for SNI and the certificate of Airflow
+// is verified against it instead of the host of base_endpoint, e.g. when the
+// endpoint is an IP or an internal alias in a split-horizon DNS setup.
+func newTlsTransport(serverName string) http.RoundTripper {
+	if serverName == "" {
+		return http.DefaultTransport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		ServerName: serverName,
+	}
+	return transport
+}
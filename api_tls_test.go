@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewTlsTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	// The certificate of the test server is valid for example.com and
+	// 127.0.0.1, which the server is reached at.
+	roots := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	tests := []struct {
+		serverName string
+		wantErr    bool
+	}{
+		{serverName: "example.com"},
+		{serverName: "airflow.internal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.serverName, func(t *testing.T) {
+			transport := newTlsTransport(tt.serverName).(*http.Transport)
+			transport.TLSClientConfig.RootCAs = roots
+
+			resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+
+	if newTlsTransport("") != http.DefaultTransport {
+		t.Fatal("expected the default transport without tls_server_name")
+	}
+}
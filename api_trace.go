@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceFileEnv names the file that sanitized request/response transcripts
+// are appended to. Tracing is disabled when it is unset.
+const traceFileEnv = "AIRFLOW_PROVIDER_TRACE_FILE"
+
+const traceRedacted = "REDACTED"
+
+// traceSensitiveHeaders are never written to the trace file.
+var traceSensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+	"Set-Cookie":          true,
+}
+
+// traceSensitiveKeys are redacted from JSON bodies when a key contains any
+// of them. Variable values and connection extras commonly hold secrets.
+var traceSensitiveKeys = []string{"password", "secret", "token", "extra", "value"}
+
+// traceTransport appends a sanitized transcript of every API call to a file.
+type traceTransport struct {
+	next http.RoundTripper
+	// sensitiveHeaders are the canonical names of the headers that are
+	// redacted, the traceSensitiveHeaders and the configured ones.
+	sensitiveHeaders map[string]bool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// newTraceTransport wraps next with a traceTransport when the trace file
+// environment variable is set. The configured headers are redacted as well,
+// as they may carry API keys or bearer tokens.
+func newTraceTransport(next http.RoundTripper, configuredHeaders []string) (http.RoundTripper, error) {
+	path := os.Getenv(traceFileEnv)
+	if path == "" {
+		return next, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", traceFileEnv, err)
+	}
+
+	sensitiveHeaders := make(map[string]bool, len(traceSensitiveHeaders)+len(configuredHeaders))
+	for name := range traceSensitiveHeaders {
+		sensitiveHeaders[name] = true
+	}
+	for _, name := range configuredHeaders {
+		if name != "" {
+			sensitiveHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+
+	return &traceTransport{next: next, sensitiveHeaders: sensitiveHeaders, out: f}, nil
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s %s %s\n", start.UTC().Format(time.RFC3339Nano), req.Method, req.URL.String())
+	t.writeHeaders(&b, req.Header)
+	writeTraceBody(&b, reqBody)
+
+	if err != nil {
+		fmt.Fprintf(&b, "--- error after %s: %s\n\n", latency, err)
+		t.write(b.String())
+		return resp, err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fmt.Fprintf(&b, "--- %s after %s\n", resp.Status, latency)
+	t.writeHeaders(&b, resp.Header)
+	writeTraceBody(&b, respBody)
+	b.WriteString("\n")
+	t.write(b.String())
+
+	return resp, readErr
+}
+
+func (t *traceTransport) write(s string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, _ = io.WriteString(t.out, s)
+}
+
+func (t *traceTransport) writeHeaders(b *strings.Builder, h http.Header) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if t.sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = traceRedacted
+		}
+		fmt.Fprintf(b, "%s: %s\n", name, value)
+	}
+}
+
+func writeTraceBody(b *strings.Builder, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+
+	b.WriteString("\n")
+	b.Write(sanitizeTraceBody(body))
+	b.WriteString("\n")
+}
+
+// sanitizeTraceBody redacts sensitive values from a JSON body. Bodies that
+// are not valid JSON are redacted entirely as they cannot be inspected.
+func sanitizeTraceBody(body []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []byte(traceRedacted)
+	}
+
+	out, err := json.Marshal(sanitizeTraceValue(v))
+	if err != nil {
+		return []byte(traceRedacted)
+	}
+
+	return out
+}
+
+func sanitizeTraceValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, inner := range v {
+			if isTraceSensitiveKey(k) && inner != nil {
+				v[k] = traceRedacted
+			} else {
+				v[k] = sanitizeTraceValue(inner)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = sanitizeTraceValue(inner)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isTraceSensitiveKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range traceSensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
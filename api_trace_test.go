@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeTraceBody(t *testing.T) {
+	cases := map[string]string{
+		`{"username":"foo","password":"secret"}`:               `{"password":"REDACTED","username":"foo"}`,
+		`{"key":"foo","value":"bar"}`:                          `{"key":"foo","value":"REDACTED"}`,
+		`{"connections":[{"connection_id":"a","extra":"{}"}]}`: `{"connections":[{"connection_id":"a","extra":"REDACTED"}]}`,
+		`{"access_token":"abc","expires_in":10}`:               `{"access_token":"REDACTED","expires_in":10}`,
+		`not json`:                                             `REDACTED`,
+	}
+
+	for body, expected := range cases {
+		if got := string(sanitizeTraceBody([]byte(body))); got != expected {
+			t.Errorf("sanitizeTraceBody(%s) = %s, expected %s", body, got, expected)
+		}
+	}
+}
+
+func TestTraceTransport_fake(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	t.Setenv(traceFileEnv, path)
+
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "top-secret"})
+	if _, _, err := m.ApiClient.VariableApi.GetVariable(m.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+
+	trace, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read trace file: %s", err)
+	}
+
+	if !strings.Contains(string(trace), "GET "+fake.URL+"/api/v1/variables/foo") {
+		t.Fatalf("expected the request to be traced:\n%s", trace)
+	}
+	if !strings.Contains(string(trace), "Authorization: REDACTED") {
+		t.Fatalf("expected the authorization header to be redacted:\n%s", trace)
+	}
+	if strings.Contains(string(trace), "top-secret") {
+		t.Fatalf("expected the variable value to be redacted:\n%s", trace)
+	}
+}
+
+func TestTraceTransport_configuredHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.log")
+	t.Setenv(traceFileEnv, path)
+
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{
+		"max_retries": 0,
+		"headers":     map[string]interface{}{"X-Api-Key": "key-secret"},
+	})
+
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "bar"})
+	if _, _, err := m.ApiClient.VariableApi.GetVariable(m.AuthContext, "foo").Execute(); err != nil {
+		t.Fatalf("get variable: %s", err)
+	}
+
+	trace, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read trace file: %s", err)
+	}
+
+	if strings.Contains(string(trace), "key-secret") {
+		t.Fatalf("expected the configured header to be redacted:\n%s", trace)
+	}
+	if !strings.Contains(string(trace), "X-Api-Key: REDACTED") {
+		t.Fatalf("expected the configured header to be traced as redacted:\n%s", trace)
+	}
+}
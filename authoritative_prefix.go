@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// authoritativePrefixSchema makes a bulk resource authoritative for the
+// objects whose name starts with the prefix, e.g. the namespace of a team:
+// those missing from the configuration are deleted, while the objects of
+// other teams are left alone.
+func authoritativePrefixSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+}
+
+// unmanagedSchema lists the objects matching the authoritative_prefix that
+// aren't in the configuration. They are found on refresh and deleted by the
+// following apply.
+func unmanagedSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// isUnmanaged returns whether a remote object named name falls under the
+// authoritative_prefix without being managed.
+func isUnmanaged(prefix, name string, managed bool) bool {
+	return prefix != "" && !managed && strings.HasPrefix(name, prefix)
+}
+
+// setUnmanaged stores the keys of the unmanaged objects sorted, so that the
+// order of the API doesn't cause a diff.
+func setUnmanaged(d *schema.ResourceData, keys []string) {
+	sort.Strings(keys)
+	d.Set("unmanaged", keys)
+}
+
+// deleteUnmanaged deletes the unmanaged objects recorded in state by the last
+// refresh, which the plan showed in pending_changes. It must be called before
+// the objects are read again: objects that appeared since the refresh are
+// only deleted by the apply following the next one, and objects added to the
+// configuration in block, matched by keyAttr, are kept. Nothing is deleted
+// while the authoritative_prefix changes, as the recorded objects fell under
+// the previous one.
+func deleteUnmanaged(d *schema.ResourceData, m interface{}, block, keyAttr string, deleteFn func(key string) error) error {
+	if d.HasChange("authoritative_prefix") {
+		return nil
+	}
+
+	configured := map[string]bool{}
+	for _, v := range d.Get(block).([]interface{}) {
+		configured[v.(map[string]interface{})[keyAttr].(string)] = true
+	}
+
+	recorded, _ := d.GetChange("unmanaged")
+	var calls []func() error
+	for _, v := range recorded.([]interface{}) {
+		key := v.(string)
+		if configured[key] {
+			continue
+		}
+		log.Printf("[INFO] Deleting `%s`, it matches the authoritative_prefix but isn't configured", key)
+		calls = append(calls, func() error { return deleteFn(key) })
+	}
+
+	return runConcurrently(m, calls)
+}
+
+// unmanagedKeys returns the keys of the unmanaged objects deleteUnmanaged
+// deletes, for callers that need to resolve them first.
+func unmanagedKeys(d *schema.ResourceData) []interface{} {
+	if d.HasChange("authoritative_prefix") {
+		return nil
+	}
+	recorded, _ := d.GetChange("unmanaged")
+	return recorded.([]interface{})
+}
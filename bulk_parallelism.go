@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// runConcurrently makes the API calls of a bulk resource with at most the
+// provider bulk_parallelism of them in flight, so that reconciling hundreds
+// of objects doesn't take one round trip each. All calls are made even when
+// some fail, and the errors are reported in the order of the calls. A panic
+// of a call is returned as its error, as the workers are out of reach of the
+// recover of wrapOperation.
+func runConcurrently(m interface{}, calls []func() error) error {
+	pcfg := m.(ProviderConfig)
+
+	workers := pcfg.BulkParallelism
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, len(calls))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(calls); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = runRecovered(calls[i])
+			}
+		}()
+	}
+	for i := range calls {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return failed[0]
+	}
+
+	msgs := make([]string, 0, len(failed)-1)
+	for _, err := range failed[1:] {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Errorf("%w; and %d more: %s", failed[0], len(msgs), strings.Join(msgs, "; "))
+}
+
+func runRecovered(call func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] Recovered from panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("unexpected error, this is a bug in the provider, please report it: %v", r)
+		}
+	}()
+
+	return call()
+}
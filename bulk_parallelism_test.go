@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	m := ProviderConfig{BulkParallelism: 3}
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	calls := make([]func() error, 0, 10)
+	for i := 0; i < 10; i++ {
+		i := i
+		calls = append(calls, func() error {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+
+			if i == 2 || i == 7 {
+				return fmt.Errorf("call %d failed", i)
+			}
+			return nil
+		})
+	}
+
+	err := runConcurrently(m, calls)
+	if maxInFlight != 3 {
+		t.Fatalf("expected at most 3 calls in flight, got %d", maxInFlight)
+	}
+	if err == nil || !strings.HasPrefix(err.Error(), "call 2 failed; and 1 more: call 7 failed") {
+		t.Fatalf("expected both failures in order, got %v", err)
+	}
+
+	if err := runConcurrently(m, nil); err != nil {
+		t.Fatalf("expected no error without calls, got %s", err)
+	}
+}
+
+func TestRunConcurrently_recoversPanic(t *testing.T) {
+	m := ProviderConfig{BulkParallelism: 2}
+
+	calls := []func() error{
+		func() error { return nil },
+		func() error {
+			var roles *[]string
+			_ = len(*roles)
+			return nil
+		},
+		func() error { return fmt.Errorf("call 2 failed") },
+	}
+
+	err := runConcurrently(m, calls)
+	if err == nil || !strings.HasPrefix(err.Error(), "unexpected error, this is a bug in the provider") || !strings.Contains(err.Error(), "call 2 failed") {
+		t.Fatalf("expected the panic to be returned as the error of its call, got %v", err)
+	}
+}
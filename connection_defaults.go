@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// connectionDefaults are extra fields the provider merges into the extra of
+// every airflow_connection, or of those with the given connection type.
+type connectionDefaults struct {
+	ConnType string
+	Extra    map[string]interface{}
+}
+
+func expandConnectionDefaults(tfList []interface{}) ([]connectionDefaults, error) {
+	apiObjects := make([]connectionDefaults, 0, len(tfList))
+	for i, tfMapRaw := range tfList {
+		tfMap, ok := tfMapRaw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		apiObject := connectionDefaults{ConnType: tfMap["conn_type"].(string)}
+		if err := json.Unmarshal([]byte(tfMap["extra"].(string)), &apiObject.Extra); err != nil {
+			return nil, fmt.Errorf("connection_defaults.%d.extra must be a JSON object: %w", i, err)
+		}
+		apiObjects = append(apiObjects, apiObject)
+	}
+	return apiObjects, nil
+}
+
+// connectionExtraDefaults returns the provider connection_defaults that apply
+// to a connection type as a JSON object, or an empty string when there are
+// none. Later blocks take precedence over earlier ones.
+func connectionExtraDefaults(m interface{}, connType string) string {
+	extra := map[string]interface{}{}
+	for _, defaults := range m.(ProviderConfig).ConnectionDefaults {
+		if defaults.ConnType != "" && defaults.ConnType != connType {
+			continue
+		}
+		for k, v := range defaults.Extra {
+			extra[k] = v
+		}
+	}
+	if len(extra) == 0 {
+		return ""
+	}
+
+	v, _ := json.Marshal(extra)
+	return string(v)
+}
+
+// mergeConnectionExtraDefaults returns the configured extra with the default
+// fields it doesn't set. An extra that isn't a JSON object is returned as is.
+func mergeConnectionExtraDefaults(defaults, extra string) string {
+	if defaults == "" {
+		return extra
+	}
+
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(defaults), &merged); err != nil {
+		return extra
+	}
+	if extra != "" {
+		configured := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(extra), &configured); err != nil {
+			return extra
+		}
+		for k, v := range configured {
+			merged[k] = v
+		}
+	}
+
+	v, _ := json.Marshal(merged)
+	return string(v)
+}
+
+// customizeConnectionExtraDefaultsDiff plans extra_defaults as the provider
+// connection_defaults of the connection type, so that connections are
+// updated when the defaults change.
+func customizeConnectionExtraDefaultsDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("conn_type") {
+		return d.SetNewComputed("extra_defaults")
+	}
+
+	defaults := connectionExtraDefaults(m, d.Get("conn_type").(string))
+	if defaults == d.Get("extra_defaults").(string) {
+		return nil
+	}
+	return d.SetNew("extra_defaults", defaults)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// connectionExtraBlock is a typed block of airflow_connection that renders
+// the `extra` JSON expected by the Airflow provider package of a connection
+// type.
+type connectionExtraBlock struct {
+	schema map[string]*schema.Schema
+	render func(tfMap map[string]interface{}) map[string]interface{}
+}
+
+var connectionExtraBlocks = map[string]connectionExtraBlock{
+	"aws_extra": {
+		schema: map[string]*schema.Schema{
+			"region_name":  {Type: schema.TypeString, Optional: true},
+			"role_arn":     {Type: schema.TypeString, Optional: true},
+			"external_id":  {Type: schema.TypeString, Optional: true},
+			"endpoint_url": {Type: schema.TypeString, Optional: true},
+		},
+		render: func(tfMap map[string]interface{}) map[string]interface{} {
+			extra := connectionExtraStrings(tfMap, "region_name", "role_arn", "endpoint_url")
+			if v := tfMap["external_id"].(string); v != "" {
+				extra["assume_role_kwargs"] = map[string]interface{}{"ExternalId": v}
+			}
+			return extra
+		},
+	},
+	"google_cloud_platform_extra": {
+		schema: map[string]*schema.Schema{
+			"project":      {Type: schema.TypeString, Optional: true},
+			"key_path":     {Type: schema.TypeString, Optional: true},
+			"keyfile_dict": {Type: schema.TypeString, Optional: true, Sensitive: true},
+			"scopes":       {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+			"num_retries":  {Type: schema.TypeInt, Optional: true},
+		},
+		render: func(tfMap map[string]interface{}) map[string]interface{} {
+			extra := connectionExtraStrings(tfMap, "project", "key_path", "keyfile_dict")
+			var scopes []string
+			for _, v := range tfMap["scopes"].([]interface{}) {
+				scopes = append(scopes, v.(string))
+			}
+			if len(scopes) > 0 {
+				extra["scope"] = strings.Join(scopes, ",")
+			}
+			if v := tfMap["num_retries"].(int); v > 0 {
+				extra["num_retries"] = v
+			}
+			return extra
+		},
+	},
+	"postgres_extra": {
+		schema: map[string]*schema.Schema{
+			"sslmode":     {Type: schema.TypeString, Optional: true},
+			"sslrootcert": {Type: schema.TypeString, Optional: true},
+			"sslcert":     {Type: schema.TypeString, Optional: true},
+			"sslkey":      {Type: schema.TypeString, Optional: true},
+			"iam":         {Type: schema.TypeBool, Optional: true},
+			"aws_conn_id": {Type: schema.TypeString, Optional: true},
+		},
+		render: func(tfMap map[string]interface{}) map[string]interface{} {
+			extra := connectionExtraStrings(tfMap, "sslmode", "sslrootcert", "sslcert", "sslkey", "aws_conn_id")
+			if tfMap["iam"].(bool) {
+				extra["iam"] = true
+			}
+			return extra
+		},
+	},
+	"snowflake_extra": {
+		schema: map[string]*schema.Schema{
+			"account":             {Type: schema.TypeString, Optional: true},
+			"warehouse":           {Type: schema.TypeString, Optional: true},
+			"database":            {Type: schema.TypeString, Optional: true},
+			"role":                {Type: schema.TypeString, Optional: true},
+			"region":              {Type: schema.TypeString, Optional: true},
+			"authenticator":       {Type: schema.TypeString, Optional: true},
+			"private_key_content": {Type: schema.TypeString, Optional: true, Sensitive: true},
+		},
+		render: func(tfMap map[string]interface{}) map[string]interface{} {
+			return connectionExtraStrings(tfMap, "account", "warehouse", "database", "role", "region", "authenticator", "private_key_content")
+		},
+	},
+	"databricks_extra": {
+		schema: map[string]*schema.Schema{
+			"token":                      {Type: schema.TypeString, Optional: true, Sensitive: true},
+			"http_path":                  {Type: schema.TypeString, Optional: true},
+			"use_azure_managed_identity": {Type: schema.TypeBool, Optional: true},
+			"azure_tenant_id":            {Type: schema.TypeString, Optional: true},
+		},
+		render: func(tfMap map[string]interface{}) map[string]interface{} {
+			extra := connectionExtraStrings(tfMap, "token", "http_path", "azure_tenant_id")
+			if tfMap["use_azure_managed_identity"].(bool) {
+				extra["use_azure_managed_identity"] = true
+			}
+			return extra
+		},
+	},
+}
+
+// connectionExtraStrings returns the non-empty string attributes of a typed
+// block as extra fields of the same name.
+func connectionExtraStrings(tfMap map[string]interface{}, keys ...string) map[string]interface{} {
+	extra := map[string]interface{}{}
+	for _, k := range keys {
+		if v := tfMap[k].(string); v != "" {
+			extra[k] = v
+		}
+	}
+	return extra
+}
+
+// addConnectionExtraBlocks adds the typed extra blocks to the connection
+// schema. Each of them conflicts with `extra` and the other blocks.
+func addConnectionExtraBlocks(s map[string]*schema.Schema) {
+	for name, block := range connectionExtraBlocks {
+		conflicts := []string{"extra"}
+		for other := range connectionExtraBlocks {
+			if other != name {
+				conflicts = append(conflicts, other)
+			}
+		}
+
+		s[name] = &schema.Schema{
+			Type:          schema.TypeList,
+			Optional:      true,
+			MaxItems:      1,
+			ConflictsWith: conflicts,
+			Elem:          &schema.Resource{Schema: block.schema},
+		}
+	}
+}
+
+// renderConnectionExtra returns the extra JSON rendered from the configured
+// typed block, if any.
+func renderConnectionExtra(d *schema.ResourceData) (string, bool) {
+	for name, block := range connectionExtraBlocks {
+		v, ok := d.GetOk(name)
+		if !ok {
+			continue
+		}
+
+		// A block without any attributes renders an empty object.
+		tfMap, _ := v.([]interface{})[0].(map[string]interface{})
+		if tfMap == nil {
+			tfMap = map[string]interface{}{}
+			for k, s := range block.schema {
+				tfMap[k] = s.ZeroValue()
+			}
+		}
+
+		// The rendered values are plain strings, numbers and booleans, which
+		// always marshal.
+		extra, _ := json.Marshal(block.render(tfMap))
+		return string(extra), true
+	}
+
+	return "", false
+}
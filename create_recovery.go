@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// createOutcomeUnknown returns whether a failed create may have created the
+// object anyway: a proxy in front of Airflow can time out or drop the
+// connection while Airflow goes on with the request.
+func createOutcomeUnknown(resp *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if resp == nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// createdDespiteError returns whether the object of a create whose outcome
+// is unknown exists, reading it into d with id if so. Without it, the object
+// would be created in Airflow but missing from state, and the next apply
+// would fail with a conflict or create a duplicate.
+func createdDespiteError(d *schema.ResourceData, m interface{}, kind, id string, resp *http.Response, err error, read schema.ReadFunc) bool {
+	if !createOutcomeUnknown(resp, err) {
+		return false
+	}
+
+	d.SetId(id)
+	if readErr := read(d, m); readErr != nil || d.Id() == "" {
+		log.Printf("[DEBUG] Failed create of %s `%s` didn't create it: %v", kind, id, readErr)
+		d.SetId("")
+		return false
+	}
+
+	log.Printf("[WARN] Creating %s `%s` failed with %s, but it was created, recording it in state", kind, id, err)
+	return true
+}
+
+// readAfterCreate reads a created object into state. When reading fails, the
+// ID is kept so that Terraform records the object as tainted and replaces it
+// on the next apply, instead of losing track of it.
+func readAfterCreate(d *schema.ResourceData, m interface{}, kind string, read schema.ReadFunc) error {
+	id := d.Id()
+	if err := read(d, m); err != nil {
+		if d.Id() == "" {
+			d.SetId(id)
+		}
+		return fmt.Errorf("%s `%s` was created, but reading it back failed, it is recorded in state as tainted: %w", kind, id, err)
+	}
+
+	return nil
+}
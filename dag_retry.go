@@ -0,0 +1,37 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// dagNotFoundRetryInterval is how long to wait between calls to a DAG scoped
+// endpoint that failed because the DAG wasn't found.
+var dagNotFoundRetryInterval = 10 * time.Second
+
+// retryWhileDagNotFound calls a DAG scoped endpoint until it doesn't fail with
+// 404 or the dag_not_found_retry_timeout of the provider passed. Cloud
+// Composer and MWAA sync DAG folders asynchronously, so a DAG uploaded right
+// before the apply may not be parsed yet.
+func retryWhileDagNotFound(pcfg ProviderConfig, dagId string, call func() (*http.Response, error)) (*http.Response, error) {
+	deadline := time.Now().Add(pcfg.DagNotFoundRetryTimeout)
+
+	for {
+		resp, err := call()
+		if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+			return resp, err
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return resp, err
+		}
+		if wait > dagNotFoundRetryInterval {
+			wait = dagNotFoundRetryInterval
+		}
+
+		log.Printf("[DEBUG] DAG `%s` not found, retrying in %s while the DAG folder syncs", dagId, wait)
+		time.Sleep(wait)
+	}
+}
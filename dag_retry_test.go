@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRetryWhileDagNotFound_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.DagNotFoundRetryTimeout = time.Minute
+
+	interval := dagNotFoundRetryInterval
+	dagNotFoundRetryInterval = time.Millisecond
+	t.Cleanup(func() { dagNotFoundRetryInterval = interval })
+
+	dag := map[string]interface{}{"dag_id": "synced", "is_paused": false}
+	fake.handle(http.MethodPatch, "/dags/synced", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+	fake.handle(http.MethodGet, "/dags/synced", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+
+	// The DAG isn't parsed yet for the first calls.
+	fake.failNext(http.MethodPatch, "/dags/synced", http.StatusNotFound, 2)
+
+	d := schema.TestResourceDataRaw(t, resourceDag().Schema, map[string]interface{}{
+		"dag_id":    "synced",
+		"is_paused": false,
+	})
+	if err := resourceDagUpdate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := fake.requestCount(http.MethodPatch, "/dags/synced"); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryWhileDagNotFound_fakeDisabled(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceDag().Schema, map[string]interface{}{
+		"dag_id":    "missing",
+		"is_paused": false,
+	})
+	if err := resourceDagUpdate(d, m); err == nil {
+		t.Fatal("expected an error for a missing DAG")
+	}
+	if got := fake.requestCount(http.MethodPatch, "/dags/missing"); got != 1 {
+		t.Fatalf("expected a single attempt without a retry timeout, got %d", got)
+	}
+}
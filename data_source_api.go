@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceApi() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceApiRead,
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"query": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"result_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"accepted_status_codes": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(100, 599),
+				},
+			},
+			"status_code": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"headers": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"body": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"response": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"result": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceApiRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	path := d.Get("path").(string)
+
+	query := url.Values{}
+	for k, v := range d.Get("query").(map[string]interface{}) {
+		query.Set(k, v.(string))
+	}
+
+	resp, body, err := apiRawRequest(pcfg, http.MethodGet, path, query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get `%s` from Airflow: %w", path, err)
+	}
+
+	// Without accepted_status_codes any successful response is accepted,
+	// otherwise only the listed ones, e.g. a 404 to check whether an
+	// object exists.
+	accepted := resp.StatusCode < 300
+	if v, ok := d.GetOk("accepted_status_codes"); ok {
+		accepted = v.(*schema.Set).Contains(resp.StatusCode)
+	}
+	if !accepted {
+		return fmt.Errorf("failed to get `%s` from Airflow: %s %s", path, resp.Status, body)
+	}
+
+	var response interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &response); err != nil {
+			return fmt.Errorf("failed to decode response of `%s`: %w", path, err)
+		}
+	}
+
+	result := response
+	if v := d.Get("result_path").(string); v != "" {
+		if result, err = extractJSONPath(response, v); err != nil {
+			return fmt.Errorf("failed to get `%s` of the response of `%s`: %w", v, path, err)
+		}
+	}
+
+	encodedResponse, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to encode response of `%s`: %w", path, err)
+	}
+	encodedResult, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result of `%s`: %w", path, err)
+	}
+
+	d.SetId(path)
+	d.Set("response", string(encodedResponse))
+	d.Set("result", string(encodedResult))
+	d.Set("status_code", resp.StatusCode)
+	d.Set("body", string(body))
+
+	headers := make(map[string]interface{}, len(resp.Header))
+	for k, v := range resp.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
+	if err := d.Set("headers", headers); err != nil {
+		return fmt.Errorf("error setting headers: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceApi_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/plugins", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "1" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "missing limit")
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"plugins":       []interface{}{map[string]interface{}{"name": "example"}},
+			"total_entries": 1,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceApi().Schema, map[string]interface{}{
+		"path":        "/plugins",
+		"query":       map[string]interface{}{"limit": "1"},
+		"result_path": "plugins[0].name",
+	})
+	if err := dataSourceApiRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("result").(string); got != `"example"` {
+		t.Fatalf("unexpected result %s", got)
+	}
+	if got := d.Get("response").(string); got != `{"plugins":[{"name":"example"}],"total_entries":1}` {
+		t.Fatalf("unexpected response %s", got)
+	}
+}
+
+func TestDataSourceApi_fakeNotFound(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourceApi().Schema, map[string]interface{}{
+		"path": "/unknown",
+	})
+	if err := dataSourceApiRead(d, m); err == nil {
+		t.Fatal("expected an error for an unknown endpoint")
+	}
+}
+
+func TestDataSourceApi_fakeAcceptedStatusCodes(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourceApi().Schema, map[string]interface{}{
+		"path":                  "/variables/missing",
+		"accepted_status_codes": []interface{}{200, 404},
+		"result_path":           "status",
+	})
+	if err := dataSourceApiRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("status_code").(int); got != http.StatusNotFound {
+		t.Fatalf("unexpected status code %d", got)
+	}
+	if got := d.Get("result").(string); got != "404" {
+		t.Fatalf("unexpected result %s", got)
+	}
+	if got := d.Get("headers").(map[string]interface{})["Content-Type"]; got != "application/json" {
+		t.Fatalf("unexpected content type %v", got)
+	}
+	if d.Get("body").(string) == "" {
+		t.Fatal("expected the raw body")
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceApi().Schema, map[string]interface{}{
+		"path":                  "/variables/missing",
+		"accepted_status_codes": []interface{}{409},
+	})
+	if err := dataSourceApiRead(d, m); err == nil {
+		t.Fatal("expected an error for a status code that isn't accepted")
+	}
+}
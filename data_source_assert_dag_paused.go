@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAssertDagPaused() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAssertDagPausedRead,
+		Schema: map[string]*schema.Schema{
+			"dag_ids": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"paused": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"allow_missing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"passed": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"failed_dag_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"missing_dag_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"details": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// dataSourceAssertDagPausedRead checks the pause state of DAGs for use in a
+// check block. DAGs that don't match are reported rather than failing the
+// read, which only fails when Airflow can't be asked.
+func dataSourceAssertDagPausedRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	dagIds := expandStringSet(d.Get("dag_ids").(*schema.Set))
+	sort.Strings(dagIds)
+	paused := d.Get("paused").(bool)
+
+	state := "unpaused"
+	if paused {
+		state = "paused"
+	}
+
+	failed := []string{}
+	missing := []string{}
+	details := []string{}
+	for _, dagId := range dagIds {
+		dag, resp, err := client.DAGApi.GetDag(pcfg.AuthContext, dagId).Execute()
+		if resp != nil && resp.StatusCode == 404 {
+			missing = append(missing, dagId)
+			if !d.Get("allow_missing").(bool) {
+				details = append(details, fmt.Sprintf("DAG `%s` doesn't exist", dagId))
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get DAG `%s` from Airflow: %w", dagId, apiPermissionError(resp, err, "can_read on DAG:"+dagId))
+		}
+
+		if dag.GetIsPaused() != paused {
+			failed = append(failed, dagId)
+			details = append(details, fmt.Sprintf("DAG `%s` isn't %s", dagId, state))
+		}
+	}
+
+	d.SetId(strings.Join(dagIds, ","))
+	d.Set("passed", len(details) == 0)
+	d.Set("failed_dag_ids", failed)
+	d.Set("missing_dag_ids", missing)
+	d.Set("details", details)
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceAssertDagPaused_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	for dagId, paused := range map[string]bool{"paused": true, "running": false} {
+		dag := map[string]interface{}{"dag_id": dagId, "is_paused": paused}
+		fake.handle(http.MethodGet, "/dags/"+dagId, func(w http.ResponseWriter, r *http.Request) {
+			writeFakeAirflowJSON(w, http.StatusOK, dag)
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceAssertDagPaused().Schema, map[string]interface{}{
+		"dag_ids": []interface{}{"paused", "running", "missing"},
+	})
+	if err := dataSourceAssertDagPausedRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("passed").(bool) {
+		t.Fatal("expected the assertion to fail")
+	}
+	if got := d.Get("failed_dag_ids").([]interface{}); !reflect.DeepEqual(got, []interface{}{"running"}) {
+		t.Fatalf("unexpected failed_dag_ids %v", got)
+	}
+	if got := d.Get("missing_dag_ids").([]interface{}); !reflect.DeepEqual(got, []interface{}{"missing"}) {
+		t.Fatalf("unexpected missing_dag_ids %v", got)
+	}
+	if got := len(d.Get("details").([]interface{})); got != 2 {
+		t.Fatalf("expected 2 details, got %d", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceAssertDagPaused().Schema, map[string]interface{}{
+		"dag_ids":       []interface{}{"paused", "missing"},
+		"allow_missing": true,
+	})
+	if err := dataSourceAssertDagPausedRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if !d.Get("passed").(bool) {
+		t.Fatalf("expected the assertion to pass, got %v", d.Get("details"))
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAssertNoImportErrors() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAssertNoImportErrorsRead,
+		Schema: map[string]*schema.Schema{
+			"filename_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"passed": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"filenames": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"import_errors": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"filename": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"stack_trace": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"details": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+// dataSourceAssertNoImportErrorsRead reports the DAG files Airflow failed to
+// import for use in a check block.
+func dataSourceAssertNoImportErrorsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	prefix := d.Get("filename_prefix").(string)
+	var matches []airflow.ImportError
+	key := func(e airflow.ImportError) string { return fmt.Sprint(e.GetImportErrorId()) }
+	err := forEachPage("import errors", key, func(limit, offset int32) ([]airflow.ImportError, int32, error) {
+		page, resp, err := client.ImportErrorApi.GetImportErrors(pcfg.AuthContext).Limit(limit).Offset(offset).Execute()
+		return page.GetImportErrors(), page.GetTotalEntries(), apiPermissionError(resp, err, "can_read on ImportError")
+	}, func(e airflow.ImportError) bool {
+		if strings.HasPrefix(e.GetFilename(), prefix) {
+			matches = append(matches, e)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].GetFilename() < matches[j].GetFilename() })
+
+	filenames := make([]string, 0, len(matches))
+	importErrors := make([]interface{}, 0, len(matches))
+	details := make([]string, 0, len(matches))
+	for _, e := range matches {
+		filenames = append(filenames, e.GetFilename())
+		importErrors = append(importErrors, map[string]interface{}{
+			"filename":    e.GetFilename(),
+			"timestamp":   e.GetTimestamp(),
+			"stack_trace": e.GetStackTrace(),
+		})
+		details = append(details, fmt.Sprintf("`%s` failed to import: %s", e.GetFilename(), importErrorSummary(e.GetStackTrace())))
+	}
+
+	d.SetId("import-errors:" + prefix)
+	d.Set("passed", len(matches) == 0)
+	d.Set("filenames", filenames)
+	if err := d.Set("import_errors", importErrors); err != nil {
+		return fmt.Errorf("error setting import_errors: %w", err)
+	}
+	d.Set("details", details)
+
+	return nil
+}
+
+// importErrorSummary returns the last line of a stack trace, which holds the
+// exception that was raised.
+func importErrorSummary(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceAssertNoImportErrors_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/importErrors", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"import_errors": []interface{}{
+				map[string]interface{}{
+					"import_error_id": 2,
+					"filename":        "/opt/airflow/dags/team_b/etl.py",
+					"timestamp":       "2024-05-01T00:00:00+00:00",
+					"stack_trace":     "Traceback (most recent call last):\n  File \"etl.py\", line 1\nModuleNotFoundError: No module named 'pandas'\n",
+				},
+				map[string]interface{}{
+					"import_error_id": 1,
+					"filename":        "/opt/airflow/dags/team_a/report.py",
+					"stack_trace":     "SyntaxError: invalid syntax",
+				},
+			},
+			"total_entries": 2,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceAssertNoImportErrors().Schema, map[string]interface{}{})
+	if err := dataSourceAssertNoImportErrorsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Get("passed").(bool) {
+		t.Fatal("expected the assertion to fail")
+	}
+	if got := d.Get("filenames").([]interface{}); !reflect.DeepEqual(got, []interface{}{"/opt/airflow/dags/team_a/report.py", "/opt/airflow/dags/team_b/etl.py"}) {
+		t.Fatalf("unexpected filenames %v", got)
+	}
+	if got := d.Get("details.1").(string); got != "`/opt/airflow/dags/team_b/etl.py` failed to import: ModuleNotFoundError: No module named 'pandas'" {
+		t.Fatalf("unexpected detail %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceAssertNoImportErrors().Schema, map[string]interface{}{
+		"filename_prefix": "/opt/airflow/dags/team_c/",
+	})
+	if err := dataSourceAssertNoImportErrorsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if !d.Get("passed").(bool) {
+		t.Fatalf("expected the assertion to pass, got %v", d.Get("details"))
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// airflowComponentHealth is the health of an Airflow component as reported
+// by the health endpoint. The generated client doesn't know the triggerer,
+// which was added in Airflow 2.6, so the response is decoded here.
+type airflowComponentHealth struct {
+	Status                   *string `json:"status"`
+	LatestSchedulerHeartbeat *string `json:"latest_scheduler_heartbeat"`
+	LatestTriggererHeartbeat *string `json:"latest_triggerer_heartbeat"`
+}
+
+type airflowHealth struct {
+	Scheduler *airflowComponentHealth `json:"scheduler"`
+	Triggerer *airflowComponentHealth `json:"triggerer"`
+}
+
+// airflowComponent picks the health and the latest heartbeat of a
+// component from the health response.
+type airflowComponent func(airflowHealth) (*airflowComponentHealth, *string)
+
+func schedulerHealth(h airflowHealth) (*airflowComponentHealth, *string) {
+	if h.Scheduler == nil {
+		return nil, nil
+	}
+	return h.Scheduler, h.Scheduler.LatestSchedulerHeartbeat
+}
+
+// triggererHealth is missing from the health response before Airflow 2.6.
+func triggererHealth(h airflowHealth) (*airflowComponentHealth, *string) {
+	if h.Triggerer == nil {
+		return nil, nil
+	}
+	return h.Triggerer, h.Triggerer.LatestTriggererHeartbeat
+}
+
+func dataSourceSchedulerStatus() *schema.Resource {
+	return dataSourceComponentStatus(schedulerHealth)
+}
+
+func dataSourceTriggererStatus() *schema.Resource {
+	return dataSourceComponentStatus(triggererHealth)
+}
+
+// dataSourceComponentStatus returns a data source exposing the status and
+// the latest heartbeat of a component. With retry, the component is polled
+// until it is healthy.
+func dataSourceComponentStatus(component airflowComponent) *schema.Resource {
+	return withReadRetry(&schema.Resource{
+		Read: func(d *schema.ResourceData, m interface{}) error {
+			return dataSourceComponentStatusRead(d, m, component, time.Now())
+		},
+		Schema: map[string]*schema.Schema{
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"healthy": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"latest_heartbeat": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"heartbeat_age_seconds": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}, func(d *schema.ResourceData) bool {
+		return !d.Get("healthy").(bool)
+	})
+}
+
+func dataSourceComponentStatusRead(d *schema.ResourceData, m interface{}, component airflowComponent, now time.Time) error {
+	pcfg := m.(ProviderConfig)
+
+	var health airflowHealth
+	if _, err := apiRequest(pcfg, http.MethodGet, "/health", nil, nil, &health); err != nil {
+		return fmt.Errorf("failed to get health from Airflow: %w", err)
+	}
+
+	status := ""
+	heartbeat := ""
+	age := -1
+	if c, latest := component(health); c != nil {
+		if c.Status != nil {
+			status = *c.Status
+		}
+		if latest != nil && *latest != "" {
+			t, err := parseAirflowTime(*latest)
+			if err != nil {
+				return fmt.Errorf("invalid heartbeat `%s` reported by Airflow: %w", *latest, err)
+			}
+			heartbeat = t.UTC().Format(time.RFC3339)
+			age = int(now.Sub(t).Seconds())
+		}
+	}
+
+	d.SetId("health")
+	d.Set("status", status)
+	d.Set("healthy", status == "healthy")
+	d.Set("latest_heartbeat", heartbeat)
+	d.Set("heartbeat_age_seconds", age)
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceComponentStatus_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"metadatabase": map[string]interface{}{"status": "healthy"},
+			"scheduler": map[string]interface{}{
+				"status":                     "healthy",
+				"latest_scheduler_heartbeat": "2022-05-01T12:00:00.123456+00:00",
+			},
+			"triggerer": map[string]interface{}{
+				"status":                     nil,
+				"latest_triggerer_heartbeat": nil,
+			},
+		})
+	})
+
+	now := time.Date(2022, 5, 1, 12, 1, 30, 0, time.UTC)
+
+	d := schema.TestResourceDataRaw(t, dataSourceSchedulerStatus().Schema, map[string]interface{}{})
+	if err := dataSourceComponentStatusRead(d, m, schedulerHealth, now); err != nil {
+		t.Fatalf("read scheduler: %s", err)
+	}
+	if !d.Get("healthy").(bool) || d.Get("latest_heartbeat") != "2022-05-01T12:00:00Z" || d.Get("heartbeat_age_seconds") != 89 {
+		t.Fatalf("unexpected scheduler status %v %v %v", d.Get("healthy"), d.Get("latest_heartbeat"), d.Get("heartbeat_age_seconds"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceTriggererStatus().Schema, map[string]interface{}{})
+	if err := dataSourceComponentStatusRead(d, m, triggererHealth, now); err != nil {
+		t.Fatalf("read triggerer: %s", err)
+	}
+	if d.Get("healthy").(bool) || d.Get("status") != "" || d.Get("heartbeat_age_seconds") != -1 {
+		t.Fatalf("unexpected triggerer status %v %v %v", d.Get("healthy"), d.Get("status"), d.Get("heartbeat_age_seconds"))
+	}
+}
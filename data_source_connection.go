@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceConnection() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"connection_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"port": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"password": {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+		"extra": {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+		"sensitive_state_mode": sensitiveStateModeSchema(),
+		"ui_url":               uiUrlSchema(),
+	}
+	for _, k := range []string{"conn_type", "host", "login", "schema"} {
+		s[k] = &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		}
+	}
+
+	return &schema.Resource{
+		Read:   dataSourceConnectionRead,
+		Schema: s,
+	}
+}
+
+func dataSourceConnectionRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	connId := d.Get("connection_id").(string)
+	connection, resp, err := client.ConnectionApi.GetConnection(pcfg.AuthContext, connId).Execute()
+	if resp != nil && resp.StatusCode == 404 {
+		return fmt.Errorf("connection `%s` not found in Airflow", connId)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get connection `%s` from Airflow: %w", connId, apiPermissionError(resp, err, "can_read on Connections"))
+	}
+
+	// The secrets are stored in state like those of airflow_connection.
+	mode := effectiveSensitiveStateMode(m, sensitiveStatePlain)
+
+	d.SetId(connection.GetConnectionId())
+	d.Set("conn_type", connection.GetConnType())
+	d.Set("host", connection.GetHost())
+	d.Set("login", connection.GetLogin())
+	d.Set("schema", connection.GetSchema())
+	d.Set("port", connection.GetPort())
+	d.Set("password", sensitiveStateValue(mode, connection.GetPassword()))
+	d.Set("extra", sensitiveStateValue(mode, connection.GetExtra()))
+	d.Set("sensitive_state_mode", mode)
+	d.Set("ui_url", airflowUiUrl(m, "connection", connection.GetConnectionId()))
+
+	return nil
+}
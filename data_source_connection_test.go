@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceConnection_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("connections", map[string]interface{}{
+		"connection_id": "warehouse",
+		"conn_type":     "postgres",
+		"host":          "db.internal",
+		"port":          5432,
+		"extra":         `{"sslmode":"require"}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceConnection().Schema, map[string]interface{}{
+		"connection_id": "warehouse",
+	})
+	if err := dataSourceConnectionRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("conn_type").(string) != "postgres" || d.Get("host").(string) != "db.internal" || d.Get("port").(int) != 5432 {
+		t.Fatalf("unexpected connection %v %v %v", d.Get("conn_type"), d.Get("host"), d.Get("port"))
+	}
+	if got := d.Get("extra").(string); got != `{"sslmode":"require"}` {
+		t.Fatalf("unexpected extra %q", got)
+	}
+}
+
+func TestDataSourceConnection_fakeHashedSecrets(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.SensitiveStateMode = sensitiveStateHash
+
+	fake.seed("connections", map[string]interface{}{
+		"connection_id": "warehouse",
+		"conn_type":     "postgres",
+		"extra":         `{"sslmode":"require"}`,
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceConnection().Schema, map[string]interface{}{
+		"connection_id": "warehouse",
+	})
+	if err := dataSourceConnectionRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("extra").(string); got != sha256Hex(`{"sslmode":"require"}`) {
+		t.Fatalf("expected the hashed extra, got %q", got)
+	}
+	if got := d.Get("sensitive_state_mode").(string); got != sensitiveStateHash {
+		t.Fatalf("unexpected sensitive_state_mode %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDagRun() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"dag_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"dag_run_id": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"finished": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"ui_url": uiUrlSchema(),
+	}
+	for _, k := range []string{"state", "run_type", "logical_date", "start_date", "end_date"} {
+		s[k] = &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		}
+	}
+	s["duration"] = &schema.Schema{
+		Type:     schema.TypeFloat,
+		Computed: true,
+	}
+
+	// With retry, the run is polled until it finishes.
+	return withReadRetry(&schema.Resource{
+		Read:   dataSourceDagRunRead,
+		Schema: s,
+	}, func(d *schema.ResourceData) bool {
+		return !d.Get("finished").(bool)
+	})
+}
+
+func dataSourceDagRunRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	dagId := d.Get("dag_id").(string)
+	dagRunId := d.Get("dag_run_id").(string)
+	dagRun, resp, err := client.DAGRunApi.GetDagRun(pcfg.AuthContext, dagId, dagRunId).Execute()
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("Dag Run `%s` of `%s` not found in Airflow", dagRunId, dagId)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Dag Run `%s` of `%s` from Airflow: %w", dagRunId, dagId, apiPermissionError(resp, err, "can_read on DAG Runs"))
+	}
+
+	state := string(dagRun.GetState())
+	d.SetId(fmt.Sprintf("%s:%s", dagId, dagRunId))
+	d.Set("state", state)
+	d.Set("finished", state == "success" || state == "failed")
+	d.Set("run_type", dagRun.GetRunType())
+	d.Set("logical_date", formatDagRunTime(dagRun.LogicalDate))
+	d.Set("start_date", formatDagRunTime(dagRun.StartDate))
+	d.Set("end_date", formatDagRunTime(dagRun.EndDate))
+	d.Set("duration", dagRunDuration(dagRun))
+	d.Set("ui_url", airflowUiUrl(m, "dag_run", dagId, dagRunId))
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDagStats() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceDagStatsRead,
+		Schema: map[string]*schema.Schema{
+			"dag_ids": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"dags": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dag_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"counts": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeInt},
+						},
+					},
+				},
+			},
+			"dags_by_id": keyedOutputSchema(),
+			"totals": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+// airflowDagStats is the response of the dagStats endpoint, which was added
+// in Airflow 2.7 and isn't covered by the generated client.
+type airflowDagStats struct {
+	Dags []struct {
+		DagId string `json:"dag_id"`
+		Stats []struct {
+			State string `json:"state"`
+			Count int    `json:"count"`
+		} `json:"stats"`
+	} `json:"dags"`
+}
+
+func dataSourceDagStatsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	var dagIds []string
+	for _, v := range d.Get("dag_ids").([]interface{}) {
+		dagIds = append(dagIds, v.(string))
+	}
+
+	var stats airflowDagStats
+	query := url.Values{"dag_ids": {strings.Join(dagIds, ",")}}
+	if _, err := apiRequest(pcfg, http.MethodGet, "/dagStats", query, nil, &stats); err != nil {
+		return fmt.Errorf("failed to get DAG stats from Airflow: %w", err)
+	}
+
+	byDagId := map[string]map[string]interface{}{}
+	totals := map[string]interface{}{}
+	for _, dag := range stats.Dags {
+		counts := map[string]interface{}{}
+		for _, s := range dag.Stats {
+			counts[s.State] = s.Count
+			total, _ := totals[s.State].(int)
+			totals[s.State] = total + s.Count
+		}
+		byDagId[dag.DagId] = counts
+	}
+
+	// Keep the order of dag_ids. DAGs that don't exist have no counts.
+	dags := make([]interface{}, 0, len(dagIds))
+	for _, dagId := range dagIds {
+		counts, ok := byDagId[dagId]
+		if !ok {
+			counts = map[string]interface{}{}
+		}
+		dags = append(dags, map[string]interface{}{
+			"dag_id": dagId,
+			"counts": counts,
+		})
+	}
+
+	d.SetId(strings.Join(dagIds, ","))
+	if err := d.Set("dags", dags); err != nil {
+		return fmt.Errorf("error setting dags: %w", err)
+	}
+	if err := setKeyedOutput(d, "dags_by_id", dags, "dag_id"); err != nil {
+		return err
+	}
+	d.Set("totals", totals)
+
+	return nil
+}
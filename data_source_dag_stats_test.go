@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceDagStats_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/dagStats", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("dag_ids"); got != "etl,report,missing" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected dag_ids "+got)
+			return
+		}
+		if _, _, ok := r.BasicAuth(); !ok {
+			writeFakeAirflowError(w, http.StatusUnauthorized, "missing credentials")
+			return
+		}
+
+		stats := func(failed, success int) []interface{} {
+			return []interface{}{
+				map[string]interface{}{"state": "failed", "count": failed},
+				map[string]interface{}{"state": "success", "count": success},
+			}
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dags": []interface{}{
+				map[string]interface{}{"dag_id": "report", "stats": stats(1, 10)},
+				map[string]interface{}{"dag_id": "etl", "stats": stats(3, 5)},
+			},
+			"total_entries": 2,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceDagStats().Schema, map[string]interface{}{
+		"dag_ids": []interface{}{"etl", "report", "missing"},
+	})
+	if err := dataSourceDagStatsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("dags.0.dag_id").(string); got != "etl" {
+		t.Fatalf("expected the order of dag_ids to be kept, got %q first", got)
+	}
+	if got := d.Get("dags.0.counts.failed").(int); got != 3 {
+		t.Fatalf("expected 3 failed etl runs, got %d", got)
+	}
+	if got := len(d.Get("dags.2.counts").(map[string]interface{})); got != 0 {
+		t.Fatalf("expected no counts for a missing DAG, got %d", got)
+	}
+	if got := d.Get("totals.failed").(int); got != 4 {
+		t.Fatalf("expected 4 failed runs in total, got %d", got)
+	}
+
+	var etl map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Get("dags_by_id.etl").(string)), &etl); err != nil {
+		t.Fatalf("invalid dags_by_id: %s", err)
+	}
+	if etl["counts"].(map[string]interface{})["failed"] != float64(3) {
+		t.Fatalf("unexpected dags_by_id entry %v", etl)
+	}
+}
+
+func TestDataSourceDagStats_fakeUnsupported(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourceDagStats().Schema, map[string]interface{}{
+		"dag_ids": []interface{}{"etl"},
+	})
+	if err := dataSourceDagStatsRead(d, m); err == nil {
+		t.Fatal("expected an error when the endpoint doesn't exist")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceDagVersion_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	versions := []interface{}{
+		map[string]interface{}{
+			"id":             "0195-b",
+			"version_number": 2,
+			"dag_id":         "example",
+			"bundle_name":    "dags-folder",
+			"bundle_version": "5f3e2a1",
+			"bundle_url":     "https://github.com/example/dags/tree/5f3e2a1",
+			"created_at":     "2025-04-22T10:00:00Z",
+		},
+		map[string]interface{}{
+			"id":             "0195-a",
+			"version_number": 1,
+			"dag_id":         "example",
+			"bundle_name":    "dags-folder",
+			"bundle_version": nil,
+			"created_at":     "2025-04-21T10:00:00Z",
+		},
+	}
+	fake.handle(http.MethodGet, "/api/v2/dags/example/dagVersions", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("order_by"); got != "-version_number" {
+			t.Errorf("expected versions ordered by version number descending, got %q", got)
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_versions": versions[:1], "total_entries": 2})
+	})
+	fake.handle(http.MethodGet, "/api/v2/dags/example/dagVersions/1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, versions[1])
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceDagVersion().Schema, map[string]interface{}{
+		"dag_id": "example",
+	})
+	if err := dataSourceDagVersionRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() != "example:2" {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+	if got := d.Get("bundle_version").(string); got != "5f3e2a1" {
+		t.Fatalf("unexpected bundle version %q", got)
+	}
+	if got := d.Get("bundle_name").(string); got != "dags-folder" {
+		t.Fatalf("unexpected bundle name %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceDagVersion().Schema, map[string]interface{}{
+		"dag_id":         "example",
+		"version_number": 1,
+	})
+	if err := dataSourceDagVersionRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("version_id").(string); got != "0195-a" {
+		t.Fatalf("unexpected version id %q", got)
+	}
+	if got := d.Get("bundle_version").(string); got != "" {
+		t.Fatalf("expected no bundle version, got %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceDagVersion().Schema, map[string]interface{}{
+		"dag_id": "missing",
+	})
+	if err := dataSourceDagVersionRead(d, m); err == nil {
+		t.Fatal("expected an error for a DAG without versions")
+	}
+}
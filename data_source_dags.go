@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// dataSourceDags lists DAGs. With retry, the list is read again while no DAG
+// matches, e.g. until an uploaded DAG is parsed.
+func dataSourceDags() *schema.Resource {
+	return withReadRetry(&schema.Resource{
+		Read: dataSourceDagsRead,
+		Schema: map[string]*schema.Schema{
+			"dag_id_pattern": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tags": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"only_active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"missing_tag_pattern": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"missing_owner_pattern": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"order_by": orderBySchema(),
+			"dag_ids": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"dags": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dag_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"is_paused": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"is_active": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"owners": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"fileloc": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"bundle_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"bundle_version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"dags_by_id": keyedOutputSchema(),
+		},
+	}, func(d *schema.ResourceData) bool {
+		return len(d.Get("dag_ids").([]interface{})) == 0
+	})
+}
+
+// airflowDagsPolicy matches the DAGs that violate a governance policy, i.e.
+// that have no tag or no owner matching the respective pattern.
+type airflowDagsPolicy struct {
+	tag   *regexp.Regexp
+	owner *regexp.Regexp
+}
+
+func (p airflowDagsPolicy) match(dag airflow.DAG) bool {
+	if p.tag == nil && p.owner == nil {
+		return true
+	}
+
+	if p.tag != nil && !anyMatch(p.tag, flattenAirflowDagTags(dag.Tags)) {
+		return true
+	}
+	if p.owner != nil && !anyMatch(p.owner, dag.GetOwners()) {
+		return true
+	}
+
+	return false
+}
+
+func anyMatch(re *regexp.Regexp, vs []string) bool {
+	for _, v := range vs {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func dataSourceDagsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	var policy airflowDagsPolicy
+	if v, ok := d.GetOk("missing_tag_pattern"); ok {
+		policy.tag = regexp.MustCompile(v.(string))
+	}
+	if v, ok := d.GetOk("missing_owner_pattern"); ok {
+		policy.owner = regexp.MustCompile(v.(string))
+	}
+
+	orderBy := d.Get("order_by").(string)
+	tags := expandStringSet(d.Get("tags").(*schema.Set))
+
+	// Airflow 3 only serves the DAG API as v2, which also returns the DAG
+	// bundles.
+	bundles := map[string]airflowDagBundle{}
+	fetch := func(limit, offset int32) ([]airflow.DAG, int32, error) {
+		req := client.DAGApi.GetDags(pcfg.AuthContext).Limit(limit).Offset(offset).OnlyActive(d.Get("only_active").(bool))
+		if v, ok := d.GetOk("dag_id_pattern"); ok {
+			req = req.DagIdPattern(v.(string))
+		}
+		if len(tags) > 0 {
+			req = req.Tags(tags)
+		}
+		if orderBy != "" {
+			req = req.OrderBy(orderBy)
+		}
+		page, _, err := req.Execute()
+		return page.GetDags(), page.GetTotalEntries(), err
+	}
+	if pcfg.isAirflow3() {
+		fetch = func(limit, offset int32) ([]airflow.DAG, int32, error) {
+			query := url.Values{
+				"limit":         {strconv.Itoa(int(limit))},
+				"offset":        {strconv.Itoa(int(offset))},
+				"exclude_stale": {strconv.FormatBool(d.Get("only_active").(bool))},
+				"tags":          tags,
+			}
+			if v, ok := d.GetOk("dag_id_pattern"); ok {
+				query.Set("dag_id_pattern", v.(string))
+			}
+			if orderBy != "" {
+				query.Set("order_by", orderBy)
+			}
+			return listAirflowDagsV2(pcfg, query, bundles)
+		}
+	}
+
+	var matches []airflow.DAG
+	key := func(dag airflow.DAG) string { return dag.GetDagId() }
+	err := forEachPage("dags", key, fetch, func(dag airflow.DAG) bool {
+		if policy.match(dag) {
+			matches = append(matches, dag)
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list DAGs from Airflow: %w", err)
+	}
+
+	if orderBy == "" {
+		sort.Slice(matches, func(i, j int) bool { return matches[i].GetDagId() < matches[j].GetDagId() })
+	}
+
+	dagIds := make([]string, 0, len(matches))
+	dags := make([]interface{}, 0, len(matches))
+	for _, dag := range matches {
+		dagIds = append(dagIds, dag.GetDagId())
+		dags = append(dags, map[string]interface{}{
+			"dag_id":    dag.GetDagId(),
+			"is_paused": dag.GetIsPaused(),
+			"is_active": dag.GetIsActive(),
+			"owners":    dag.GetOwners(),
+			"tags":      flattenAirflowDagTags(dag.Tags),
+			"fileloc":   dag.GetFileloc(),
+
+			"bundle_name":    bundles[dag.GetDagId()].BundleName,
+			"bundle_version": bundles[dag.GetDagId()].BundleVersion,
+		})
+	}
+
+	d.SetId("dags")
+	d.Set("dag_ids", dagIds)
+	if err := d.Set("dags", dags); err != nil {
+		return fmt.Errorf("error setting dags: %w", err)
+	}
+	if err := setKeyedOutput(d, "dags_by_id", dags, "dag_id"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceDags_fakePolicy(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	dag := func(id string, owners []interface{}, tags ...string) map[string]interface{} {
+		tagObjects := []interface{}{}
+		for _, tag := range tags {
+			tagObjects = append(tagObjects, map[string]interface{}{"name": tag})
+		}
+		return map[string]interface{}{"dag_id": id, "owners": owners, "tags": tagObjects, "is_active": true}
+	}
+	fake.handle(http.MethodGet, "/dags", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("only_active"); got != "true" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected only_active "+got)
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dags": []interface{}{
+				dag("untagged", []interface{}{"data-eng"}),
+				dag("tagged", []interface{}{"data-eng"}, "etl", "team:data"),
+				dag("airflow_owned", []interface{}{"airflow"}, "team:ml"),
+			},
+			"total_entries": 3,
+		})
+	})
+
+	cases := map[string]struct {
+		raw  map[string]interface{}
+		want []interface{}
+	}{
+		"all": {
+			raw:  map[string]interface{}{},
+			want: []interface{}{"airflow_owned", "tagged", "untagged"},
+		},
+		"missing team tag": {
+			raw:  map[string]interface{}{"missing_tag_pattern": "^team:"},
+			want: []interface{}{"untagged"},
+		},
+		"missing team tag or owner": {
+			raw:  map[string]interface{}{"missing_tag_pattern": "^team:", "missing_owner_pattern": "-eng$"},
+			want: []interface{}{"airflow_owned", "untagged"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceDags().Schema, c.raw)
+			if err := dataSourceDagsRead(d, m); err != nil {
+				t.Fatalf("read: %s", err)
+			}
+			if got := d.Get("dag_ids").([]interface{}); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("expected %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestDataSourceDags_fakeBundles(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.playAirflow3("3.0.2")
+	fake.handle(http.MethodGet, "/api/v2/dags", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("dag_id_pattern"); got != "%" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected dag_id_pattern "+got)
+			return
+		}
+		if got := r.URL.Query().Get("exclude_stale"); got != "true" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected exclude_stale "+got)
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dags": []interface{}{
+				map[string]interface{}{"dag_id": "a", "is_stale": false, "bundle_name": "dags-repo", "bundle_version": "4f1c2ab"},
+				map[string]interface{}{"dag_id": "b", "is_stale": true, "bundle_name": "dags-folder", "bundle_version": nil},
+			},
+			"total_entries": 2,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceDags().Schema, map[string]interface{}{"dag_id_pattern": "%"})
+	if err := dataSourceDagsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("dags.0.bundle_version").(string); got != "4f1c2ab" {
+		t.Fatalf("unexpected bundle version %q", got)
+	}
+	if got := d.Get("dags.1.bundle_name").(string); got != "dags-folder" {
+		t.Fatalf("unexpected bundle name %q", got)
+	}
+	if !d.Get("dags.0.is_active").(bool) || d.Get("dags.1.is_active").(bool) {
+		t.Fatalf("expected only the DAG that isn't stale to be active, got %v", d.Get("dags"))
+	}
+}
+
+func TestDataSourceDags_fakeRetry(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	// The DAG is only parsed by the third read.
+	calls := 0
+	fake.handle(http.MethodGet, "/dags", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		dags := []interface{}{}
+		if calls >= 3 {
+			dags = append(dags, map[string]interface{}{"dag_id": "uploaded", "is_active": true})
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dags": dags, "total_entries": len(dags)})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceDags().Schema, map[string]interface{}{
+		"dag_id_pattern": "uploaded",
+		"retry": []interface{}{
+			map[string]interface{}{"attempts": 5, "wait": "1ms"},
+		},
+	})
+	if err := dataSourceDags().Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("dag_ids").([]interface{}); !reflect.DeepEqual(got, []interface{}{"uploaded"}) {
+		t.Fatalf("expected the DAG to be polled for, got %v", got)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 reads, got %d", calls)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// airflowEventObjectTypes are the kinds of objects whose changes are looked up
+// in the event log. Airflow names the events of the UI and the API after them,
+// e.g. `variable.edit` or `post_connection`.
+var airflowEventObjectTypes = []string{"connection", "user", "variable"}
+
+func dataSourceLastEvent() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceLastEventRead,
+		Schema: map[string]*schema.Schema{
+			"object_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(airflowEventObjectTypes, false),
+			},
+			"object_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"exclude_owners": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"max_entries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1000,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"found": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"event_log_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"when": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"event": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"owner": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"extra": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceLastEventRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	objectType := d.Get("object_type").(string)
+	objectId := d.Get("object_id").(string)
+	maxEntries := d.Get("max_entries").(int)
+	excludeOwners := map[string]bool{}
+	for _, owner := range expandStringSet(d.Get("exclude_owners").(*schema.Set)) {
+		excludeOwners[owner] = true
+	}
+
+	var match *airflow.EventLog
+	scanned := 0
+	key := func(event airflow.EventLog) string { return strconv.Itoa(int(event.GetEventLogId())) }
+	err := forEachPage("event logs", key, func(limit, offset int32) ([]airflow.EventLog, int32, error) {
+		page, _, err := client.EventLogApi.GetEventLogs(pcfg.AuthContext).Limit(limit).Offset(offset).OrderBy("-event_log_id").Execute()
+		return page.GetEventLogs(), page.GetTotalEntries(), err
+	}, func(event airflow.EventLog) bool {
+		scanned++
+		if !excludeOwners[event.GetOwner()] && isAirflowObjectEvent(event, objectType, objectId) {
+			match = &event
+			return false
+		}
+		return scanned < maxEntries
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get the last event of %s `%s` from Airflow: %w", objectType, objectId, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s:%s", objectType, objectId))
+	d.Set("found", match != nil)
+	if match == nil {
+		d.Set("event_log_id", 0)
+		d.Set("when", "")
+		d.Set("event", "")
+		d.Set("owner", "")
+		d.Set("extra", "")
+		return nil
+	}
+
+	when := ""
+	if match.When != nil {
+		when = match.When.UTC().Format(time.RFC3339)
+	}
+	d.Set("event_log_id", match.GetEventLogId())
+	d.Set("when", when)
+	d.Set("event", match.GetEvent())
+	d.Set("owner", match.GetOwner())
+	d.Set("extra", match.GetExtra())
+
+	return nil
+}
+
+// isAirflowObjectEvent returns whether an event log entry records a change of
+// the given object. Airflow doesn't record the object of an event in a
+// dedicated field, so the event name must mention the object type and the
+// extra, which holds the request arguments, the object ID.
+func isAirflowObjectEvent(event airflow.EventLog, objectType, objectId string) bool {
+	name := strings.ToLower(event.GetEvent())
+	if !strings.Contains(name, objectType) {
+		return false
+	}
+	// Reading an object is logged as well, but doesn't change it.
+	for _, read := range []string{"get_", ".list", ".show", "list_"} {
+		if strings.Contains(name, read) {
+			return false
+		}
+	}
+
+	return strings.Contains(event.GetExtra(), objectId)
+}
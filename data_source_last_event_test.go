@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceLastEvent_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/eventLogs", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("order_by"); got != "-event_log_id" {
+			t.Errorf("expected event logs ordered by -event_log_id, got %q", got)
+		}
+		events := []interface{}{
+			map[string]interface{}{"event_log_id": 5, "event": "variable.edit", "owner": "terraform", "extra": "[('key', 'foo')]", "when": "2022-05-03T00:00:00+00:00"},
+			map[string]interface{}{"event_log_id": 4, "event": "get_variable", "owner": "alice", "extra": "{\"variable_key\": \"foo\"}", "when": "2022-05-02T12:00:00+00:00"},
+			map[string]interface{}{"event_log_id": 3, "event": "variable.edit", "owner": "alice", "extra": "[('key', 'foo')]", "when": "2022-05-02T00:00:00+00:00"},
+			map[string]interface{}{"event_log_id": 2, "event": "connection.edit", "owner": "bob", "extra": "[('conn_id', 'foo')]", "when": "2022-05-01T00:00:00+00:00"},
+		}
+		if r.URL.Query().Get("offset") != "0" {
+			events = nil
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"event_logs": events, "total_entries": 4})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceLastEvent().Schema, map[string]interface{}{
+		"object_type":    "variable",
+		"object_id":      "foo",
+		"exclude_owners": []interface{}{"terraform"},
+	})
+	if err := dataSourceLastEventRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if !d.Get("found").(bool) {
+		t.Fatal("expected an event to be found")
+	}
+	if got := d.Get("event_log_id").(int); got != 3 {
+		t.Fatalf("expected the edit by alice to be found, got event %d", got)
+	}
+	if got := d.Get("owner").(string); got != "alice" {
+		t.Fatalf("expected owner alice, got %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceLastEvent().Schema, map[string]interface{}{
+		"object_type": "user",
+		"object_id":   "foo",
+	})
+	if err := dataSourceLastEventRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Get("found").(bool) {
+		t.Fatalf("expected no event to be found, got %d", d.Get("event_log_id").(int))
+	}
+}
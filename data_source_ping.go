@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// pingProbe performs the cheapest read call that requires the same RBAC
+// permission as reading the given resource type.
+type pingProbe func(pcfg ProviderConfig) (*http.Response, error)
+
+var pingProbes = map[string]pingProbe{
+	"airflow_connection": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.ConnectionApi.GetConnections(pcfg.AuthContext).Limit(1).Execute()
+		return resp, err
+	},
+	"airflow_dag": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.DAGApi.GetDags(pcfg.AuthContext).Limit(1).Execute()
+		return resp, err
+	},
+	"airflow_dag_run": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.DAGRunApi.GetDagRuns(pcfg.AuthContext, "~").Limit(1).Execute()
+		return resp, err
+	},
+	"airflow_pool": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.PoolApi.GetPools(pcfg.AuthContext).Limit(1).Execute()
+		return resp, err
+	},
+	"airflow_role": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.RoleApi.GetRoles(pcfg.AuthContext).Limit(1).Execute()
+		return resp, err
+	},
+	"airflow_user": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.UserApi.GetUsers(pcfg.AuthContext).Limit(1).Execute()
+		return resp, err
+	},
+	"airflow_variable": func(pcfg ProviderConfig) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.VariableApi.GetVariables(pcfg.AuthContext).Limit(1).Execute()
+		return resp, err
+	},
+}
+
+// pingWriteProbes update or create an object of the given resource type that
+// doesn't exist, named by pingProbeName. Airflow checks the permission to
+// write before looking the object up, so a 404, or a 400 of a rejected body,
+// means the write is allowed without anything being changed.
+var pingWriteProbes = map[string]func(pcfg ProviderConfig, name string) (*http.Response, error){
+	"airflow_connection": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		connType := "http"
+		_, resp, err := pcfg.ApiClient.ConnectionApi.PatchConnection(pcfg.AuthContext, name).Connection(airflow.Connection{ConnectionId: &name, ConnType: &connType}).Execute()
+		return resp, err
+	},
+	"airflow_dag": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		dag := *airflow.NewDAG()
+		dag.SetIsPaused(true)
+		_, resp, err := pcfg.ApiClient.DAGApi.PatchDag(pcfg.AuthContext, name).DAG(dag).UpdateMask([]string{"is_paused"}).Execute()
+		return resp, err
+	},
+	"airflow_dag_run": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.DAGRunApi.PostDagRun(pcfg.AuthContext, name).DAGRun(airflow.DAGRun{}).Execute()
+		return resp, err
+	},
+	"airflow_pool": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		slots := int32(1)
+		_, resp, err := pcfg.ApiClient.PoolApi.PatchPool(pcfg.AuthContext, name).Pool(airflow.Pool{Name: &name, Slots: &slots}).Execute()
+		return resp, err
+	},
+	"airflow_role": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.RoleApi.PatchRole(pcfg.AuthContext, name).Role(airflow.Role{Name: &name}).Execute()
+		return resp, err
+	},
+	"airflow_user": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		_, resp, err := pcfg.ApiClient.UserApi.PatchUser(pcfg.AuthContext, name).User(airflow.User{Username: &name}).UpdateMask([]string{"first_name"}).Execute()
+		return resp, err
+	},
+	"airflow_variable": func(pcfg ProviderConfig, name string) (*http.Response, error) {
+		value := ""
+		_, resp, err := pcfg.ApiClient.VariableApi.PatchVariable(pcfg.AuthContext, name).Variable(airflow.Variable{Key: &name, Value: &value}).Execute()
+		return resp, err
+	},
+}
+
+// pingWriteAllowed interprets the result of a write probe. The error is set
+// when the write isn't allowed.
+func pingWriteAllowed(resp *http.Response, err error) (bool, error) {
+	if resp == nil {
+		if err == nil {
+			err = fmt.Errorf("no response")
+		}
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusBadRequest:
+		return true, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false, err
+	}
+	if resp.StatusCode >= 300 && err == nil {
+		err = fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return err == nil, err
+}
+
+// pingProbeName returns a name no object is expected to have, for a write
+// probe to miss.
+func pingProbeName() (string, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate probe name: %w", err)
+	}
+	return "terraform-provider-airflow-ping-" + id, nil
+}
+
+func pingResourceTypes() []string {
+	types := make([]string, 0, len(pingProbes))
+	for k := range pingProbes {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// With retry, Airflow is polled until the checks pass, e.g. while it starts.
+func dataSourcePing() *schema.Resource {
+	return withReadRetry(&schema.Resource{
+		Read: dataSourcePingRead,
+		Schema: map[string]*schema.Schema{
+			"resource_types": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(pingResourceTypes(), false),
+				},
+			},
+			"check_write": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"ok": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"reachable": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"authenticated": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"git_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"metadatabase_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"scheduler_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"permissions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"resource_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"allowed": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"status_code": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"error": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"write_allowed": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"write_status_code": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"write_error": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"errors": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}, func(d *schema.ResourceData) bool {
+		return !d.Get("ok").(bool)
+	})
+}
+
+func dataSourcePingRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	var errs []string
+	reachable := false
+
+	health, resp, err := client.MonitoringApi.GetHealth(pcfg.AuthContext).Execute()
+	if resp != nil {
+		reachable = true
+	}
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("health: %s", err))
+	} else {
+		d.Set("metadatabase_status", string(health.Metadatabase.GetStatus()))
+		d.Set("scheduler_status", string(health.Scheduler.GetStatus()))
+	}
+
+	version, resp, err := getAirflowVersionInfo(pcfg)
+	if resp != nil {
+		reachable = true
+	}
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("version: %s", err))
+	} else {
+		d.Set("version", version.GetVersion())
+		d.Set("git_version", version.GetGitVersion())
+	}
+
+	resourceTypes := pingResourceTypes()
+	if v, ok := d.GetOk("resource_types"); ok {
+		resourceTypes = nil
+		for _, rt := range v.([]interface{}) {
+			resourceTypes = append(resourceTypes, rt.(string))
+		}
+	}
+
+	probeName := ""
+	checkWrite := d.Get("check_write").(bool)
+	if checkWrite {
+		if probeName, err = pingProbeName(); err != nil {
+			return err
+		}
+	}
+
+	authenticated := false
+	allAllowed := true
+	permissions := make([]interface{}, 0, len(resourceTypes))
+	for _, rt := range resourceTypes {
+		resp, err := pingProbes[rt](pcfg)
+
+		permission := map[string]interface{}{
+			"resource_type":     rt,
+			"allowed":           err == nil,
+			"status_code":       0,
+			"error":             "",
+			"write_allowed":     false,
+			"write_status_code": 0,
+			"write_error":       "",
+		}
+		if resp != nil {
+			reachable = true
+			permission["status_code"] = resp.StatusCode
+			if resp.StatusCode != http.StatusUnauthorized {
+				authenticated = true
+			}
+		}
+		if err != nil {
+			allAllowed = false
+			permission["error"] = err.Error()
+			errs = append(errs, fmt.Sprintf("%s: %s", rt, err))
+		}
+
+		if checkWrite {
+			resp, err := pingWriteProbes[rt](pcfg, probeName)
+			allowed, err := pingWriteAllowed(resp, err)
+			permission["write_allowed"] = allowed
+			if resp != nil {
+				permission["write_status_code"] = resp.StatusCode
+			}
+			if !allowed {
+				allAllowed = false
+				permission["write_error"] = err.Error()
+				errs = append(errs, fmt.Sprintf("%s (write): %s", rt, err))
+			}
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	d.SetId("ping")
+	d.Set("reachable", reachable)
+	d.Set("authenticated", authenticated)
+	d.Set("ok", reachable && authenticated && allAllowed && len(errs) == 0)
+	d.Set("errors", errs)
+	if err := d.Set("permissions", permissions); err != nil {
+		return fmt.Errorf("error setting permissions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccAirflowPingDataSource_basic(t *testing.T) {
+	dataSourceName := "data.airflow_ping.test"
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAirflowPingDataSourceConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataSourceName, "ok", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "reachable", "true"),
+					resource.TestCheckResourceAttr(dataSourceName, "authenticated", "true"),
+					resource.TestCheckResourceAttrSet(dataSourceName, "version"),
+					resource.TestCheckResourceAttr(dataSourceName, "permissions.#", "2"),
+					resource.TestCheckResourceAttr(dataSourceName, "permissions.0.allowed", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourcePing_fakePermissionDenied(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"metadatabase": map[string]interface{}{"status": "healthy"},
+			"scheduler":    map[string]interface{}{"status": "healthy"},
+		})
+	})
+	fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": "2.9.3"})
+	})
+	fake.failNext(http.MethodGet, "/users", http.StatusForbidden, 1)
+
+	d := schema.TestResourceDataRaw(t, dataSourcePing().Schema, map[string]interface{}{
+		"resource_types": []interface{}{"airflow_user", "airflow_variable"},
+	})
+
+	if err := dataSourcePingRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if !d.Get("reachable").(bool) || !d.Get("authenticated").(bool) {
+		t.Fatal("expected the fake Airflow to be reachable and authenticated")
+	}
+	if d.Get("ok").(bool) {
+		t.Fatal("expected ok to be false when a permission is missing")
+	}
+	if got := d.Get("version").(string); got != "2.9.3" {
+		t.Fatalf("expected version 2.9.3, got %q", got)
+	}
+	if d.Get("permissions.0.allowed").(bool) {
+		t.Fatal("expected airflow_user permission to be denied")
+	}
+	if got := d.Get("permissions.0.status_code").(int); got != http.StatusForbidden {
+		t.Fatalf("expected status code 403, got %d", got)
+	}
+	if !d.Get("permissions.1.allowed").(bool) {
+		t.Fatal("expected airflow_variable permission to be allowed")
+	}
+}
+
+func TestDataSourcePing_fakeRetry(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"metadatabase": map[string]interface{}{"status": "healthy"},
+			"scheduler":    map[string]interface{}{"status": "healthy"},
+		})
+	})
+	fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": "2.9.3"})
+	})
+	// The permission is only granted by the time of the second read.
+	fake.failNext(http.MethodGet, "/users", http.StatusForbidden, 1)
+
+	d := schema.TestResourceDataRaw(t, dataSourcePing().Schema, map[string]interface{}{
+		"resource_types": []interface{}{"airflow_user"},
+		"retry": []interface{}{
+			map[string]interface{}{"attempts": 3, "wait": "1ms"},
+		},
+	})
+	if err := dataSourcePing().Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if !d.Get("ok").(bool) {
+		t.Fatal("expected Airflow to be polled until the checks pass")
+	}
+	if got := fake.requestCount(http.MethodGet, "/users"); got != 2 {
+		t.Fatalf("expected 2 reads, got %d", got)
+	}
+}
+
+func TestDataSourcePing_fakeWriteDenied(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0})
+
+	fake.handle(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"metadatabase": map[string]interface{}{"status": "healthy"},
+			"scheduler":    map[string]interface{}{"status": "healthy"},
+		})
+	})
+	fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": "2.9.3"})
+	})
+	// A read-only credential may list variables but not edit them.
+	fake.failNext(http.MethodPatch, "/variables", http.StatusForbidden, 1)
+
+	d := schema.TestResourceDataRaw(t, dataSourcePing().Schema, map[string]interface{}{
+		"resource_types": []interface{}{"airflow_variable", "airflow_pool"},
+	})
+	if err := dataSourcePingRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("ok").(bool) {
+		t.Fatal("expected ok to be false when a write permission is missing")
+	}
+	if !d.Get("permissions.0.allowed").(bool) || d.Get("permissions.0.write_allowed").(bool) {
+		t.Fatalf("expected only reading variables to be allowed, got %v", d.Get("permissions.0"))
+	}
+	if got := d.Get("permissions.0.write_status_code").(int); got != http.StatusForbidden {
+		t.Fatalf("expected write status code 403, got %d", got)
+	}
+	// The probe of the pool misses, which means the write is allowed.
+	if !d.Get("permissions.1.write_allowed").(bool) || d.Get("permissions.1.write_status_code").(int) != http.StatusNotFound {
+		t.Fatalf("expected writing pools to be allowed, got %v", d.Get("permissions.1"))
+	}
+	if got := d.Get("errors").([]interface{}); len(got) != 1 {
+		t.Fatalf("expected a single error, got %v", got)
+	}
+
+	// Without check_write only reading is checked.
+	d = schema.TestResourceDataRaw(t, dataSourcePing().Schema, map[string]interface{}{
+		"resource_types": []interface{}{"airflow_variable"},
+		"check_write":    false,
+	})
+	fake.failNext(http.MethodPatch, "/variables", http.StatusForbidden, 1)
+	if err := dataSourcePingRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if !d.Get("ok").(bool) {
+		t.Fatalf("expected ok without write checks, got errors %v", d.Get("errors"))
+	}
+	if got := fake.requestCount(http.MethodPatch, "/variables"); got != 1 {
+		t.Fatalf("expected no write probe without check_write, got %d", got)
+	}
+}
+
+func testAccAirflowPingDataSourceConfig() string {
+	return `
+data "airflow_ping" "test" {
+  resource_types = ["airflow_user", "airflow_variable"]
+}
+`
+}
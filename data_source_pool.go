@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePool() *schema.Resource {
+	s := airflowPoolDataSchema()
+	s["name"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	s["ui_url"] = uiUrlSchema()
+
+	return &schema.Resource{
+		Read:   dataSourcePoolRead,
+		Schema: s,
+	}
+}
+
+// airflowPoolDataSchema are the computed attributes of a pool exported by the
+// pool data sources.
+func airflowPoolDataSchema() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"description": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+	for _, k := range []string{"slots", "occupied_slots", "queued_slots", "open_slots", "used_slots"} {
+		s[k] = &schema.Schema{
+			Type:     schema.TypeInt,
+			Computed: true,
+		}
+	}
+	return s
+}
+
+func dataSourcePoolRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	name := d.Get("name").(string)
+	pool, resp, err := getWithListFallback(pcfg, "pool", name, client.PoolApi.GetPool(pcfg.AuthContext, name).Execute,
+		func(fn func(airflow.Pool) bool) error {
+			_, err := listAirflowPools(pcfg, fn)
+			return err
+		}, func(p airflow.Pool) string { return p.GetName() })
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("pool `%s` not found in Airflow", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get pool `%s` from Airflow: %w", name, apiPermissionError(resp, err, "can_read on Pools"))
+	}
+
+	d.SetId(pool.GetName())
+	for k, v := range flattenAirflowPoolData(pool) {
+		d.Set(k, v)
+	}
+	d.Set("ui_url", airflowUiUrl(m, "pool", pool.GetName()))
+
+	return nil
+}
+
+func flattenAirflowPoolData(pool airflow.Pool) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           pool.GetName(),
+		"description":    pool.GetDescription(),
+		"slots":          pool.GetSlots(),
+		"occupied_slots": pool.GetOccupiedSlots(),
+		"queued_slots":   pool.GetQueuedSlots(),
+		"open_slots":     pool.GetOpenSlots(),
+		"used_slots":     pool.GetUsedSlots(),
+	}
+}
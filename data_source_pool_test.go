@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourcePool_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("pools", map[string]interface{}{"name": "etl", "slots": 8, "open_slots": 5, "description": "ETL jobs"})
+
+	d := schema.TestResourceDataRaw(t, dataSourcePool().Schema, map[string]interface{}{
+		"name": "etl",
+	})
+	if err := dataSourcePoolRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("slots").(int) != 8 || d.Get("open_slots").(int) != 5 {
+		t.Fatalf("unexpected slots %v %v", d.Get("slots"), d.Get("open_slots"))
+	}
+	if got := d.Get("description").(string); got != "ETL jobs" {
+		t.Fatalf("unexpected description %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourcePool().Schema, map[string]interface{}{
+		"name": "missing",
+	})
+	if err := dataSourcePoolRead(d, m); err == nil {
+		t.Fatal("expected an error for a missing pool")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourcePools() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePoolsRead,
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"pools": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: airflowPoolDataSchema(),
+				},
+			},
+			"pools_by_name": keyedOutputSchema(),
+		},
+	}
+}
+
+func dataSourcePoolsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	prefix := d.Get("name_prefix").(string)
+	var matches []airflow.Pool
+	_, err := listAirflowPools(pcfg, func(p airflow.Pool) bool {
+		if strings.HasPrefix(p.GetName(), prefix) {
+			matches = append(matches, p)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].GetName() < matches[j].GetName() })
+
+	names := make([]string, 0, len(matches))
+	pools := make([]interface{}, 0, len(matches))
+	for _, p := range matches {
+		names = append(names, p.GetName())
+		pools = append(pools, flattenAirflowPoolData(p))
+	}
+
+	d.SetId("pools")
+	d.Set("names", names)
+	if err := d.Set("pools", pools); err != nil {
+		return fmt.Errorf("error setting pools: %w", err)
+	}
+	if err := setKeyedOutput(d, "pools_by_name", pools, "name"); err != nil {
+		return err
+	}
+
+	return nil
+}
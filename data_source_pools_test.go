@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourcePools_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("pools", map[string]interface{}{"name": "default_pool", "slots": 128})
+	fake.seed("pools", map[string]interface{}{"name": "etl", "slots": 8})
+
+	d := schema.TestResourceDataRaw(t, dataSourcePools().Schema, map[string]interface{}{})
+	if err := dataSourcePoolsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("names").([]interface{}); !reflect.DeepEqual(got, []interface{}{"default_pool", "etl"}) {
+		t.Fatalf("unexpected names %v", got)
+	}
+	if got := d.Get("pools.1.slots").(int); got != 8 {
+		t.Fatalf("unexpected slots %d", got)
+	}
+	if _, ok := d.Get("pools_by_name").(map[string]interface{})["etl"]; !ok {
+		t.Fatal("expected etl in pools_by_name")
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceProviderConnectionTypes() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceProviderConnectionTypesRead,
+		Schema: map[string]*schema.Schema{
+			"providers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"package_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"connection_types_available": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"connection_types": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"connection_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hook_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hook_class_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"default_conn_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"standard_fields": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"hidden": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"label": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"placeholder": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"extra_fields": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"label": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"type": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"description": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"default_json": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"schema_json": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"connection_types_by_type": keyedOutputSchema(),
+		},
+	}
+}
+
+// airflowHookMeta is an entry of the connection form metadata the Airflow 3
+// UI is built from. Airflow 2 renders the connection form on the server and
+// has no endpoint for it.
+type airflowHookMeta struct {
+	ConnectionType  *string `json:"connection_type"`
+	HookName        *string `json:"hook_name"`
+	HookClassName   *string `json:"hook_class_name"`
+	DefaultConnName *string `json:"default_conn_name"`
+	StandardFields  map[string]*struct {
+		Hidden      bool    `json:"hidden"`
+		Label       *string `json:"label"`
+		Placeholder *string `json:"placeholder"`
+	} `json:"standard_fields"`
+	ExtraFields map[string]struct {
+		Value       json.RawMessage        `json:"value"`
+		Schema      map[string]interface{} `json:"schema"`
+		Description *string                `json:"description"`
+	} `json:"extra_fields"`
+}
+
+func dataSourceProviderConnectionTypesRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	// Airflow 3 only serves the provider packages with the v2 API, along
+	// with the metadata of the connection types of its UI.
+	airflow3 := pcfg.isAirflow3()
+
+	var providers []airflow.Provider
+	var err error
+	if airflow3 {
+		key := func(p airflow.Provider) string { return p.GetPackageName() }
+		providers, err = fetchAllPages("providers", key, func(limit, offset int32) ([]airflow.Provider, int32, error) {
+			query := url.Values{
+				"limit":  {strconv.Itoa(int(limit))},
+				"offset": {strconv.Itoa(int(offset))},
+			}
+			var page struct {
+				Providers    []airflow.Provider `json:"providers"`
+				TotalEntries int32              `json:"total_entries"`
+			}
+			_, err := uiRequest(pcfg, http.MethodGet, "/api/v2/providers", query, &page)
+			return page.Providers, page.TotalEntries, err
+		})
+	} else {
+		var collection airflow.ProviderCollection
+		collection, _, err = client.ProviderApi.GetProviders(pcfg.AuthContext).Execute()
+		providers = collection.GetProviders()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the provider packages from Airflow: %w", err)
+	}
+
+	packages := make([]interface{}, 0, len(providers))
+	for _, p := range providers {
+		packages = append(packages, map[string]interface{}{
+			"package_name": p.GetPackageName(),
+			"version":      p.GetVersion(),
+			"description":  p.GetDescription(),
+		})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].(map[string]interface{})["package_name"].(string) < packages[j].(map[string]interface{})["package_name"].(string)
+	})
+
+	var hooks []airflowHookMeta
+	available := false
+	if airflow3 {
+		resp, err := uiRequest(pcfg, http.MethodGet, "/ui/connections/hook_meta", nil, &hooks)
+		switch {
+		case err == nil:
+			available = true
+		case resp != nil && resp.StatusCode == http.StatusNotFound:
+			log.Printf("[INFO] Airflow doesn't serve the metadata of connection types, only the provider packages are listed")
+		default:
+			return fmt.Errorf("failed to get the connection types from Airflow: %w", err)
+		}
+	}
+
+	connectionTypes := make([]interface{}, 0, len(hooks))
+	for _, h := range hooks {
+		if h.ConnectionType == nil {
+			continue
+		}
+		connectionTypes = append(connectionTypes, flattenAirflowHookMeta(h))
+	}
+	sort.Slice(connectionTypes, func(i, j int) bool {
+		return connectionTypes[i].(map[string]interface{})["connection_type"].(string) < connectionTypes[j].(map[string]interface{})["connection_type"].(string)
+	})
+
+	d.SetId("provider-connection-types")
+	if err := d.Set("providers", packages); err != nil {
+		return fmt.Errorf("error setting providers: %w", err)
+	}
+	d.Set("connection_types_available", available)
+	if err := d.Set("connection_types", connectionTypes); err != nil {
+		return fmt.Errorf("error setting connection_types: %w", err)
+	}
+	if err := setKeyedOutput(d, "connection_types_by_type", connectionTypes, "connection_type"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func flattenAirflowHookMeta(h airflowHookMeta) map[string]interface{} {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	standardNames := make([]string, 0, len(h.StandardFields))
+	for name := range h.StandardFields {
+		standardNames = append(standardNames, name)
+	}
+	sort.Strings(standardNames)
+
+	standardFields := make([]interface{}, 0, len(standardNames))
+	for _, name := range standardNames {
+		f := h.StandardFields[name]
+		if f == nil {
+			continue
+		}
+		standardFields = append(standardFields, map[string]interface{}{
+			"name":        name,
+			"hidden":      f.Hidden,
+			"label":       str(f.Label),
+			"placeholder": str(f.Placeholder),
+		})
+	}
+
+	extraNames := make([]string, 0, len(h.ExtraFields))
+	for name := range h.ExtraFields {
+		extraNames = append(extraNames, name)
+	}
+	sort.Strings(extraNames)
+
+	extraFields := make([]interface{}, 0, len(extraNames))
+	for _, name := range extraNames {
+		f := h.ExtraFields[name]
+
+		label, _ := f.Schema["title"].(string)
+		defaultJson := ""
+		if len(f.Value) > 0 && string(f.Value) != "null" {
+			defaultJson = string(f.Value)
+		}
+		schemaJson := ""
+		if len(f.Schema) > 0 {
+			b, err := json.Marshal(f.Schema)
+			if err == nil {
+				schemaJson = string(b)
+			}
+		}
+
+		extraFields = append(extraFields, map[string]interface{}{
+			"name":         name,
+			"label":        label,
+			"type":         jsonSchemaType(f.Schema["type"]),
+			"description":  str(f.Description),
+			"default_json": defaultJson,
+			"schema_json":  schemaJson,
+		})
+	}
+
+	return map[string]interface{}{
+		"connection_type":   str(h.ConnectionType),
+		"hook_name":         str(h.HookName),
+		"hook_class_name":   str(h.HookClassName),
+		"default_conn_name": str(h.DefaultConnName),
+		"standard_fields":   standardFields,
+		"extra_fields":      extraFields,
+	}
+}
+
+// jsonSchemaType returns the JSON schema type of an extra field. Optional
+// fields are typed like ["string", "null"], of which the first type other
+// than null is returned.
+func jsonSchemaType(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, e := range t {
+			if s, ok := e.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// fakeAirflowProviders serves the provider packages with the stable and the
+// v2 API.
+func fakeAirflowProviders(fake *fakeAirflow) {
+	for _, path := range []string{"/providers", "/api/v2/providers"} {
+		fake.handle(http.MethodGet, path, func(w http.ResponseWriter, r *http.Request) {
+			writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+				"providers": []interface{}{
+					map[string]interface{}{"package_name": "apache-airflow-providers-snowflake", "version": "5.0.0", "description": "Snowflake"},
+					map[string]interface{}{"package_name": "apache-airflow-providers-amazon", "version": "8.10.0", "description": "Amazon"},
+				},
+				"total_entries": 2,
+			})
+		})
+	}
+}
+
+func TestDataSourceProviderConnectionTypes_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.playAirflow3("3.0.2")
+	fakeAirflowProviders(fake)
+
+	fake.handle(http.MethodGet, "/ui/connections/hook_meta", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, []interface{}{
+			map[string]interface{}{
+				"connection_type":   "snowflake",
+				"hook_name":         "Snowflake",
+				"hook_class_name":   "airflow.providers.snowflake.hooks.snowflake.SnowflakeHook",
+				"default_conn_name": "snowflake_default",
+				"standard_fields": map[string]interface{}{
+					"port":  map[string]interface{}{"hidden": true, "label": nil, "placeholder": nil},
+					"login": map[string]interface{}{"hidden": false, "label": "Login", "placeholder": "user"},
+				},
+				"extra_fields": map[string]interface{}{
+					"warehouse": map[string]interface{}{
+						"value":       "compute_wh",
+						"schema":      map[string]interface{}{"type": []interface{}{"string", "null"}, "title": "Warehouse"},
+						"description": nil,
+					},
+				},
+			},
+			map[string]interface{}{
+				"connection_type": "aws",
+				"hook_name":       "Amazon Web Services",
+			},
+			map[string]interface{}{
+				"connection_type": nil,
+				"hook_name":       "Unknown",
+			},
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceProviderConnectionTypes().Schema, map[string]interface{}{})
+	if err := dataSourceProviderConnectionTypesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("providers.0.package_name").(string); got != "apache-airflow-providers-amazon" {
+		t.Fatalf("unexpected first provider %q", got)
+	}
+	if !d.Get("connection_types_available").(bool) {
+		t.Fatal("expected connection types to be available")
+	}
+	if got := d.Get("connection_types.#").(int); got != 2 {
+		t.Fatalf("expected 2 connection types, got %d", got)
+	}
+	if got := d.Get("connection_types.1.connection_type").(string); got != "snowflake" {
+		t.Fatalf("unexpected connection type %q", got)
+	}
+	if got := d.Get("connection_types.1.standard_fields.0.name").(string); got != "login" {
+		t.Fatalf("unexpected standard field %q", got)
+	}
+	if !d.Get("connection_types.1.standard_fields.1.hidden").(bool) {
+		t.Fatal("expected port to be hidden")
+	}
+
+	extra := d.Get("connection_types.1.extra_fields.0").(map[string]interface{})
+	if extra["label"] != "Warehouse" || extra["type"] != "string" || extra["default_json"] != `"compute_wh"` {
+		t.Fatalf("unexpected extra field %v", extra)
+	}
+	if _, ok := d.Get("connection_types_by_type").(map[string]interface{})["aws"]; !ok {
+		t.Fatal("expected aws in connection_types_by_type")
+	}
+}
+
+func TestDataSourceProviderConnectionTypes_fakeAirflow2(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fakeAirflowProviders(fake)
+
+	fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": "2.9.3"})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceProviderConnectionTypes().Schema, map[string]interface{}{})
+	if err := dataSourceProviderConnectionTypesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("connection_types_available").(bool) {
+		t.Fatal("expected connection types to be unavailable")
+	}
+	if got := d.Get("providers.#").(int); got != 2 {
+		t.Fatalf("expected 2 providers, got %d", got)
+	}
+	if got := fake.requestCount(http.MethodGet, "/ui/"); got != 0 {
+		t.Fatalf("expected no UI requests on Airflow 2, got %d", got)
+	}
+}
+
+func TestDataSourceProviderConnectionTypes_fakeNotServed(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.playAirflow3("3.0.2")
+	fakeAirflowProviders(fake)
+
+	d := schema.TestResourceDataRaw(t, dataSourceProviderConnectionTypes().Schema, map[string]interface{}{})
+	if err := dataSourceProviderConnectionTypesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("connection_types_available").(bool) {
+		t.Fatal("expected connection types to be unavailable")
+	}
+	if got := d.Get("connection_types.#").(int); got != 0 {
+		t.Fatalf("expected no connection types, got %d", got)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceQueuedDatasetEvents() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceQueuedDatasetEventsRead,
+		Schema: map[string]*schema.Schema{
+			"dag_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"uris": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"events": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"uri": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"events_by_uri": keyedOutputSchema(),
+		},
+	}
+}
+
+// airflowQueuedEvents is the response of the queued dataset events endpoint,
+// which was added in Airflow 2.9 and isn't covered by the generated client.
+// Airflow 3 renamed datasets to assets and moved the endpoint to the v2 API,
+// where events refer to their asset by ID instead of URI.
+type airflowQueuedEvents struct {
+	QueuedEvents []struct {
+		Uri       string `json:"uri"`
+		AssetId   int64  `json:"asset_id"`
+		CreatedAt string `json:"created_at"`
+	} `json:"queued_events"`
+}
+
+func dataSourceQueuedDatasetEventsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	dagId := d.Get("dag_id").(string)
+	v2 := false
+	if pcfg.isAirflow3() {
+		v2 = true
+	}
+
+	var queued airflowQueuedEvents
+	var resp *http.Response
+	var err error
+	if v2 {
+		path := fmt.Sprintf("/api/v2/dags/%s/assets/queuedEvents", url.PathEscape(dagId))
+		resp, err = uiRequest(pcfg, http.MethodGet, path, nil, &queued)
+	} else {
+		path := fmt.Sprintf("/dags/%s/datasets/queuedEvent", url.PathEscape(dagId))
+		resp, err = apiRequest(pcfg, http.MethodGet, path, nil, nil, &queued)
+	}
+
+	// Airflow responds with 404 both when no events are queued for the DAG
+	// and when the DAG doesn't exist.
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		if err := checkQueuedEventsDagExists(pcfg, dagId, v2); err != nil {
+			return err
+		}
+		queued = airflowQueuedEvents{}
+	} else if err != nil {
+		return fmt.Errorf("failed to get queued dataset events of DAG `%s` from Airflow: %w", dagId, err)
+	}
+
+	if v2 {
+		if err := resolveQueuedEventUris(pcfg, &queued); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(queued.QueuedEvents, func(i, j int) bool { return queued.QueuedEvents[i].Uri < queued.QueuedEvents[j].Uri })
+
+	uris := make([]string, 0, len(queued.QueuedEvents))
+	events := make([]interface{}, 0, len(queued.QueuedEvents))
+	for _, e := range queued.QueuedEvents {
+		uris = append(uris, e.Uri)
+		events = append(events, map[string]interface{}{
+			"uri":        e.Uri,
+			"created_at": e.CreatedAt,
+		})
+	}
+
+	d.SetId(dagId)
+	d.Set("uris", uris)
+	if err := d.Set("events", events); err != nil {
+		return fmt.Errorf("error setting events: %w", err)
+	}
+	if err := setKeyedOutput(d, "events_by_uri", events, "uri"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkQueuedEventsDagExists fails unless the DAG whose queued events were
+// not found exists, so that a misspelled DAG isn't reported as having none.
+func checkQueuedEventsDagExists(pcfg ProviderConfig, dagId string, v2 bool) error {
+	var resp *http.Response
+	var err error
+	if v2 {
+		resp, err = uiRequest(pcfg, http.MethodGet, fmt.Sprintf("/api/v2/dags/%s", url.PathEscape(dagId)), nil, nil)
+	} else {
+		_, resp, err = pcfg.ApiClient.DAGApi.GetDag(pcfg.AuthContext, dagId).Execute()
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("DAG `%s` not found in Airflow", dagId)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get DAG `%s` from Airflow: %w", dagId, err)
+	}
+	return nil
+}
+
+// resolveQueuedEventUris looks up the URIs of the assets of queued events of
+// the v2 API, once per asset.
+func resolveQueuedEventUris(pcfg ProviderConfig, queued *airflowQueuedEvents) error {
+	uris := map[int64]string{}
+	for i, e := range queued.QueuedEvents {
+		uri, ok := uris[e.AssetId]
+		if !ok {
+			var asset struct {
+				Uri string `json:"uri"`
+			}
+			if _, err := uiRequest(pcfg, http.MethodGet, fmt.Sprintf("/api/v2/assets/%d", e.AssetId), nil, &asset); err != nil {
+				return fmt.Errorf("failed to get asset `%d` from Airflow: %w", e.AssetId, err)
+			}
+			uri = asset.Uri
+			uris[e.AssetId] = uri
+		}
+		queued.QueuedEvents[i].Uri = uri
+	}
+	return nil
+}
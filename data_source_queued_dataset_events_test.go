@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceQueuedDatasetEvents_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/dags/consumer/datasets/queuedEvent", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"queued_events": []interface{}{
+				map[string]interface{}{"uri": "s3://bucket/b", "dag_id": "consumer", "created_at": "2024-05-01T00:00:00+00:00"},
+				map[string]interface{}{"uri": "s3://bucket/a", "dag_id": "consumer", "created_at": "2024-05-02T00:00:00+00:00"},
+			},
+			"total_entries": 2,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceQueuedDatasetEvents().Schema, map[string]interface{}{
+		"dag_id": "consumer",
+	})
+	if err := dataSourceQueuedDatasetEventsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("uris").([]interface{}); len(got) != 2 || got[0] != "s3://bucket/a" {
+		t.Fatalf("unexpected uris %v", got)
+	}
+	if got := d.Get("events.0.created_at").(string); got != "2024-05-02T00:00:00+00:00" {
+		t.Fatalf("unexpected created_at %q", got)
+	}
+}
+
+func TestDataSourceQueuedDatasetEvents_fakeNoneQueued(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/dags/consumer", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "consumer"})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceQueuedDatasetEvents().Schema, map[string]interface{}{
+		"dag_id": "consumer",
+	})
+	if err := dataSourceQueuedDatasetEventsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := len(d.Get("uris").([]interface{})); got != 0 {
+		t.Fatalf("expected no queued events, got %d", got)
+	}
+}
+
+func TestDataSourceQueuedDatasetEvents_fakeDagNotFound(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourceQueuedDatasetEvents().Schema, map[string]interface{}{
+		"dag_id": "missing",
+	})
+	err := dataSourceQueuedDatasetEventsRead(d, m)
+	if err == nil || !strings.Contains(err.Error(), "DAG `missing` not found") {
+		t.Fatalf("expected a missing DAG to fail, got %v", err)
+	}
+}
+
+func TestDataSourceQueuedDatasetEvents_fakeAirflow3(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.playAirflow3("3.0.2")
+	fake.handle(http.MethodGet, "/api/v2/dags/consumer/assets/queuedEvents", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"queued_events": []interface{}{
+				map[string]interface{}{"asset_id": 2, "dag_id": "consumer", "created_at": "2025-05-01T00:00:00Z"},
+				map[string]interface{}{"asset_id": 1, "dag_id": "consumer", "created_at": "2025-05-02T00:00:00Z"},
+			},
+			"total_entries": 2,
+		})
+	})
+	for id, uri := range map[string]string{"1": "s3://bucket/a", "2": "s3://bucket/b"} {
+		uri := uri
+		fake.handle(http.MethodGet, "/api/v2/assets/"+id, func(w http.ResponseWriter, r *http.Request) {
+			writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"uri": uri})
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceQueuedDatasetEvents().Schema, map[string]interface{}{
+		"dag_id": "consumer",
+	})
+	if err := dataSourceQueuedDatasetEventsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("uris").([]interface{}); len(got) != 2 || got[0] != "s3://bucket/a" {
+		t.Fatalf("unexpected uris %v", got)
+	}
+	if got := d.Get("events.0.created_at").(string); got != "2025-05-02T00:00:00Z" {
+		t.Fatalf("unexpected created_at %q", got)
+	}
+	if got := fake.requestCount(http.MethodGet, "/dags/consumer/datasets"); got != 0 {
+		t.Fatalf("expected no v1 requests on Airflow 3, got %d", got)
+	}
+
+	// On Airflow 3, a missing DAG is looked up through the v2 API as well.
+	d = schema.TestResourceDataRaw(t, dataSourceQueuedDatasetEvents().Schema, map[string]interface{}{
+		"dag_id": "missing",
+	})
+	if err := dataSourceQueuedDatasetEventsRead(d, m); err == nil || !strings.Contains(err.Error(), "DAG `missing` not found") {
+		t.Fatalf("expected a missing DAG to fail, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// withReadRetry adds a retry block to a data source, with which reading it
+// is repeated while it fails, e.g. until a DAG appears. When pending is
+// given, reading is also repeated while it reports that the condition the
+// data source polls for isn't met yet, e.g. while a run is still running.
+// The last read is kept once the attempts are used up, so that a pending
+// condition can still be checked by the configuration.
+func withReadRetry(r *schema.Resource, pending func(d *schema.ResourceData) bool) *schema.Resource {
+	r.Schema["retry"] = &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"attempts": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntAtLeast(1),
+				},
+				"wait": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "10s",
+					ValidateFunc: validateDuration,
+				},
+			},
+		},
+	}
+
+	read := r.Read
+	r.Read = func(d *schema.ResourceData, m interface{}) error {
+		attempts, wait := 1, time.Duration(0)
+		if v, ok := d.GetOk("retry.0"); ok {
+			retry := v.(map[string]interface{})
+			attempts = retry["attempts"].(int)
+			// The duration was validated by the schema.
+			wait, _ = time.ParseDuration(retry["wait"].(string))
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := read(d, m)
+			if err == nil && (pending == nil || !pending(d)) {
+				return nil
+			}
+			if attempt >= attempts {
+				return err
+			}
+
+			if err != nil {
+				log.Printf("[DEBUG] Reading failed, retrying in %s (attempt %d of %d): %s", wait, attempt, attempts, err)
+			} else {
+				log.Printf("[DEBUG] Condition not met yet, retrying in %s (attempt %d of %d)", wait, attempt, attempts)
+			}
+			time.Sleep(wait)
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWithReadRetry_fakeDagRun(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	states := []string{"queued", "running", "success"}
+	calls := 0
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run", func(w http.ResponseWriter, r *http.Request) {
+		state := states[calls]
+		if calls < len(states)-1 {
+			calls++
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_id":     "example",
+			"dag_run_id": "run",
+			"state":      state,
+			"run_type":   "manual",
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceDagRun().Schema, map[string]interface{}{
+		"dag_id":     "example",
+		"dag_run_id": "run",
+		"retry": []interface{}{
+			map[string]interface{}{"attempts": 5, "wait": "1ms"},
+		},
+	})
+	if err := dataSourceDagRun().Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("state").(string); got != "success" {
+		t.Fatalf("expected the run to be polled until it finished, got state %q", got)
+	}
+	if got := fake.requestCount(http.MethodGet, "/dags/example/dagRuns/run"); got != 3 {
+		t.Fatalf("expected 3 reads, got %d", got)
+	}
+
+	// Once the attempts are used up, the last read is kept.
+	calls = 0
+	d = schema.TestResourceDataRaw(t, dataSourceDagRun().Schema, map[string]interface{}{
+		"dag_id":     "example",
+		"dag_run_id": "run",
+		"retry": []interface{}{
+			map[string]interface{}{"attempts": 2, "wait": "1ms"},
+		},
+	})
+	if err := dataSourceDagRun().Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("state").(string); got != "running" || d.Get("finished").(bool) {
+		t.Fatalf("expected the running state of the last attempt, got %q", got)
+	}
+}
+
+func TestWithReadRetry_fakeError(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	calls := 0
+	fake.handle(http.MethodGet, "/api/v2/dags/example/dagVersions", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			writeFakeAirflowError(w, http.StatusNotFound, "DAG not found")
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_versions": []interface{}{
+				map[string]interface{}{"id": "0195-a", "version_number": 1, "bundle_name": "dags-folder"},
+			},
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceDagVersion().Schema, map[string]interface{}{
+		"dag_id": "example",
+	})
+	if err := dataSourceDagVersion().Read(d, m); err == nil {
+		t.Fatal("expected reading without retry to fail")
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceDagVersion().Schema, map[string]interface{}{
+		"dag_id": "example",
+		"retry": []interface{}{
+			map[string]interface{}{"attempts": 3, "wait": "1ms"},
+		},
+	})
+	if err := dataSourceDagVersion().Read(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("bundle_name").(string); got != "dags-folder" {
+		t.Fatalf("unexpected bundle name %q", got)
+	}
+}
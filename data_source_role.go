@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRole() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRoleRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"action": airflowRoleActionsDataSchema(),
+			"ui_url": uiUrlSchema(),
+		},
+	}
+}
+
+// airflowRoleActionsDataSchema is the computed list of the permissions of a
+// role exported by the role data sources, sorted by resource and action.
+func airflowRoleActionsDataSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"action": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"resource": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRoleRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	name := d.Get("name").(string)
+	role, resp, err := getWithListFallback(pcfg, "role", name, client.RoleApi.GetRole(pcfg.AuthContext, name).Execute,
+		func(fn func(airflow.Role) bool) error {
+			_, err := listAirflowRoles(pcfg, fn)
+			return err
+		}, func(r airflow.Role) string { return r.GetName() })
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("role `%s` not found in Airflow", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get role `%s` from Airflow: %w", name, apiPermissionError(resp, err, "can_read on Roles"))
+	}
+
+	d.SetId(role.GetName())
+	if err := d.Set("action", flattenAirflowRoleActions(role.GetActions())); err != nil {
+		return fmt.Errorf("error setting action: %w", err)
+	}
+	d.Set("ui_url", airflowUiUrl(m, "role", role.GetName()))
+
+	return nil
+}
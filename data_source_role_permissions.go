@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRolePermissions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRolePermissionsRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"required_permissions": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"action": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"permissions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"missing_permissions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceRolePermissionsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	name := d.Get("name").(string)
+	role, resp, err := client.RoleApi.GetRole(pcfg.AuthContext, name).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to get role `%s` from Airflow: %w", name, apiPermissionError(resp, err, "can_read on Roles"))
+	}
+
+	permissions := []string{}
+	seen := map[string]bool{}
+	granted := map[string]bool{}
+	for _, apiObject := range role.GetActions() {
+		p := formatAirflowRoleAction(apiObject)
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+		action, resource := canonicalAirflowPermission(apiObject.Action.GetName(), apiObject.Resource.GetName())
+		granted[action+" on "+resource] = true
+	}
+	sort.Strings(permissions)
+
+	// Required permissions may use the names of another Airflow version.
+	missing := []string{}
+	for _, p := range expandStringSet(d.Get("required_permissions").(*schema.Set)) {
+		action, resource, _ := strings.Cut(p, " on ")
+		action, resource = canonicalAirflowPermission(action, resource)
+		if !granted[action+" on "+resource] {
+			missing = append(missing, p)
+		}
+	}
+	sort.Strings(missing)
+
+	d.SetId(name)
+	if err := d.Set("action", flattenAirflowRoleActions(role.GetActions())); err != nil {
+		return fmt.Errorf("error setting action: %w", err)
+	}
+	d.Set("permissions", permissions)
+	d.Set("missing_permissions", missing)
+
+	return nil
+}
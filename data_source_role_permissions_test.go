@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceRolePermissions_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("roles", map[string]interface{}{
+		"name": "analyst",
+		"actions": []interface{}{
+			map[string]interface{}{"action": map[string]interface{}{"name": "can_read"}, "resource": map[string]interface{}{"name": "DAGs"}},
+			map[string]interface{}{"action": map[string]interface{}{"name": "can_read"}, "resource": map[string]interface{}{"name": "Audit Logs"}},
+		},
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceRolePermissions().Schema, map[string]interface{}{
+		"name":                 "analyst",
+		"required_permissions": []interface{}{"can_read on DAGs", "can_edit on DAGs"},
+	})
+	if err := dataSourceRolePermissionsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("permissions").([]interface{}); !reflect.DeepEqual(got, []interface{}{"can_read on Audit Logs", "can_read on DAGs"}) {
+		t.Fatalf("unexpected permissions %v", got)
+	}
+	if got := d.Get("missing_permissions").([]interface{}); !reflect.DeepEqual(got, []interface{}{"can_edit on DAGs"}) {
+		t.Fatalf("unexpected missing_permissions %v", got)
+	}
+	if got := d.Get("action").([]interface{}); len(got) != 2 {
+		t.Fatalf("expected 2 actions, got %v", got)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceRole_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("roles", map[string]interface{}{
+		"name": "analyst",
+		"actions": []interface{}{
+			map[string]interface{}{"action": map[string]interface{}{"name": "can_read"}, "resource": map[string]interface{}{"name": "DAGs"}},
+			map[string]interface{}{"action": map[string]interface{}{"name": "can_read"}, "resource": map[string]interface{}{"name": "Audit Logs"}},
+		},
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceRole().Schema, map[string]interface{}{
+		"name": "analyst",
+	})
+	if err := dataSourceRoleRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("action.#").(int); got != 2 {
+		t.Fatalf("expected 2 actions, got %d", got)
+	}
+	if got := d.Get("action.0.resource").(string); got != "Audit Logs" {
+		t.Fatalf("unexpected first resource %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceRole().Schema, map[string]interface{}{
+		"name": "missing",
+	})
+	if err := dataSourceRoleRead(d, m); err == nil {
+		t.Fatal("expected an error for a missing role")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRoles() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRolesRead,
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"roles": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"action": airflowRoleActionsDataSchema(),
+					},
+				},
+			},
+			"roles_by_name": keyedOutputSchema(),
+		},
+	}
+}
+
+func dataSourceRolesRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	prefix := d.Get("name_prefix").(string)
+	var matches []airflow.Role
+	_, err := listAirflowRoles(pcfg, func(r airflow.Role) bool {
+		if strings.HasPrefix(r.GetName(), prefix) {
+			matches = append(matches, r)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].GetName() < matches[j].GetName() })
+
+	names := make([]string, 0, len(matches))
+	roles := make([]interface{}, 0, len(matches))
+	for _, r := range matches {
+		names = append(names, r.GetName())
+		roles = append(roles, map[string]interface{}{
+			"name":   r.GetName(),
+			"action": flattenAirflowRoleActions(r.GetActions()),
+		})
+	}
+
+	d.SetId("roles")
+	d.Set("names", names)
+	if err := d.Set("roles", roles); err != nil {
+		return fmt.Errorf("error setting roles: %w", err)
+	}
+	if err := setKeyedOutput(d, "roles_by_name", roles, "name"); err != nil {
+		return err
+	}
+
+	return nil
+}
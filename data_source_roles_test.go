@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceRoles_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	for _, name := range []string{"team_b", "team_a", "Viewer"} {
+		fake.seed("roles", map[string]interface{}{
+			"name": name,
+			"actions": []interface{}{
+				map[string]interface{}{"action": map[string]interface{}{"name": "can_read"}, "resource": map[string]interface{}{"name": "DAGs"}},
+			},
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceRoles().Schema, map[string]interface{}{
+		"name_prefix": "team_",
+	})
+	if err := dataSourceRolesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("names").([]interface{}); !reflect.DeepEqual(got, []interface{}{"team_a", "team_b"}) {
+		t.Fatalf("unexpected names %v", got)
+	}
+	if got := d.Get("roles.0.action.0.action").(string); got != "can_read" {
+		t.Fatalf("unexpected action %q", got)
+	}
+	if got := len(d.Get("roles_by_name").(map[string]interface{})); got != 2 {
+		t.Fatalf("expected 2 keyed roles, got %d", got)
+	}
+}
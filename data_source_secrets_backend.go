@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceSecretsBackend() *schema.Resource {
+	lookupSchema := func(idKey string) *schema.Schema {
+		return &schema.Schema{
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					idKey: {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"in_metadata_db": {
+						Type:     schema.TypeBool,
+						Computed: true,
+					},
+					"served_from_secrets_backend": {
+						Type:     schema.TypeBool,
+						Computed: true,
+					},
+				},
+			},
+		}
+	}
+
+	return &schema.Resource{
+		Read: dataSourceSecretsBackendRead,
+		Schema: map[string]*schema.Schema{
+			"variable_keys": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"connection_ids": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"backend": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"backend_known": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"variables":   lookupSchema("key"),
+			"connections": lookupSchema("connection_id"),
+
+			"variables_by_key":  keyedOutputSchema(),
+			"connections_by_id": keyedOutputSchema(),
+		},
+	}
+}
+
+// fetchSecretsBackend returns the secrets backend configured in Airflow, or
+// an empty string when none is. known is false when the configuration
+// can't be read because `[webserver] expose_config` is disabled.
+func fetchSecretsBackend(pcfg ProviderConfig) (backend string, known bool, err error) {
+	config, resp, err := pcfg.ApiClient.ConfigApi.GetConfig(pcfg.AuthContext).Execute()
+	if resp != nil && resp.StatusCode == http.StatusForbidden {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get config from Airflow: %w", err)
+	}
+
+	for _, section := range config.GetSections() {
+		if section.GetName() != "secrets" {
+			continue
+		}
+		for _, option := range section.GetOptions() {
+			if option.GetKey() == "backend" {
+				return option.GetValue(), true, nil
+			}
+		}
+	}
+
+	return "", true, nil
+}
+
+// checkSecretsBackend refuses to manage an object in the metadata database
+// when a secrets backend is configured, as Airflow looks objects up in the
+// secrets backend first and would never read the value Terraform manages.
+func checkSecretsBackend(d *schema.ResourceData, pcfg ProviderConfig, kind, id string) error {
+	if !d.Get("check_secrets_backend").(bool) {
+		return nil
+	}
+
+	backend, known, err := fetchSecretsBackend(pcfg)
+	if err != nil {
+		return err
+	}
+	if !known {
+		log.Printf("[WARN] Cannot check the secrets backend for %s `%s`, the Airflow config is not exposed", kind, id)
+		return nil
+	}
+	if backend != "" {
+		return fmt.Errorf("refusing to manage %s `%s`: Airflow has the secrets backend `%s` configured, which takes precedence over the metadata database", kind, id, backend)
+	}
+
+	return nil
+}
+
+func dataSourceSecretsBackendRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	backend, known, err := fetchSecretsBackend(pcfg)
+	if err != nil {
+		return err
+	}
+
+	var variables []interface{}
+	for _, v := range d.Get("variable_keys").([]interface{}) {
+		key := v.(string)
+		_, resp, err := client.VariableApi.GetVariable(pcfg.AuthContext, key).Execute()
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to get variable `%s` from Airflow: %w", key, err)
+		}
+
+		variables = append(variables, map[string]interface{}{
+			"key":                         key,
+			"in_metadata_db":              err == nil,
+			"served_from_secrets_backend": err != nil && backend != "",
+		})
+	}
+
+	var connections []interface{}
+	for _, v := range d.Get("connection_ids").([]interface{}) {
+		connId := v.(string)
+		_, resp, err := client.ConnectionApi.GetConnection(pcfg.AuthContext, connId).Execute()
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to get connection `%s` from Airflow: %w", connId, err)
+		}
+
+		connections = append(connections, map[string]interface{}{
+			"connection_id":               connId,
+			"in_metadata_db":              err == nil,
+			"served_from_secrets_backend": err != nil && backend != "",
+		})
+	}
+
+	d.SetId("secrets-backend")
+	d.Set("backend", backend)
+	d.Set("backend_known", known)
+	if err := d.Set("variables", variables); err != nil {
+		return fmt.Errorf("error setting variables: %w", err)
+	}
+	if err := d.Set("connections", connections); err != nil {
+		return fmt.Errorf("error setting connections: %w", err)
+	}
+	if err := setKeyedOutput(d, "variables_by_key", variables, "key"); err != nil {
+		return err
+	}
+	if err := setKeyedOutput(d, "connections_by_id", connections, "connection_id"); err != nil {
+		return err
+	}
+
+	return nil
+}
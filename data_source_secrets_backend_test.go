@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func fakeAirflowSecretsBackend(fake *fakeAirflow, backend string) {
+	fake.handle(http.MethodGet, "/config", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"sections": []interface{}{
+				map[string]interface{}{
+					"name": "secrets",
+					"options": []interface{}{
+						map[string]interface{}{"key": "backend", "value": backend},
+					},
+				},
+			},
+		})
+	})
+}
+
+func TestDataSourceSecretsBackend_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fakeAirflowSecretsBackend(fake, "airflow.providers.hashicorp.secrets.vault.VaultBackend")
+	fake.seed("variables", map[string]interface{}{"key": "in-db", "value": "foo"})
+	fake.seed("connections", map[string]interface{}{"connection_id": "in-db", "conn_type": "http"})
+
+	d := schema.TestResourceDataRaw(t, dataSourceSecretsBackend().Schema, map[string]interface{}{
+		"variable_keys":  []interface{}{"in-db", "in-vault"},
+		"connection_ids": []interface{}{"in-vault", "in-db"},
+	})
+
+	if err := dataSourceSecretsBackendRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if !d.Get("backend_known").(bool) {
+		t.Fatal("expected the backend to be known")
+	}
+	if got := d.Get("backend").(string); got != "airflow.providers.hashicorp.secrets.vault.VaultBackend" {
+		t.Fatalf("unexpected backend %q", got)
+	}
+	if !d.Get("variables.0.in_metadata_db").(bool) || d.Get("variables.0.served_from_secrets_backend").(bool) {
+		t.Fatal("expected variable in-db to be served from the metadata database")
+	}
+	if d.Get("variables.1.in_metadata_db").(bool) || !d.Get("variables.1.served_from_secrets_backend").(bool) {
+		t.Fatal("expected variable in-vault to be served from the secrets backend")
+	}
+	if !d.Get("connections.0.served_from_secrets_backend").(bool) || !d.Get("connections.1.in_metadata_db").(bool) {
+		t.Fatal("unexpected connection lookups")
+	}
+}
+
+func TestDataSourceSecretsBackend_fakeConfigNotExposed(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.failNext(http.MethodGet, "/config", http.StatusForbidden, 1)
+
+	d := schema.TestResourceDataRaw(t, dataSourceSecretsBackend().Schema, map[string]interface{}{
+		"variable_keys": []interface{}{"missing"},
+	})
+
+	if err := dataSourceSecretsBackendRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if d.Get("backend_known").(bool) {
+		t.Fatal("expected the backend to be unknown")
+	}
+	if d.Get("variables.0.served_from_secrets_backend").(bool) {
+		t.Fatal("expected an unknown backend not to be reported as serving the variable")
+	}
+}
+
+func TestResourceVariable_fakeCheckSecretsBackend(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fakeAirflowSecretsBackend(fake, "airflow.providers.amazon.aws.secrets.secrets_manager.SecretsManagerBackend")
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{
+		"key":                   "shadowed",
+		"value":                 "foo",
+		"check_secrets_backend": true,
+	})
+
+	err := resourceVariableCreate(d, m)
+	if err == nil || !strings.Contains(err.Error(), "SecretsManagerBackend") {
+		t.Fatalf("expected create to be refused, got %v", err)
+	}
+	if fake.object("variables", "shadowed") != nil {
+		t.Fatal("variable was created despite the secrets backend")
+	}
+}
+
+func TestResourceConnection_fakeCheckSecretsBackend(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fakeAirflowSecretsBackend(fake, "")
+
+	d := schema.TestResourceDataRaw(t, resourceConnection().Schema, map[string]interface{}{
+		"connection_id":         "not-shadowed",
+		"conn_type":             "http",
+		"check_secrets_backend": true,
+	})
+
+	if err := resourceConnectionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if fake.object("connections", "not-shadowed") == nil {
+		t.Fatal("connection was not created")
+	}
+}
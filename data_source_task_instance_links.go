@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceTaskInstanceLinks() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceTaskInstanceLinksRead,
+		Schema: map[string]*schema.Schema{
+			"dag_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"dag_run_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"task_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"links": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceTaskInstanceLinksRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	dagId := d.Get("dag_id").(string)
+	dagRunId := d.Get("dag_run_id").(string)
+	taskId := d.Get("task_id").(string)
+	id := fmt.Sprintf("%s:%s:%s", dagId, dagRunId, taskId)
+
+	// Airflow returns the links as an object of link name to URL rather than
+	// the collection the generated client expects, so it's decoded here.
+	// Links that aren't available for the run are null.
+	var links map[string]*string
+	path := fmt.Sprintf("/dags/%s/dagRuns/%s/taskInstances/%s/links", url.PathEscape(dagId), url.PathEscape(dagRunId), url.PathEscape(taskId))
+	_, err := retryWhileDagNotFound(pcfg, dagId, func() (*http.Response, error) {
+		return apiRequest(pcfg, http.MethodGet, path, nil, nil, &links)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get extra links of task instance `%s` from Airflow: %w", id, err)
+	}
+
+	tfMap := make(map[string]interface{}, len(links))
+	for name, href := range links {
+		if href != nil {
+			tfMap[name] = *href
+		}
+	}
+
+	d.SetId(id)
+	d.Set("links", tfMap)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceTaskInstanceLinks_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/dags/bootstrap/dagRuns/manual__2022-05-01T00:00:00+00:00/taskInstances/spark/links", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"Spark Job": "https://spark.example.com/jobs/1",
+			"Logs":      nil,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceTaskInstanceLinks().Schema, map[string]interface{}{
+		"dag_id":     "bootstrap",
+		"dag_run_id": "manual__2022-05-01T00:00:00+00:00",
+		"task_id":    "spark",
+	})
+	if err := dataSourceTaskInstanceLinksRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	links := d.Get("links").(map[string]interface{})
+	if len(links) != 1 || links["Spark Job"] != "https://spark.example.com/jobs/1" {
+		t.Fatalf("unexpected links %v", links)
+	}
+}
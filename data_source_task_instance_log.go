@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceTaskInstanceLog() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceTaskInstanceLogRead,
+		Schema: map[string]*schema.Schema{
+			"dag_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"dag_run_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"task_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"try_number": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"tail_lines": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"content": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceTaskInstanceLogRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient.TaskInstanceApi
+
+	dagId := d.Get("dag_id").(string)
+	dagRunId := d.Get("dag_run_id").(string)
+	taskId := d.Get("task_id").(string)
+	id := fmt.Sprintf("%s:%s:%s", dagId, dagRunId, taskId)
+
+	var taskInstance airflow.TaskInstance
+	_, err := retryWhileDagNotFound(pcfg, dagId, func() (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		taskInstance, resp, err = client.GetTaskInstance(pcfg.AuthContext, dagId, dagRunId, taskId).Execute()
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get task instance `%s` from Airflow: %w", id, err)
+	}
+
+	// Default to the latest try, which is the one that failed the run.
+	tryNumber := taskInstance.GetTryNumber()
+	if v, ok := d.GetOk("try_number"); ok {
+		tryNumber = int32(v.(int))
+	}
+
+	taskLog, _, err := client.GetLog(pcfg.AuthContext, dagId, dagRunId, taskId, tryNumber).FullContent(true).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to get log of try %d of task instance `%s` from Airflow: %w", tryNumber, id, err)
+	}
+
+	content := taskLog.GetContent()
+	if v, ok := d.GetOk("tail_lines"); ok {
+		content = tailLines(content, v.(int))
+	}
+
+	d.SetId(fmt.Sprintf("%s:%d", id, tryNumber))
+	d.Set("try_number", tryNumber)
+	d.Set("state", taskInstance.GetState())
+	d.Set("content", content)
+
+	return nil
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= n {
+		return s
+	}
+
+	return strings.Join(lines[len(lines)-n:], "\n")
+}
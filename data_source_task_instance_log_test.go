@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceTaskInstanceLog_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/dags/bootstrap/dagRuns/run-1/taskInstances/seed", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_id":     "bootstrap",
+			"task_id":    "seed",
+			"state":      "failed",
+			"try_number": 2,
+		})
+	})
+	fake.handle(http.MethodGet, "/dags/bootstrap/dagRuns/run-1/taskInstances/seed/logs/2", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"content": "starting\nseeding\nValueError: boom\n",
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceTaskInstanceLog().Schema, map[string]interface{}{
+		"dag_id":     "bootstrap",
+		"dag_run_id": "run-1",
+		"task_id":    "seed",
+		"tail_lines": 2,
+	})
+	if err := dataSourceTaskInstanceLogRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("try_number").(int); got != 2 {
+		t.Fatalf("expected the latest try to be read, got %d", got)
+	}
+	if got := d.Get("state").(string); got != "failed" {
+		t.Fatalf("expected state failed, got %q", got)
+	}
+	if got := d.Get("content").(string); got != "seeding\nValueError: boom" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceUnmanagedUsers() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceUnmanagedUsersRead,
+		Schema: map[string]*schema.Schema{
+			"managed_emails": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"managed_username_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"order_by": orderBySchema(),
+			"usernames": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"emails": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"users":          airflowUsersDataSchema(),
+			"users_by_email": keyedOutputSchema(),
+		},
+	}
+}
+
+func dataSourceUnmanagedUsersRead(d *schema.ResourceData, m interface{}) error {
+	// E-mails are compared case-insensitively.
+	managed := map[string]bool{}
+	for _, v := range d.Get("managed_emails").(*schema.Set).List() {
+		managed[strings.ToLower(v.(string))] = true
+	}
+	prefix := d.Get("managed_username_prefix").(string)
+
+	var unmanaged []airflow.UserCollectionItem
+	orderBy := d.Get("order_by").(string)
+	err := searchUsersOrderedBy(m, orderBy, func(user airflow.UserCollectionItem) bool {
+		if managed[strings.ToLower(user.GetEmail())] {
+			return true
+		}
+		if prefix != "" && strings.HasPrefix(user.GetUsername(), prefix) {
+			return true
+		}
+		unmanaged = append(unmanaged, user)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if orderBy == "" {
+		sort.Slice(unmanaged, func(i, j int) bool { return unmanaged[i].GetUsername() < unmanaged[j].GetUsername() })
+	}
+
+	users := make([]interface{}, 0, len(unmanaged))
+	usernames := make([]string, 0, len(unmanaged))
+	emails := make([]string, 0, len(unmanaged))
+	for _, user := range unmanaged {
+		users = append(users, flattenAirflowUserData(m, user))
+		usernames = append(usernames, user.GetUsername())
+		emails = append(emails, user.GetEmail())
+	}
+
+	d.SetId("unmanaged-users")
+	if err := d.Set("users", users); err != nil {
+		return fmt.Errorf("error setting users: %w", err)
+	}
+	d.Set("usernames", usernames)
+	d.Set("emails", emails)
+	if err := setKeyedOutput(d, "users_by_email", users, "email"); err != nil {
+		return err
+	}
+
+	return nil
+}
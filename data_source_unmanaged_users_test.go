@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceUnmanagedUsers_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	for _, name := range []string{"alice", "bob", "svc-scheduler", "mallory"} {
+		fake.seed("users", map[string]interface{}{
+			"username":   name,
+			"email":      name + "@example.com",
+			"first_name": name,
+			"last_name":  name,
+			"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}},
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceUnmanagedUsers().Schema, map[string]interface{}{
+		"managed_emails":          []interface{}{"Alice@example.com", "bob@example.com"},
+		"managed_username_prefix": "svc-",
+	})
+	if err := dataSourceUnmanagedUsersRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("usernames").([]interface{}); !reflect.DeepEqual(got, []interface{}{"mallory"}) {
+		t.Fatalf("expected only mallory to be unmanaged, got %v", got)
+	}
+	if got := d.Get("users.0.email").(string); got != "mallory@example.com" {
+		t.Fatalf("unexpected email %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceUser() *schema.Resource {
+	s := airflowUsersDataSchema().Elem.(*schema.Resource).Schema
+	s["username"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	s["ui_url"] = uiUrlSchema()
+
+	return &schema.Resource{
+		Read:   dataSourceUserRead,
+		Schema: s,
+	}
+}
+
+func dataSourceUserRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	username := d.Get("username").(string)
+	user, resp, err := client.UserApi.GetUser(pcfg.AuthContext, username).Execute()
+	if resp != nil && resp.StatusCode == 404 {
+		return fmt.Errorf("user `%s` not found in Airflow", username)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user `%s` from Airflow: %w", username, apiPermissionError(resp, err, "can_read on Users"))
+	}
+
+	d.SetId(user.GetUsername())
+	for k, v := range flattenAirflowUserData(m, user) {
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("error setting %s: %w", k, err)
+		}
+	}
+	d.Set("ui_url", airflowUiUrl(m, "user", user.GetUsername()))
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// unsafeResourceNameRegexp matches the characters that aren't allowed in
+// the names of Terraform resources.
+var unsafeResourceNameRegexp = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// dataSourceUserBulkImport lists the users of an existing environment as
+// import blocks of airflow_user resources, to adopt them with
+// `terraform plan -generate-config-out`.
+func dataSourceUserBulkImport() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceUserBulkImportRead,
+		Schema: map[string]*schema.Schema{
+			"resource_address_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "airflow_user",
+			},
+			"resource_name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"exclude_emails": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"exclude_username_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"imports": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resource_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"import_block": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"import_blocks": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceUserBulkImportRead(d *schema.ResourceData, m interface{}) error {
+	// E-mails are compared case-insensitively.
+	excluded := map[string]bool{}
+	for _, v := range d.Get("exclude_emails").(*schema.Set).List() {
+		excluded[strings.ToLower(v.(string))] = true
+	}
+	excludedPrefix := d.Get("exclude_username_prefix").(string)
+
+	var users []airflow.UserCollectionItem
+	err := searchUsers(m, func(user airflow.UserCollectionItem) bool {
+		// airflow_user is identified by e-mail, so users without one can't
+		// be imported.
+		if user.GetEmail() == "" || excluded[strings.ToLower(user.GetEmail())] {
+			return true
+		}
+		if excludedPrefix != "" && strings.HasPrefix(user.GetUsername(), excludedPrefix) {
+			return true
+		}
+		users = append(users, user)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].GetUsername() < users[j].GetUsername() })
+
+	addressPrefix := d.Get("resource_address_prefix").(string)
+	namePrefix := d.Get("resource_name_prefix").(string)
+	used := map[string]bool{}
+	imports := make([]interface{}, 0, len(users))
+	blocks := make([]string, 0, len(users))
+	for _, user := range users {
+		// Usernames that differ only in unsafe characters get a numbered
+		// suffix, so that every address is unique.
+		base := suggestedResourceName(namePrefix + user.GetUsername())
+		name := base
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+
+		address := addressPrefix + "." + name
+		block := fmt.Sprintf("import {\n  to = %s\n  id = %s\n}\n", address, hclQuote(user.GetEmail()))
+		imports = append(imports, map[string]interface{}{
+			"id":            user.GetEmail(),
+			"username":      user.GetUsername(),
+			"resource_name": name,
+			"address":       address,
+			"import_block":  block,
+		})
+		blocks = append(blocks, block)
+	}
+
+	d.SetId("user-bulk-import")
+	if err := d.Set("imports", imports); err != nil {
+		return fmt.Errorf("error setting imports: %w", err)
+	}
+	d.Set("import_blocks", strings.Join(blocks, "\n"))
+
+	return nil
+}
+
+// suggestedResourceName turns a username into a valid name of a resource,
+// e.g. `Jane.Doe@example.com` into `jane_doe_example_com`.
+func suggestedResourceName(username string) string {
+	name := strings.Trim(unsafeResourceNameRegexp.ReplaceAllString(strings.ToLower(username), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "user_" + name
+	}
+	return name
+}
+
+// hclQuote quotes a string for HCL, escaping template sequences as well.
+func hclQuote(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
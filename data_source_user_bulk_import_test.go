@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceUserBulkImport_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	for username, email := range map[string]string{
+		"Jane.Doe":      "jane@example.com",
+		"jane_doe":      "jane.doe@example.com",
+		"42":            "answer@example.com",
+		"svc-scheduler": "scheduler@example.com",
+		"admin":         "admin@example.com",
+	} {
+		fake.seed("users", map[string]interface{}{
+			"username":   username,
+			"email":      email,
+			"first_name": username,
+			"last_name":  username,
+			"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}},
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceUserBulkImport().Schema, map[string]interface{}{
+		"exclude_emails":          []interface{}{"Admin@example.com"},
+		"exclude_username_prefix": "svc-",
+	})
+	if err := dataSourceUserBulkImportRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	imports := d.Get("imports").([]interface{})
+	if len(imports) != 3 {
+		t.Fatalf("expected 3 imports, got %v", imports)
+	}
+	for i, expected := range []map[string]string{
+		{"id": "answer@example.com", "address": "airflow_user.user_42"},
+		{"id": "jane@example.com", "address": "airflow_user.jane_doe"},
+		{"id": "jane.doe@example.com", "address": "airflow_user.jane_doe_2"},
+	} {
+		got := imports[i].(map[string]interface{})
+		if got["id"] != expected["id"] || got["address"] != expected["address"] {
+			t.Fatalf("unexpected import %d: %v", i, got)
+		}
+	}
+
+	expected := "import {\n  to = airflow_user.user_42\n  id = \"answer@example.com\"\n}\n"
+	if got := d.Get("imports.0.import_block").(string); got != expected {
+		t.Fatalf("unexpected import block %q", got)
+	}
+}
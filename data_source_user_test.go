@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceUser_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("users", map[string]interface{}{
+		"username":   "alice",
+		"email":      "alice@example.com",
+		"first_name": "Alice",
+		"last_name":  "Doe",
+		"active":     true,
+		"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}, map[string]interface{}{"name": "Op"}},
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceUser().Schema, map[string]interface{}{
+		"username": "alice",
+	})
+	if err := dataSourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("email").(string); got != "alice@example.com" {
+		t.Fatalf("unexpected email %q", got)
+	}
+	if got := d.Get("roles").([]interface{}); len(got) != 2 || got[0] != "Op" {
+		t.Fatalf("unexpected roles %v", got)
+	}
+	if !d.Get("active").(bool) {
+		t.Fatal("expected the user to be active")
+	}
+}
+
+func TestDataSourceUser_fakeNotFound(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, dataSourceUser().Schema, map[string]interface{}{
+		"username": "missing",
+	})
+	if err := dataSourceUserRead(d, m); err == nil {
+		t.Fatal("expected an error for a missing user")
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceUsers() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceUsersRead,
+		Schema: map[string]*schema.Schema{
+			"username_filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"role": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"last_login_older_than_days": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"order_by": orderBySchema(),
+			"usernames": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"users":          airflowUsersDataSchema(),
+			"users_by_email": keyedOutputSchema(),
+		},
+	}
+}
+
+// airflowUsersDataSchema is the computed list of users exported by the user
+// data sources.
+func airflowUsersDataSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"username": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"email": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"first_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"last_name": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"active": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+				"roles": {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+				"last_login": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"login_count": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"failed_login_count": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"locked": {
+					Type:     schema.TypeBool,
+					Computed: true,
+				},
+				"created_on": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// airflowUsersFilter selects users by the filters of the airflow_users data
+// source. The users API doesn't support any filters, so they are applied to
+// the listed users.
+type airflowUsersFilter struct {
+	username        string
+	role            string
+	active          *bool
+	lastLoginBefore *time.Time
+}
+
+func expandAirflowUsersFilter(d *schema.ResourceData, now time.Time) airflowUsersFilter {
+	filter := airflowUsersFilter{
+		username: d.Get("username_filter").(string),
+		role:     d.Get("role").(string),
+	}
+
+	// A plain Get can't tell an unset filter from its zero value, so the
+	// raw configuration is consulted when it is available.
+	config := d.GetRawConfig()
+	configured := func(key string) bool {
+		if config.IsNull() || !config.IsKnown() {
+			_, ok := d.GetOkExists(key)
+			return ok
+		}
+		return !config.GetAttr(key).IsNull()
+	}
+
+	if configured("active") {
+		active := d.Get("active").(bool)
+		filter.active = &active
+	}
+	if configured("last_login_older_than_days") {
+		before := now.AddDate(0, 0, -d.Get("last_login_older_than_days").(int))
+		filter.lastLoginBefore = &before
+	}
+
+	return filter
+}
+
+func (f airflowUsersFilter) match(user airflow.UserCollectionItem) bool {
+	if f.username != "" && !strings.Contains(user.GetUsername(), f.username) {
+		return false
+	}
+
+	if f.role != "" {
+		found := false
+		for _, role := range user.GetRoles() {
+			if role.GetName() == f.role {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.active != nil && user.GetActive() != *f.active {
+		return false
+	}
+
+	// Users that never logged in are as dormant as they can get.
+	if f.lastLoginBefore != nil && user.GetLastLogin() != "" {
+		lastLogin, err := parseAirflowTime(user.GetLastLogin())
+		if err == nil && !lastLogin.Before(*f.lastLoginBefore) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseAirflowTime parses a timestamp returned by the Airflow API. Older
+// Airflow versions omit the UTC offset.
+func parseAirflowTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999999", s)
+}
+
+func dataSourceUsersRead(d *schema.ResourceData, m interface{}) error {
+	filter := expandAirflowUsersFilter(d, time.Now())
+
+	// Only matching users are kept while paging through all users.
+	var matches []airflow.UserCollectionItem
+	orderBy := d.Get("order_by").(string)
+	err := searchUsersOrderedBy(m, orderBy, func(user airflow.UserCollectionItem) bool {
+		if filter.match(user) {
+			matches = append(matches, user)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if orderBy == "" {
+		sort.Slice(matches, func(i, j int) bool { return matches[i].GetUsername() < matches[j].GetUsername() })
+	}
+
+	var users []interface{}
+	usernames := []string{}
+	for _, user := range matches {
+		usernames = append(usernames, user.GetUsername())
+		users = append(users, flattenAirflowUserData(m, user))
+	}
+
+	d.SetId("users")
+	if err := d.Set("users", users); err != nil {
+		return fmt.Errorf("error setting users: %w", err)
+	}
+	d.Set("usernames", usernames)
+	if err := setKeyedOutput(d, "users_by_email", users, "email"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func flattenAirflowUserData(m interface{}, user airflow.UserCollectionItem) map[string]interface{} {
+	return map[string]interface{}{
+		"username":           user.GetUsername(),
+		"email":              user.GetEmail(),
+		"first_name":         user.GetFirstName(),
+		"last_name":          user.GetLastName(),
+		"active":             user.GetActive(),
+		"roles":              flattenAirflowUserRoles(user.GetRoles()),
+		"last_login":         user.GetLastLogin(),
+		"login_count":        user.GetLoginCount(),
+		"failed_login_count": user.GetFailedLoginCount(),
+		"locked":             airflowUserLocked(m, user),
+		"created_on":         user.GetCreatedOn(),
+	}
+}
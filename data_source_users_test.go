@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceUsers_fakeFilters(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339Nano)
+	user := func(name, role string, active bool, lastLogin interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"username":   name,
+			"email":      name + "@example.com",
+			"first_name": name,
+			"last_name":  name,
+			"active":     active,
+			"last_login": lastLogin,
+			"roles":      []interface{}{map[string]interface{}{"name": role}},
+		}
+	}
+	fake.seed("users", user("recent", "Viewer", true, recent))
+	fake.seed("users", user("dormant", "Viewer", true, "2021-01-02T03:04:05.123456+00:00"))
+	fake.seed("users", user("never", "Viewer", true, nil))
+	fake.seed("users", user("inactive", "Viewer", false, "2021-01-02T03:04:05"))
+	fake.seed("users", user("admin", "Admin", true, "2021-01-02T03:04:05+00:00"))
+
+	cases := map[string]struct {
+		raw  map[string]interface{}
+		want []string
+	}{
+		"all": {
+			raw:  map[string]interface{}{},
+			want: []string{"admin", "dormant", "inactive", "never", "recent"},
+		},
+		"username filter": {
+			raw:  map[string]interface{}{"username_filter": "in"},
+			want: []string{"admin", "inactive"},
+		},
+		"role": {
+			raw:  map[string]interface{}{"role": "Admin"},
+			want: []string{"admin"},
+		},
+		"inactive": {
+			raw:  map[string]interface{}{"active": false},
+			want: []string{"inactive"},
+		},
+		"dormant viewers": {
+			raw:  map[string]interface{}{"role": "Viewer", "active": true, "last_login_older_than_days": 90},
+			want: []string{"dormant", "never"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceUsers().Schema, tc.raw)
+			if err := dataSourceUsersRead(d, m); err != nil {
+				t.Fatalf("read: %s", err)
+			}
+
+			var got []string
+			for _, v := range d.Get("usernames").([]interface{}) {
+				got = append(got, v.(string))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDataSourceUsers_fakeOrderBy(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/users", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("order_by"); got != "-last_login" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected order_by "+got)
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"username": "recent", "last_login": "2022-05-02T00:00:00+00:00"},
+				map[string]interface{}{"username": "older", "last_login": "2022-05-01T00:00:00+00:00"},
+			},
+			"total_entries": 2,
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, dataSourceUsers().Schema, map[string]interface{}{
+		"order_by": "-last_login",
+	})
+	if err := dataSourceUsersRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("usernames").([]interface{}); !reflect.DeepEqual(got, []interface{}{"recent", "older"}) {
+		t.Fatalf("expected the order of the API to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceVariable() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVariableRead,
+		Schema: map[string]*schema.Schema{
+			"key": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"full_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"value": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"sensitive_state_mode": sensitiveStateModeSchema(),
+			"ui_url":               uiUrlSchema(),
+		},
+	}
+}
+
+func dataSourceVariableRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	// Keys are prefixed like those of airflow_variable, so that a variable
+	// is looked up with the key it was created with.
+	key := variableKeyPrefix(d.Get("key_prefix").(string), m) + d.Get("key").(string)
+	variable, resp, err := client.VariableApi.GetVariable(pcfg.AuthContext, key).Execute()
+	if resp != nil && resp.StatusCode == 404 {
+		return fmt.Errorf("variable `%s` not found in Airflow", key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get variable `%s` from Airflow: %w", key, apiPermissionError(resp, err, "can_read on Variables"))
+	}
+
+	mode := effectiveSensitiveStateMode(m, sensitiveStatePlain)
+
+	d.SetId(variable.GetKey())
+	d.Set("full_key", variable.GetKey())
+	d.Set("value", sensitiveStateValue(mode, variable.GetValue()))
+	d.Set("sensitive_state_mode", mode)
+	d.Set("ui_url", airflowUiUrl(m, "variable", variable.GetKey()))
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceVariable_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.VariableKeyPrefix = "team_a_"
+
+	fake.seed("variables", map[string]interface{}{"key": "team_a_bucket", "value": "s3://bucket"})
+
+	d := schema.TestResourceDataRaw(t, dataSourceVariable().Schema, map[string]interface{}{
+		"key": "bucket",
+	})
+	if err := dataSourceVariableRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if got := d.Get("value").(string); got != "s3://bucket" {
+		t.Fatalf("unexpected value %q", got)
+	}
+	if got := d.Get("full_key").(string); got != "team_a_bucket" {
+		t.Fatalf("unexpected full_key %q", got)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceVariable().Schema, map[string]interface{}{
+		"key": "missing",
+	})
+	if err := dataSourceVariableRead(d, m); err == nil {
+		t.Fatal("expected an error for a missing variable")
+	}
+}
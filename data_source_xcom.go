@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	xcomDecodeRaw    = "raw"
+	xcomDecodeJson   = "json"
+	xcomDecodeBase64 = "base64"
+)
+
+func dataSourceXcom() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceXcomRead,
+		Schema: map[string]*schema.Schema{
+			"dag_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"dag_run_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"task_id": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"key": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "return_value",
+			},
+			"decode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      xcomDecodeRaw,
+				ValidateFunc: validation.StringInSlice([]string{xcomDecodeRaw, xcomDecodeJson, xcomDecodeBase64}, false),
+			},
+			"max_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1 << 20,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"timestamp": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"value": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceXcomRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient.XComApi
+
+	dagId := d.Get("dag_id").(string)
+	dagRunId := d.Get("dag_run_id").(string)
+	taskId := d.Get("task_id").(string)
+	key := d.Get("key").(string)
+	id := fmt.Sprintf("%s:%s:%s:%s", dagId, dagRunId, taskId, key)
+
+	var xcom airflow.XCom
+	_, err := retryWhileDagNotFound(pcfg, dagId, func() (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		xcom, resp, err = client.GetXcomEntry(pcfg.AuthContext, dagId, dagRunId, taskId, key).Execute()
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get XCom `%s` from Airflow: %w", id, err)
+	}
+
+	raw := xcom.GetValue()
+	// Large payloads, e.g. a dataframe pushed by mistake, would bloat the
+	// state and every plan reading it.
+	if maxSize := d.Get("max_size").(int); maxSize > 0 && len(raw) > maxSize {
+		return fmt.Errorf("XCom `%s` is %d bytes, more than max_size of %d bytes", id, len(raw), maxSize)
+	}
+
+	value, err := decodeXcomValue(raw, d.Get("decode").(string))
+	if err != nil {
+		return fmt.Errorf("failed to decode XCom `%s`: %w", id, err)
+	}
+
+	d.SetId(id)
+	d.Set("timestamp", xcom.GetTimestamp())
+	d.Set("size", len(raw))
+	d.Set("value", value)
+
+	return nil
+}
+
+// decodeXcomValue returns the value of an XCom as read by the stable API,
+// which serializes it as a string, in the given decode mode.
+func decodeXcomValue(raw, decode string) (string, error) {
+	switch decode {
+	case xcomDecodeJson:
+		// Compact the value so that whitespace changes don't show up as
+		// differences of the output.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(raw)); err != nil {
+			return "", fmt.Errorf("value is not JSON, Airflow may have returned the Python representation of it, use decode = %q instead: %w", xcomDecodeRaw, err)
+		}
+		return buf.String(), nil
+	case xcomDecodeBase64:
+		return base64.StdEncoding.EncodeToString([]byte(raw)), nil
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDataSourceXcom_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/dags/bootstrap/dagRuns/run-1/taskInstances/seed/xcomEntries/return_value", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"key":       "return_value",
+			"timestamp": "2022-05-01T00:00:00+00:00",
+			"value":     "{\"rows\": 42}",
+		})
+	})
+
+	cases := []struct {
+		decode  string
+		maxSize int
+		value   string
+		err     string
+	}{
+		{decode: "raw", maxSize: 1024, value: "{\"rows\": 42}"},
+		{decode: "json", maxSize: 1024, value: "{\"rows\":42}"},
+		{decode: "base64", maxSize: 1024, value: "eyJyb3dzIjogNDJ9"},
+		{decode: "raw", maxSize: 4, err: "more than max_size"},
+		{decode: "raw", maxSize: 0, value: "{\"rows\": 42}"},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, dataSourceXcom().Schema, map[string]interface{}{
+			"dag_id":     "bootstrap",
+			"dag_run_id": "run-1",
+			"task_id":    "seed",
+			"decode":     c.decode,
+			"max_size":   c.maxSize,
+		})
+		err := dataSourceXcomRead(d, m)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("%s/%d: expected error containing %q, got %v", c.decode, c.maxSize, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s/%d: read: %s", c.decode, c.maxSize, err)
+		}
+		if got := d.Get("value").(string); got != c.value {
+			t.Fatalf("%s/%d: expected value %q, got %q", c.decode, c.maxSize, c.value, got)
+		}
+		if got := d.Get("size").(int); got != 12 {
+			t.Fatalf("%s/%d: expected size 12, got %d", c.decode, c.maxSize, got)
+		}
+	}
+}
+
+func TestDecodeXcomValue_pythonRepr(t *testing.T) {
+	if _, err := decodeXcomValue("{'rows': 42}", xcomDecodeJson); err == nil {
+		t.Fatal("expected the Python representation of a dict to fail JSON decoding")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// deletionProtectionSchema guards critical objects against being destroyed
+// or replaced by accident.
+func deletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+}
+
+// checkDeletionProtection refuses deleting an object with deletion
+// protection. The flag has to be turned off and applied before the object
+// can be deleted, so the decision is recorded in state first.
+func checkDeletionProtection(d *schema.ResourceData, kind string) error {
+	if d.Get("deletion_protection").(bool) {
+		return fmt.Errorf("cannot delete %s `%s` with deletion_protection set to true, set it to false and apply before destroying or replacing it", kind, d.Id())
+	}
+	return nil
+}
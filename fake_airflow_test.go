@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// fakeAirflowCollections describes the collections served by the fake
+// Airflow API, keyed by the URL path segment used by the stable API.
+var fakeAirflowCollections = map[string]string{
+	"connections": "connection_id",
+	"pools":       "name",
+	"roles":       "name",
+	"users":       "username",
+	"variables":   "key",
+}
+
+// fakeAirflowMaxPageSize mirrors the Airflow API default maximum page size.
+const fakeAirflowMaxPageSize = 100
+
+// fakeAirflowFailure is an injected failure returned instead of serving
+// the next matching request.
+type fakeAirflowFailure struct {
+	method     string
+	pathPrefix string
+	status     int
+	remaining  int
+}
+
+// fakeAirflow is an in-memory implementation of the parts of the Airflow
+// stable REST API used by the provider. It allows CRUD, pagination and
+// retry logic to be unit tested without a live Airflow instance.
+type fakeAirflow struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	objects     map[string]map[string]map[string]interface{}
+	handlers    map[string]http.HandlerFunc
+	failures    []*fakeAirflowFailure
+	requests    []*http.Request
+	maxPageSize int
+	// airflow3 makes the server serve only the v2 API, like Airflow 3.
+	airflow3 bool
+}
+
+func newFakeAirflow(t *testing.T) *fakeAirflow {
+	t.Helper()
+
+	f := &fakeAirflow{
+		objects:     map[string]map[string]map[string]interface{}{},
+		handlers:    map[string]http.HandlerFunc{},
+		maxPageSize: fakeAirflowMaxPageSize,
+	}
+	for collection := range fakeAirflowCollections {
+		f.objects[collection] = map[string]map[string]interface{}{}
+	}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// providerConfig configures the provider against the fake server. API calls
+// aren't retried, so that injected failures reach the code under test.
+func (f *fakeAirflow) providerConfig(t *testing.T) ProviderConfig {
+	t.Helper()
+
+	return f.providerConfigWith(t, map[string]interface{}{"max_retries": 0})
+}
+
+// providerConfigWith configures the provider against the fake server with
+// the given provider arguments.
+func (f *fakeAirflow) providerConfigWith(t *testing.T, args map[string]interface{}) ProviderConfig {
+	t.Helper()
+
+	raw := map[string]interface{}{
+		"base_endpoint": f.URL,
+		"username":      "admin",
+		"password":      "admin",
+	}
+	for k, v := range args {
+		raw[k] = v
+	}
+	d := schema.TestResourceDataRaw(t, AirflowProvider().Schema, raw)
+
+	m, err := providerConfigure(d)
+	if err != nil {
+		t.Fatalf("failed to configure provider: %s", err)
+	}
+
+	return m.(ProviderConfig)
+}
+
+// seed stores an object in the given collection. The object must contain
+// the identifying field of the collection.
+func (f *fakeAirflow) seed(collection string, obj map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := fmt.Sprint(obj[fakeAirflowCollections[collection]])
+	f.objects[collection][id] = obj
+}
+
+// object returns a stored object, or nil if it does not exist.
+func (f *fakeAirflow) object(collection, id string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.objects[collection][id]
+}
+
+// handle overrides the handler for an exact method and API path, such as
+// "GET /dags/example". Paths are relative to /api/v1.
+func (f *fakeAirflow) handle(method, path string, h http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.handlers[method+" "+path] = h
+}
+
+// playAirflow3 makes the server behave like Airflow 3 of the given version:
+// the stable API isn't served, and the version is only served by the v2
+// API. Handlers must be registered for the full /api/v2 paths.
+func (f *fakeAirflow) playAirflow3(version string) {
+	f.handle(http.MethodGet, "/api/v2/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": version})
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.airflow3 = true
+}
+
+// failNext makes the next n requests matching method and path prefix fail
+// with the given status code. An empty method matches any method.
+func (f *fakeAirflow) failNext(method, pathPrefix string, status, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, &fakeAirflowFailure{
+		method:     method,
+		pathPrefix: pathPrefix,
+		status:     status,
+		remaining:  n,
+	})
+}
+
+// requestCount returns the number of requests received for a method and
+// path prefix. An empty method matches any method.
+func (f *fakeAirflow) requestCount(method, pathPrefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count := 0
+	for _, r := range f.requests {
+		if (method == "" || r.Method == method) && strings.HasPrefix(strings.TrimPrefix(r.URL.Path, "/api/v1"), pathPrefix) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (f *fakeAirflow) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+
+	for _, failure := range f.failures {
+		if failure.remaining > 0 && (failure.method == "" || failure.method == r.Method) && strings.HasPrefix(path, failure.pathPrefix) {
+			failure.remaining--
+			f.mu.Unlock()
+			writeFakeAirflowError(w, failure.status, "injected failure")
+			return
+		}
+	}
+
+	if f.airflow3 && strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		f.mu.Unlock()
+		writeFakeAirflowError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	h, ok := f.handlers[r.Method+" "+path]
+	f.mu.Unlock()
+
+	if ok {
+		h(w, r)
+		return
+	}
+
+	parts := strings.SplitN(strings.Trim(path, "/"), "/", 2)
+	idField, ok := fakeAirflowCollections[parts[0]]
+	if !ok {
+		writeFakeAirflowError(w, http.StatusNotFound, "unknown endpoint")
+		return
+	}
+
+	if len(parts) == 1 {
+		f.serveCollection(w, r, parts[0], idField)
+		return
+	}
+
+	f.serveObject(w, r, parts[0], parts[1])
+}
+
+func (f *fakeAirflow) serveCollection(w http.ResponseWriter, r *http.Request, collection, idField string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := f.maxPageSize
+		if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v < limit {
+			limit = v
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		ids := make([]string, 0, len(f.objects[collection]))
+		for id := range f.objects[collection] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		page := make([]map[string]interface{}, 0, limit)
+		for i := offset; i < len(ids) && len(page) < limit; i++ {
+			page = append(page, f.objects[collection][ids[i]])
+		}
+
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			collection:      page,
+			"total_entries": len(ids),
+		})
+	case http.MethodPost:
+		var obj map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+			writeFakeAirflowError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		id := fmt.Sprint(obj[idField])
+		if _, exists := f.objects[collection][id]; exists {
+			writeFakeAirflowError(w, http.StatusConflict, "already exists")
+			return
+		}
+
+		delete(obj, "password")
+		f.objects[collection][id] = obj
+		writeFakeAirflowJSON(w, http.StatusOK, obj)
+	default:
+		writeFakeAirflowError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (f *fakeAirflow) serveObject(w http.ResponseWriter, r *http.Request, collection, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	obj, exists := f.objects[collection][id]
+	if !exists {
+		writeFakeAirflowError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeFakeAirflowJSON(w, http.StatusOK, obj)
+	case http.MethodPatch:
+		var patch map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeFakeAirflowError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		delete(patch, "password")
+		for k, v := range patch {
+			obj[k] = v
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, obj)
+	case http.MethodDelete:
+		delete(f.objects[collection], id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeAirflowError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func writeFakeAirflowJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeAirflowError(w http.ResponseWriter, status int, detail string) {
+	writeFakeAirflowJSON(w, status, map[string]interface{}{
+		"detail": detail,
+		"status": status,
+		"title":  http.StatusText(status),
+		"type":   "about:blank",
+	})
+}
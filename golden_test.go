@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// assertGolden compares the JSON encoding of v against the golden file
+// testdata/golden/<name>.json. Run `go test -run Golden -update` to
+// regenerate the golden files after an intentional change.
+func assertGolden(t *testing.T, name string, v interface{}) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode %s: %s", name, err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %s", path, err)
+	}
+
+	if string(got) != string(expected) {
+		t.Errorf("%s does not match golden file %s\ngot:\n%s\nexpected:\n%s", name, path, got, expected)
+	}
+}
+
+func TestGolden_userRoles(t *testing.T) {
+	cases := map[string][]interface{}{
+		"user_roles_empty":    {},
+		"user_roles_single":   {"Viewer"},
+		"user_roles_multiple": {"Viewer", "Admin", "Op"},
+	}
+
+	for name, roles := range cases {
+		t.Run(name, func(t *testing.T) {
+			expanded := expandAirflowUserRoles(schema.NewSet(schema.HashString, roles))
+			assertGolden(t, name+"_expanded", expanded)
+			assertGolden(t, name+"_flattened", flattenAirflowUserRoles(expanded))
+		})
+	}
+}
+
+func TestGolden_roleActions(t *testing.T) {
+	cases := map[string][]interface{}{
+		"role_actions_empty": {},
+		"role_actions_multiple": {
+			map[string]interface{}{"action": "can_read", "resource": "DAGs"},
+			map[string]interface{}{"action": "can_edit", "resource": "DAGs"},
+			map[string]interface{}{"action": "can_read", "resource": "Audit Logs"},
+		},
+	}
+
+	for name, actions := range cases {
+		t.Run(name, func(t *testing.T) {
+			expanded := expandAirflowRoleActions(actions)
+			assertGolden(t, name+"_expanded", expanded)
+			assertGolden(t, name+"_flattened", flattenAirflowRoleActions(expanded))
+		})
+	}
+}
+
+func TestGolden_connection(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"connection_minimal": {
+			"connection_id": "minimal",
+			"conn_type":     "http",
+		},
+		"connection_full": {
+			"connection_id": "full",
+			"conn_type":     "postgres",
+			"host":          "db.example.com",
+			"login":         "user",
+			"schema":        "public",
+			"port":          5432,
+			"extra":         `{"sslmode": "require"}`,
+		},
+	}
+
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceConnection().Schema, raw)
+			assertGolden(t, name+"_expanded", expandAirflowConnection(d, raw["connection_id"].(string)))
+		})
+	}
+}
+
+func TestGolden_variable(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"variable_plain": {
+			"key":   "plain",
+			"value": "bar",
+		},
+		"variable_json": {
+			"key":   "json",
+			"value": `{"foo": ["bar", 1]}`,
+		},
+	}
+
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceVariable().Schema, raw)
+			assertGolden(t, name+"_expanded", expandAirflowVariable(d, raw["key"].(string)))
+		})
+	}
+}
+
+// Ensure the golden files are decoded back into the same API objects so a
+// golden file can't silently drift from the client models.
+func TestGolden_roundTrip(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "golden", "connection_full_expanded.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conn airflow.Connection
+	if err := json.Unmarshal(b, &conn); err != nil {
+		t.Fatalf("failed to decode golden connection: %s", err)
+	}
+	if conn.GetPort() != 5432 || conn.GetExtra() != `{"sslmode": "require"}` {
+		t.Fatalf("unexpected golden connection: %+v", conn)
+	}
+}
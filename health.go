@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+const (
+	healthUnreachable = "unreachable"
+	healthUnhealthy   = "unhealthy"
+	healthHealthy     = "healthy"
+)
+
+// waitForHealthy blocks until both the metadatabase and the scheduler of
+// Airflow report healthy. A webserver that is still booting, e.g. right
+// after the environment was created, is retried until timeout.
+func waitForHealthy(pcfg ProviderConfig, timeout time.Duration) error {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{healthUnreachable, healthUnhealthy},
+		Target:  []string{healthHealthy},
+		Refresh: healthRefreshFunc(pcfg),
+		Timeout: timeout,
+	}
+
+	if _, err := stateConf.WaitForStateContext(pcfg.AuthContext); err != nil {
+		return fmt.Errorf("error waiting for Airflow to become healthy: %s", err)
+	}
+
+	return nil
+}
+
+func healthRefreshFunc(pcfg ProviderConfig) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		health, _, err := pcfg.ApiClient.MonitoringApi.GetHealth(pcfg.AuthContext).Execute()
+		if err != nil {
+			log.Printf("[DEBUG] Airflow health check failed: %s", err)
+			return health, healthUnreachable, nil
+		}
+
+		metadatabase := health.Metadatabase.GetStatus()
+		scheduler := health.Scheduler.GetStatus()
+		if metadatabase != airflow.HEALTHSTATUS_HEALTHY || scheduler != airflow.HEALTHSTATUS_HEALTHY {
+			log.Printf("[DEBUG] Airflow isn't healthy yet: metadatabase %s, scheduler %s", metadatabase, scheduler)
+			return health, healthUnhealthy, nil
+		}
+
+		return health, healthHealthy, nil
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaitForHealthy_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	// The webserver is still booting, then the scheduler starts late.
+	fake.failNext(http.MethodGet, "/health", http.StatusServiceUnavailable, 1)
+	checks := 0
+	fake.handle(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request) {
+		checks++
+		scheduler := "unhealthy"
+		if checks > 1 {
+			scheduler = "healthy"
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"metadatabase": map[string]interface{}{"status": "healthy"},
+			"scheduler":    map[string]interface{}{"status": scheduler},
+		})
+	})
+
+	if err := waitForHealthy(m, time.Minute); err != nil {
+		t.Fatalf("wait: %s", err)
+	}
+	if got := fake.requestCount(http.MethodGet, "/health"); got != 3 {
+		t.Fatalf("expected 3 health checks, got %d", got)
+	}
+}
+
+func TestWaitForHealthy_fakeTimeout(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	if err := waitForHealthy(m, time.Second); err == nil {
+		t.Fatal("expected a timeout while Airflow is unreachable")
+	}
+}
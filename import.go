@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// airflowKeyRegexp matches the IDs Airflow allows for DAGs and tasks.
+var airflowKeyRegexp = regexp.MustCompile(`^[\w.-]+$`)
+
+// resourceDagRunImport imports a DAG run by `<dag_id>:<dag_run_id>` or
+// `<dag_id>/<dag_run_id>`. DAG IDs can't contain either separator, while
+// generated run IDs contain colons, so the ID is split at the first one.
+func resourceDagRunImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	i := strings.IndexAny(d.Id(), ":/")
+	if i <= 0 || i == len(d.Id())-1 {
+		return nil, fmt.Errorf("unexpected format of import ID (%s), expected DAG-ID:DAG-RUN-ID", d.Id())
+	}
+
+	dagId, dagRunId := d.Id()[:i], d.Id()[i+1:]
+	if !airflowKeyRegexp.MatchString(dagId) {
+		return nil, fmt.Errorf("invalid DAG ID `%s` in import ID (%s)", dagId, d.Id())
+	}
+
+	d.SetId(dagId + ":" + dagRunId)
+	d.Set("dag_id", dagId)
+	d.Set("dag_run_id", dagRunId)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceUserImport imports a user by e-mail, the ID of an airflow_user, or
+// by username, which is resolved to the e-mail of the user. Without it, a
+// user imported by username would be looked up by an e-mail that no user has
+// and vanish from state on the first refresh.
+func resourceUserImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	id := strings.TrimSpace(d.Id())
+	if id == "" {
+		return nil, fmt.Errorf("unexpected format of import ID (%s), expected the e-mail or username of the user", d.Id())
+	}
+
+	// The ID is tried as a username first, which is a single call, and only
+	// looked up as an e-mail when no user has that username.
+	user, resp, err := client.UserApi.GetUser(pcfg.AuthContext, id).Execute()
+	if resp != nil && resp.StatusCode == 404 {
+		exists := false
+		err := searchUsers(m, func(u airflow.UserCollectionItem) bool {
+			if u.GetEmail() == id {
+				user, exists = u, true
+			}
+			return !exists
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("no user with the e-mail or username `%s` found in Airflow", id)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user `%s` from Airflow: %w", id, apiPermissionError(resp, err, "can_read on Users"))
+	}
+	if user.GetEmail() == "" {
+		return nil, fmt.Errorf("user `%s` has no e-mail, which airflow_user requires", id)
+	}
+
+	d.SetId(user.GetEmail())
+	d.Set("username", user.GetUsername())
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceApiResourceImport imports an object by `<path>:<id>`, where path is
+// the collection the object was created in.
+func resourceApiResourceImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of import ID (%s), expected PATH:ID", d.Id())
+	}
+
+	d.SetId(parts[1])
+	d.Set("path", parts[0])
+
+	return []*schema.ResourceData{d}, nil
+}
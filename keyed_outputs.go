@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// keyedOutputSchema is the map a list data source exports alongside a list,
+// keyed by the natural ID of its items so that it can be passed to for_each
+// directly. Maps of objects can't be expressed by the SDK, so every value is
+// the JSON encoding of the item, to be read with jsondecode.
+func keyedOutputSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// keyedOutput returns the items of a flattened list keyed by their key
+// attribute, with each item encoded as JSON.
+func keyedOutput(items []interface{}, key string) (map[string]interface{}, error) {
+	keyed := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		tfMap := item.(map[string]interface{})
+
+		v, err := json.Marshal(tfMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s `%v`: %w", key, tfMap[key], err)
+		}
+		keyed[fmt.Sprint(tfMap[key])] = string(v)
+	}
+
+	return keyed, nil
+}
+
+// setKeyedOutput sets the keyed map of a flattened list.
+func setKeyedOutput(d *schema.ResourceData, attr string, items []interface{}, key string) error {
+	keyed, err := keyedOutput(items, key)
+	if err != nil {
+		return err
+	}
+	if err := d.Set(attr, keyed); err != nil {
+		return fmt.Errorf("error setting %s: %w", attr, err)
+	}
+
+	return nil
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-log/tfsdklog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 )
 
 func main() {
 	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: func() *schema.Provider {
-			return AirflowProvider()
+		GRPCProviderFunc: func() tfprotov5.ProviderServer {
+			return &metricsSummaryServer{ProviderServer: schema.NewGRPCProviderServer(AirflowProvider())}
 		},
 	})
+
+	// Without a stop, the plugin server shutting down is the only sign of
+	// the end of the run. The loggers of the SDK only live as long as a
+	// single RPC, so the summary gets a provider logger of its own.
+	logAPIMetricsSummary(tfsdklog.NewRootProviderLogger(context.Background(), tfsdklog.WithoutLocation()))
 }
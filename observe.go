@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// manageSchema controls whether Terraform changes an object. Objects that
+// aren't managed are only read, so their drift from the configuration shows
+// up in plans without ever being applied, e.g. while a team moves objects
+// that are administered by hand to Terraform.
+func manageSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  true,
+	}
+}
+
+func isObserveOnly(d *schema.ResourceData) bool {
+	return !d.Get("manage").(bool)
+}
+
+// observeExisting adopts the existing object with the given ID instead of
+// creating it.
+func observeExisting(d *schema.ResourceData, m interface{}, kind, id string, read schema.ReadFunc) error {
+	d.SetId(id)
+	if err := read(d, m); err != nil {
+		return err
+	}
+	if d.Id() == "" {
+		return fmt.Errorf("%s `%s` doesn't exist in Airflow, objects with manage set to false must already exist", kind, id)
+	}
+	return nil
+}
+
+// observeOnly warns about an operation that isn't made because the object
+// isn't managed, so that the apply doesn't silently report a change.
+func observeOnly(d *schema.ResourceData, m interface{}, kind, operation string) {
+	addWarning(m.(ProviderConfig).AuthContext, fmt.Sprintf("Not %s %s `%s` in Airflow", operation, kind, d.Id()),
+		fmt.Sprintf("manage is false, so the %s is only read and the planned change isn't applied.", kind))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// correlationIdHeader is sent with every API request so that failed
+// operations can be matched to the Airflow webserver access logs.
+const correlationIdHeader = "X-Correlation-ID"
+
+type correlationIdContextKey struct{}
+
+type operationWarningsContextKey struct{}
+
+type operationFunc func(*schema.ResourceData, interface{}) error
+
+// wrapOperations wraps the CRUD entry points of every resource and data
+// source of the provider with wrapOperation.
+func wrapOperations(p *schema.Provider) {
+	for name, r := range p.ResourcesMap {
+		wrapResourceOperations(name, r)
+		wrapRefreshSkipping(r)
+	}
+	for name, r := range p.DataSourcesMap {
+		wrapResourceOperations("data."+name, r)
+	}
+}
+
+// wrapResourceOperations serves the CRUD functions through their diagnostics
+// returning variants, so that warnings added with addWarning during an
+// operation are shown next to its error. API calls keep using the
+// AuthContext of the provider, not the context Terraform passes.
+func wrapResourceOperations(resourceType string, r *schema.Resource) {
+	if r.Create != nil {
+		r.CreateContext = schema.CreateContextFunc(withOperationWarnings(wrapOperation(resourceType, operationFunc(r.Create))))
+		r.Create = nil
+	}
+	if r.Read != nil {
+		r.ReadContext = schema.ReadContextFunc(withOperationWarnings(wrapOperation(resourceType, operationFunc(r.Read))))
+		r.Read = nil
+	}
+	if r.Update != nil {
+		r.UpdateContext = schema.UpdateContextFunc(withOperationWarnings(wrapOperation(resourceType, operationFunc(r.Update))))
+		r.Update = nil
+	}
+	if r.Delete != nil {
+		r.DeleteContext = schema.DeleteContextFunc(withOperationWarnings(wrapOperation(resourceType, operationFunc(r.Delete))))
+		r.Delete = nil
+	}
+}
+
+type operationDiagnosticsFunc func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+
+// withOperationWarnings returns the warnings added with addWarning during an
+// operation as diagnostics along with its error.
+func withOperationWarnings(f operationFunc) operationDiagnosticsFunc {
+	return func(_ context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		warnings := &operationWarnings{}
+		if pcfg, ok := m.(ProviderConfig); ok && pcfg.AuthContext != nil {
+			pcfg.AuthContext = context.WithValue(pcfg.AuthContext, operationWarningsContextKey{}, warnings)
+			m = pcfg
+		}
+
+		err := f(d, m)
+		return append(warnings.diagnostics(), diag.FromErr(err)...)
+	}
+}
+
+// operationWarnings collects the warnings of an operation. Bulk resources
+// run API calls concurrently, so it is safe for concurrent use.
+type operationWarnings struct {
+	mu    sync.Mutex
+	diags diag.Diagnostics
+}
+
+func (w *operationWarnings) diagnostics() diag.Diagnostics {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.diags
+}
+
+// addWarning returns a warning diagnostic from the operation of ctx. Outside
+// of an operation the warning is only logged.
+func addWarning(ctx context.Context, summary, detail string) {
+	log.Printf("[WARN] %s: %s", summary, detail)
+
+	w, ok := ctx.Value(operationWarningsContextKey{}).(*operationWarnings)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.diags = append(w.diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  summary,
+		Detail:   detail,
+	})
+}
+
+// wrapOperation assigns a correlation ID to a single CRUD operation. The ID
+// is attached to the context used for all API calls of the operation and is
+// included in the returned error, and the API calls are counted under the
+// resource type. With backend affinity, the operation also gets its own
+// cookie jar. Panics are recovered and returned as errors
+// so that a single unexpected API response doesn't crash the plugin process.
+func wrapOperation(resourceType string, f operationFunc) operationFunc {
+	return func(d *schema.ResourceData, m interface{}) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[ERROR] Recovered from panic: %v\n%s", r, debug.Stack())
+				err = fmt.Errorf("unexpected error while handling `%s`, this is a bug in the provider, please report it: %v", d.Id(), r)
+			}
+		}()
+
+		pcfg, ok := m.(ProviderConfig)
+		if !ok {
+			return f(d, m)
+		}
+
+		correlationId, err := uuid.GenerateUUID()
+		if err != nil {
+			return fmt.Errorf("failed to generate correlation ID: %w", err)
+		}
+		log.Printf("[DEBUG] Starting operation with correlation ID %s", correlationId)
+
+		pcfg.AuthContext = context.WithValue(pcfg.AuthContext, correlationIdContextKey{}, correlationId)
+		pcfg.AuthContext = withOperationResourceType(pcfg.AuthContext, resourceType)
+		if pcfg.BackendAffinity {
+			pcfg.AuthContext = withBackendAffinity(pcfg.AuthContext)
+		}
+
+		if err := f(d, pcfg); err != nil {
+			return fmt.Errorf("%w (correlation ID: %s)", err, correlationId)
+		}
+
+		return nil
+	}
+}
+
+// correlationIdTransport sets the correlation ID header on requests made
+// within a wrapped operation.
+type correlationIdTransport struct {
+	next http.RoundTripper
+}
+
+func (t *correlationIdTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id, ok := req.Context().Value(correlationIdContextKey{}).(string); ok {
+		req = req.Clone(req.Context())
+		req.Header.Set(correlationIdHeader, id)
+	}
+
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWrapOperation_correlationId(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var headers []string
+	fake.handle(http.MethodGet, "/variables/foo", func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get(correlationIdHeader))
+		writeFakeAirflowError(w, http.StatusInternalServerError, "boom")
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{})
+	d.SetId("foo")
+
+	err := wrapOperation("airflow_variable", resourceVariableRead)(d, m)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(headers) != 1 || headers[0] == "" {
+		t.Fatalf("expected a correlation ID header, got %v", headers)
+	}
+	if !strings.Contains(err.Error(), "correlation ID: "+headers[0]) {
+		t.Fatalf("expected error to contain correlation ID %s, got: %s", headers[0], err)
+	}
+}
+
+func TestWrapOperation_recoversPanic(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{})
+	d.SetId("foo")
+
+	err := wrapOperation("airflow_variable", func(d *schema.ResourceData, m interface{}) error {
+		var roles *[]string
+		_ = len(*roles)
+		return nil
+	})(d, m)
+	if err == nil {
+		t.Fatal("expected the panic to be converted to an error")
+	}
+	if !strings.Contains(err.Error(), "this is a bug in the provider") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestWrapResourceOperations_warnings(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	r := &schema.Resource{
+		Schema: map[string]*schema.Schema{},
+		Read: func(d *schema.ResourceData, m interface{}) error {
+			addWarning(m.(ProviderConfig).AuthContext, "Something to know", "It went fine anyway.")
+			return nil
+		},
+	}
+	wrapResourceOperations("airflow_test", r)
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{})
+	d.SetId("foo")
+	diags := r.ReadContext(context.Background(), d, m)
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "Something to know" {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+}
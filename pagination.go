@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// listPageSize is the Airflow API default maximum page size.
+const listPageSize = int32(100)
+
+// listPageRetries is how often a page is fetched again when it is empty
+// although Airflow reports more entries.
+const listPageRetries = 3
+
+var listPageRetryInterval = time.Second
+
+// orderBySchema is the order_by argument of list data sources. It is passed
+// to the API, which sorts the collection before paging through it, and the
+// data source keeps that order. A leading `-` sorts descending.
+func orderBySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringMatch(regexp.MustCompile(`^-?[A-Za-z_.]+$`), "must be an attribute name, optionally prefixed with `-`"),
+	}
+}
+
+// pageFunc fetches a single page of a collection and returns its items along
+// with the total_entries reported by Airflow.
+type pageFunc[T any] func(limit, offset int32) ([]T, int32, error)
+
+// fetchAllPages fetches every page of a collection into memory, see
+// forEachPage.
+func fetchAllPages[T any](collection string, key func(T) string, fetch pageFunc[T]) ([]T, error) {
+	var items []T
+	err := forEachPage(collection, key, fetch, func(item T) bool {
+		items = append(items, item)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// forEachPage calls fn for every item of a collection as its pages are
+// fetched, so large collections don't have to be kept in memory. Paging
+// stops as soon as fn returns false. key identifies an item so that items
+// aren't passed twice when the collection changes while it is paged through.
+//
+// Pages are advanced by the number of items returned, which may be less than
+// requested when the Airflow maximum_page_limit is lower. Some proxies return
+// empty or stale pages, which would make deleted objects out of existing
+// ones. So an empty page before total_entries are reached is fetched again,
+// and when it stays empty the listing fails instead of returning a partial
+// collection. A total_entries of 0 next to returned items, as is the case
+// when a proxy strips it, means the total is unknown and pages are fetched
+// until the first empty one. Paging also fails when a page contains no new
+// items, as is the case when the server ignores the offset, instead of
+// looping endlessly.
+func forEachPage[T any](collection string, key func(T) string, fetch pageFunc[T], fn func(T) bool) error {
+	seen := map[string]bool{}
+	totalKnown := true
+
+	offset := int32(0)
+	for retries := 0; ; {
+		page, total, err := fetch(listPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list %s from Airflow: %w", collection, err)
+		}
+		if total == 0 && len(page) > 0 {
+			totalKnown = false
+		}
+
+		added := 0
+		for _, item := range page {
+			k := key(item)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			added++
+
+			if !fn(item) {
+				return nil
+			}
+		}
+
+		if len(page) == 0 && totalKnown && int32(len(seen)) < total {
+			if retries < listPageRetries {
+				retries++
+				log.Printf("[WARN] Airflow reported %d %s but the page at offset %d is empty, retrying (%d/%d)", total, collection, offset, retries, listPageRetries)
+				time.Sleep(listPageRetryInterval)
+				continue
+			}
+			return fmt.Errorf("failed to list %s from Airflow: it reported %d entries but returned %d, refusing to use an incomplete list", collection, total, len(seen))
+		}
+		if len(page) == 0 || (totalKnown && int32(len(seen)) >= total) {
+			if totalKnown && int32(len(seen)) != total {
+				log.Printf("[WARN] Airflow reported %d %s but returned %d", total, collection, len(seen))
+			}
+			return nil
+		}
+		if added == 0 {
+			return fmt.Errorf("failed to list %s from Airflow: the page at offset %d contains no new entries", collection, offset)
+		}
+
+		retries = 0
+		offset += int32(len(page))
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testPages(pages map[int32][]string, total int32) pageFunc[string] {
+	return func(limit, offset int32) ([]string, int32, error) {
+		return pages[offset], total, nil
+	}
+}
+
+func testPage(prefix string, n int) []string {
+	page := make([]string, n)
+	for i := range page {
+		page[i] = fmt.Sprintf("%s-%d", prefix, i)
+	}
+	return page
+}
+
+func identity(s string) string { return s }
+
+func TestFetchAllPages(t *testing.T) {
+	listPageRetryInterval = 0
+
+	cases := map[string]struct {
+		pages   map[int32][]string
+		total   int32
+		want    int
+		wantErr string
+	}{
+		"single page": {
+			pages: map[int32][]string{0: testPage("a", 3)},
+			total: 3,
+			want:  3,
+		},
+		"multiple pages": {
+			pages: map[int32][]string{0: testPage("a", 100), 100: testPage("b", 100), 200: testPage("c", 5)},
+			total: 205,
+			want:  205,
+		},
+		"lower server page limit": {
+			pages: map[int32][]string{0: testPage("a", 50), 50: testPage("b", 50), 100: testPage("c", 10)},
+			total: 110,
+			want:  110,
+		},
+		"total overcounted": {
+			pages:   map[int32][]string{0: testPage("a", 100), 100: testPage("b", 20)},
+			total:   500,
+			wantErr: "reported 500 entries but returned 120",
+		},
+		"total stripped": {
+			pages: map[int32][]string{0: testPage("a", 100), 100: testPage("b", 20)},
+			total: 0,
+			want:  120,
+		},
+		"total undercounted": {
+			pages: map[int32][]string{0: testPage("a", 100), 100: testPage("b", 100)},
+			total: 150,
+			want:  200,
+		},
+		"entry shifted between pages": {
+			// An entry of the first page was deleted, which Airflow
+			// reflects in total_entries of the second page already.
+			pages: map[int32][]string{0: testPage("a", 100), 100: append([]string{"a-99"}, testPage("b", 9)...)},
+			total: 109,
+			want:  109,
+		},
+		"offset ignored": {
+			pages:   map[int32][]string{0: testPage("a", 100), 100: testPage("a", 100), 200: testPage("a", 100)},
+			total:   300,
+			wantErr: "contains no new entries",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			items, err := fetchAllPages("things", identity, testPages(tc.pages, tc.total))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != tc.want {
+				t.Fatalf("expected %d items, got %d", tc.want, len(items))
+			}
+		})
+	}
+}
+
+func TestFetchAllPages_error(t *testing.T) {
+	_, err := fetchAllPages("things", identity, func(limit, offset int32) ([]string, int32, error) {
+		return nil, 0, fmt.Errorf("boom")
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to list things from Airflow: boom") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestForEachPage_shortCircuit(t *testing.T) {
+	calls := 0
+	fetch := func(limit, offset int32) ([]string, int32, error) {
+		calls++
+		return testPage(fmt.Sprintf("page-%d", offset), int(limit)), 100000, nil
+	}
+
+	var found string
+	err := forEachPage("things", identity, fetch, func(item string) bool {
+		if item == "page-200-5" {
+			found = item
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == "" {
+		t.Fatal("expected the item to be found")
+	}
+	if calls != 3 {
+		t.Fatalf("expected paging to stop after 3 pages, got %d", calls)
+	}
+}
+
+func TestForEachPage_retriesEmptyPage(t *testing.T) {
+	listPageRetryInterval = 0
+
+	calls := 0
+	items, err := fetchAllPages("things", identity, func(limit, offset int32) ([]string, int32, error) {
+		calls++
+		if offset == 100 && calls < 4 {
+			return nil, 120, nil
+		}
+		return testPages(map[int32][]string{0: testPage("a", 100), 100: testPage("b", 20)}, 120)(limit, offset)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 120 || calls != 4 {
+		t.Fatalf("expected 120 items after 2 retries, got %d items in %d calls", len(items), calls)
+	}
+}
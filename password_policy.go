@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// passwordPolicy is the provider password_policy the passwords of users must
+// meet before they are sent to Airflow.
+type passwordPolicy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	DenyList         []string
+}
+
+func passwordPolicySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Requirements the passwords of airflow_user and airflow_users must meet, checked during plan",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"min_length": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"require_uppercase": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"require_lowercase": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"require_digit": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"require_symbol": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"deny_list": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func expandPasswordPolicy(tfList []interface{}) *passwordPolicy {
+	if len(tfList) == 0 || tfList[0] == nil {
+		return nil
+	}
+
+	tfMap := tfList[0].(map[string]interface{})
+	return &passwordPolicy{
+		MinLength:        tfMap["min_length"].(int),
+		RequireUppercase: tfMap["require_uppercase"].(bool),
+		RequireLowercase: tfMap["require_lowercase"].(bool),
+		RequireDigit:     tfMap["require_digit"].(bool),
+		RequireSymbol:    tfMap["require_symbol"].(bool),
+		DenyList:         expandStringSet(tfMap["deny_list"].(*schema.Set)),
+	}
+}
+
+// checkPassword returns an error listing every requirement of the provider
+// password_policy a password of a user doesn't meet. Empty passwords aren't
+// sent to Airflow and always pass. The password itself is never included.
+func checkPassword(m interface{}, user, password string) error {
+	p := m.(ProviderConfig).PasswordPolicy
+	if p == nil || password == "" {
+		return nil
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var violations []string
+	if n := len([]rune(password)); n < p.MinLength {
+		violations = append(violations, fmt.Sprintf("be at least %d characters long", p.MinLength))
+	}
+	if p.RequireUppercase && !upper {
+		violations = append(violations, "contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		violations = append(violations, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "contain a symbol")
+	}
+	for _, denied := range p.DenyList {
+		if strings.EqualFold(password, denied) {
+			violations = append(violations, "not be on the deny_list")
+			break
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the password of user `%s` doesn't meet the provider password_policy, it must %s", user, strings.Join(violations, ", "))
+}
+
+// customizeUserPasswordPolicyDiff checks the password of an airflow_user
+// against the provider password_policy when it is sent, i.e. on create and
+// when it changes. Passwords that are unknown during plan are checked
+// during apply.
+func customizeUserPasswordPolicyDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && !d.HasChanges("password", "password_wo_version") {
+		return nil
+	}
+
+	for _, key := range []string{"password", "password_wo"} {
+		if err := checkPassword(m, d.Get("email").(string), configString(d, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customizeUsersPasswordPolicyDiff checks the passwords of the users of an
+// airflow_users that are new or changed against the password_policy.
+func customizeUsersPasswordPolicyDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("user") {
+		return nil
+	}
+
+	o, n := d.GetChange("user")
+	old := map[string]string{}
+	for _, v := range o.([]interface{}) {
+		tfMap := v.(map[string]interface{})
+		old[tfMap["email"].(string)] = tfMap["password"].(string)
+	}
+
+	for i, v := range n.([]interface{}) {
+		if !d.NewValueKnown(fmt.Sprintf("user.%d.password", i)) {
+			continue
+		}
+
+		tfMap := v.(map[string]interface{})
+		email, password := tfMap["email"].(string), tfMap["password"].(string)
+		if previous, exists := old[email]; exists && previous == password {
+			continue
+		}
+		if err := checkPassword(m, email, password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatedPasswordClasses are the characters generated passwords are drawn
+// from. Every password contains at least one character of each class.
+var generatedPasswordClasses = []string{
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"abcdefghijkmnopqrstuvwxyz",
+	"23456789",
+	"!#%+-.:=?@^_~",
+}
+
+// generatedPasswordLength is the length of generated passwords, unless the
+// password_policy requires longer ones.
+const generatedPasswordLength = 32
+
+// generateUserPassword returns a random password for generate_password that
+// meets the provider password_policy.
+func generateUserPassword(m interface{}) (string, error) {
+	length := generatedPasswordLength
+	if p := m.(ProviderConfig).PasswordPolicy; p != nil && p.MinLength > length {
+		length = p.MinLength
+	}
+
+	randomIndex := func(n int) (int, error) {
+		i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate password: %w", err)
+		}
+		return int(i.Int64()), nil
+	}
+
+	all := strings.Join(generatedPasswordClasses, "")
+	password := make([]byte, length)
+	for i := range password {
+		chars := all
+		if i < len(generatedPasswordClasses) {
+			chars = generatedPasswordClasses[i]
+		}
+		j, err := randomIndex(len(chars))
+		if err != nil {
+			return "", err
+		}
+		password[i] = chars[j]
+	}
+
+	// Shuffle so that the required classes aren't always at the start.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+// customizeUserGeneratedPasswordDiff plans a new generated_password when
+// generate_password is toggled, or when the rotation triggers of a user
+// with a generated password change.
+func customizeUserGeneratedPasswordDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	if d.HasChange("generate_password") || (d.Get("generate_password").(bool) && d.HasChange("rotation_triggers")) {
+		return d.SetNewComputed("generated_password")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCheckPassword(t *testing.T) {
+	m := ProviderConfig{PasswordPolicy: &passwordPolicy{
+		MinLength:        12,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		DenyList:         []string{"Correct-Horse-Battery-1"},
+	}}
+
+	cases := map[string]string{
+		"":                        "",
+		"Sufficiently-long-1":     "",
+		"short":                   "be at least 12 characters long, contain an uppercase letter, contain a digit, contain a symbol",
+		"ALLUPPERCASE-123":        "contain a lowercase letter",
+		"correct-horse-battery-1": "contain an uppercase letter, not be on the deny_list",
+	}
+	for password, expected := range cases {
+		err := checkPassword(m, "user@example.com", password)
+		if expected == "" {
+			if err != nil {
+				t.Fatalf("expected %q to pass, got %s", password, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasSuffix(err.Error(), "it must "+expected) {
+			t.Fatalf("expected %q to fail with %q, got %v", password, expected, err)
+		}
+		if strings.Contains(err.Error(), password) {
+			t.Fatalf("expected the error not to include the password, got %s", err)
+		}
+	}
+
+	if err := checkPassword(ProviderConfig{}, "user@example.com", "weak"); err != nil {
+		t.Fatalf("expected no policy to pass every password, got %s", err)
+	}
+}
+
+func TestResourceUser_passwordPolicyPlan(t *testing.T) {
+	m := ProviderConfig{PasswordPolicy: &passwordPolicy{MinLength: 12}}
+
+	raw := map[string]interface{}{
+		"email":      "user@example.com",
+		"username":   "user",
+		"first_name": "first",
+		"last_name":  "last",
+		"roles":      []interface{}{"Viewer"},
+		"password":   "weak",
+	}
+	_, err := resourceUser().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), m)
+	if err == nil || !strings.Contains(err.Error(), "at least 12 characters") {
+		t.Fatalf("expected the plan to reject the weak password, got %v", err)
+	}
+
+	raw["password"] = "long-enough-password"
+	if _, err := resourceUser().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), m); err != nil {
+		t.Fatalf("expected the plan to accept the password, got %s", err)
+	}
+
+	raw = map[string]interface{}{
+		"user": []interface{}{map[string]interface{}{
+			"email":      "user@example.com",
+			"username":   "user",
+			"first_name": "first",
+			"last_name":  "last",
+			"roles":      []interface{}{"Viewer"},
+			"password":   "weak",
+		}},
+	}
+	_, err = resourceUsers().Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), m)
+	if err == nil || !strings.Contains(err.Error(), "user `user@example.com`") {
+		t.Fatalf("expected the plan of airflow_users to reject the weak password, got %v", err)
+	}
+}
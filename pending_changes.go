@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// pendingChangesSchema is the preview a bulk resource plans of the objects
+// the apply creates, updates and deletes, so that mass changes can be
+// reviewed before applying them. It is empty in state.
+func pendingChangesSchema() *schema.Schema {
+	keys := &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"create": keys,
+				"update": keys,
+				"delete": keys,
+			},
+		},
+	}
+}
+
+// customizePendingChangesDiff plans pending_changes from the change of the
+// objects in block, which are matched by key and compared with equal like
+// the update does, and from the unmanaged objects the apply deletes. The
+// keys of unknown objects and of the objects under a changed
+// authoritative_prefix can't be previewed.
+func customizePendingChangesDiff[T any](block, keyAttr string, expand func([]interface{}) ([]T, error), key func(T) string, equal func(a, b T) bool) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		unmanaged := d.Get("unmanaged").([]interface{})
+		if len(unmanaged) > 0 || d.HasChange("authoritative_prefix") {
+			if err := d.SetNewComputed("unmanaged"); err != nil {
+				return err
+			}
+			if d.HasChange("authoritative_prefix") {
+				return d.SetNewComputed("pending_changes")
+			}
+		}
+		if !d.HasChange(block) && len(unmanaged) == 0 {
+			return nil
+		}
+		if !d.NewValueKnown(block) {
+			return d.SetNewComputed("pending_changes")
+		}
+		for i := 0; i < d.Get(block+".#").(int); i++ {
+			if !d.NewValueKnown(fmt.Sprintf("%s.%d.%s", block, i, keyAttr)) {
+				return d.SetNewComputed("pending_changes")
+			}
+		}
+
+		o, n := d.GetChange(block)
+		oldObjects, err := expand(o.([]interface{}))
+		if err != nil {
+			return err
+		}
+		newObjects, err := expand(n.([]interface{}))
+		if err != nil {
+			return err
+		}
+
+		changes := diffBulkObjects(oldObjects, newObjects, key, equal)
+
+		// Unmanaged objects that were added to the configuration are kept.
+		configured := make(map[string]bool, len(newObjects))
+		for _, o := range newObjects {
+			configured[key(o)] = true
+		}
+		remove := changes["delete"].([]string)
+		for _, v := range unmanaged {
+			if !configured[v.(string)] {
+				remove = append(remove, v.(string))
+			}
+		}
+		sort.Strings(remove)
+		changes["delete"] = remove
+
+		return d.SetNew("pending_changes", []interface{}{changes})
+	}
+}
+
+// diffBulkObjects returns the sorted keys of the objects that are created,
+// updated and deleted when changing from before to after.
+func diffBulkObjects[T any](before, after []T, key func(T) string, equal func(a, b T) bool) map[string]interface{} {
+	oldByKey := make(map[string]T, len(before))
+	for _, o := range before {
+		oldByKey[key(o)] = o
+	}
+
+	create, update := []string{}, []string{}
+	for _, n := range after {
+		k := key(n)
+		o, exists := oldByKey[k]
+		delete(oldByKey, k)
+
+		if !exists {
+			create = append(create, k)
+		} else if !equal(o, n) {
+			update = append(update, k)
+		}
+	}
+
+	remove := make([]string, 0, len(oldByKey))
+	for k := range oldByKey {
+		remove = append(remove, k)
+	}
+
+	sort.Strings(create)
+	sort.Strings(update)
+	sort.Strings(remove)
+
+	return map[string]interface{}{
+		"create": create,
+		"update": update,
+		"delete": remove,
+	}
+}
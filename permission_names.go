@@ -0,0 +1,177 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/apache/airflow-client-go/airflow"
+	goversion "github.com/hashicorp/go-version"
+)
+
+// airflowPermissionRename is a permission action or resource that Airflow
+// renamed in a version.
+type airflowPermissionRename struct {
+	resource bool
+	old      string
+	new      string
+	since    *goversion.Version
+}
+
+// airflowPermissionRenames translates permissions between Airflow versions,
+// so roles can be configured with the names of any of them. Names are sent to
+// Airflow as the version of the server knows them and compared by their
+// newest names.
+var airflowPermissionRenames = []airflowPermissionRename{
+	{old: "can_dag_read", new: "can_read", since: goversion.Must(goversion.NewVersion("2.0.0"))},
+	{old: "can_dag_edit", new: "can_edit", since: goversion.Must(goversion.NewVersion("2.0.0"))},
+	{resource: true, old: "all_dags", new: "DAGs", since: goversion.Must(goversion.NewVersion("2.0.0"))},
+	{resource: true, old: "Datasets", new: "Assets", since: goversion.Must(goversion.NewVersion("3.0.0"))},
+	{resource: true, old: "Dataset Aliases", new: "Asset Aliases", since: goversion.Must(goversion.NewVersion("3.0.0"))},
+}
+
+// rename returns name renamed from `from` to `to` if it matches.
+func (r airflowPermissionRename) rename(name, from, to string) string {
+	if name == from {
+		return to
+	}
+	return name
+}
+
+// canonicalAirflowPermission returns the newest names of a permission.
+func canonicalAirflowPermission(action, resource string) (string, string) {
+	for _, r := range airflowPermissionRenames {
+		if r.resource {
+			resource = r.rename(resource, r.old, r.new)
+		} else {
+			action = r.rename(action, r.old, r.new)
+		}
+	}
+
+	return action, resource
+}
+
+// translateAirflowPermission returns the names of a permission as known by
+// the given Airflow version.
+func translateAirflowPermission(action, resource string, version *goversion.Version) (string, string) {
+	action, resource = canonicalAirflowPermission(action, resource)
+	for _, r := range airflowPermissionRenames {
+		if !version.LessThan(r.since) {
+			continue
+		}
+		if r.resource {
+			resource = r.rename(resource, r.new, r.old)
+		} else {
+			action = r.rename(action, r.new, r.old)
+		}
+	}
+
+	return action, resource
+}
+
+// translateAirflowRoleActions returns permissions with the names the Airflow
+// server knows them by. They are returned unchanged when its version can't
+// be determined.
+func translateAirflowRoleActions(pcfg ProviderConfig, apiObjects []airflow.ActionResource) []airflow.ActionResource {
+	version := pcfg.airflowVersion()
+	if version == nil {
+		return apiObjects
+	}
+
+	translated := make([]airflow.ActionResource, 0, len(apiObjects))
+	for _, apiObject := range apiObjects {
+		action, resource := translateAirflowPermission(apiObject.Action.GetName(), apiObject.Resource.GetName(), version)
+		translated = append(translated, airflow.ActionResource{
+			Action:   &airflow.Action{Name: &action},
+			Resource: &airflow.Resource{Name: &resource},
+		})
+	}
+
+	return translated
+}
+
+// spellAirflowRoleActionsAs returns the permissions read from Airflow with
+// the names of the equivalent configured permissions, so that a role
+// configured with the names of another Airflow version doesn't show drift.
+func spellAirflowRoleActionsAs(apiObjects, configured []airflow.ActionResource) []airflow.ActionResource {
+	spelling := make(map[string]airflow.ActionResource, len(configured))
+	for _, apiObject := range configured {
+		action, resource := canonicalAirflowPermission(apiObject.Action.GetName(), apiObject.Resource.GetName())
+		spelling[action+" on "+resource] = apiObject
+	}
+
+	spelled := make([]airflow.ActionResource, 0, len(apiObjects))
+	for _, apiObject := range apiObjects {
+		action, resource := canonicalAirflowPermission(apiObject.Action.GetName(), apiObject.Resource.GetName())
+		if v, ok := spelling[action+" on "+resource]; ok {
+			apiObject = v
+		}
+		spelled = append(spelled, apiObject)
+	}
+
+	return spelled
+}
+
+// airflowVersionCache holds the version of the Airflow server, which is
+// fetched once per provider configuration. Failures aren't cached, so that a
+// transient failure doesn't hide the version for the rest of the run.
+type airflowVersionCache struct {
+	mu      sync.Mutex
+	version *goversion.Version
+}
+
+// airflowVersion returns the version of the Airflow server, or nil if it
+// can't be determined.
+func (pcfg ProviderConfig) airflowVersion() *goversion.Version {
+	if pcfg.versionCache == nil {
+		return fetchAirflowVersion(pcfg)
+	}
+
+	pcfg.versionCache.mu.Lock()
+	defer pcfg.versionCache.mu.Unlock()
+
+	if pcfg.versionCache.version == nil {
+		pcfg.versionCache.version = fetchAirflowVersion(pcfg)
+	}
+	return pcfg.versionCache.version
+}
+
+// isAirflow3 reports whether the Airflow server is known to be Airflow 3 or
+// later, which only serves its API as v2.
+func (pcfg ProviderConfig) isAirflow3() bool {
+	version := pcfg.airflowVersion()
+	return version != nil && !version.LessThan(airflow3)
+}
+
+func fetchAirflowVersion(pcfg ProviderConfig) *goversion.Version {
+	info, _, err := getAirflowVersionInfo(pcfg)
+	if err != nil {
+		log.Printf("[WARN] Failed to get the Airflow version, permission names are sent as configured: %s", err)
+		return nil
+	}
+
+	version, err := goversion.NewVersion(info.GetVersion())
+	if err != nil {
+		log.Printf("[WARN] Failed to parse the Airflow version `%s`, permission names are sent as configured: %s", info.GetVersion(), err)
+		return nil
+	}
+
+	return version
+}
+
+// getAirflowVersionInfo returns the version of the Airflow server from the
+// stable API or, when that fails, from the v2 API, which is the only one
+// Airflow 3 serves.
+func getAirflowVersionInfo(pcfg ProviderConfig) (airflow.VersionInfo, *http.Response, error) {
+	info, resp, err := pcfg.ApiClient.MonitoringApi.GetVersion(pcfg.AuthContext).Execute()
+	if err == nil {
+		return info, resp, nil
+	}
+
+	var v2 airflow.VersionInfo
+	if v2Resp, v2Err := uiRequest(pcfg, http.MethodGet, "/api/v2/version", nil, &v2); v2Err == nil {
+		return v2, v2Resp, nil
+	}
+
+	return info, resp, err
+}
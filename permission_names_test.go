@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	goversion "github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestTranslateAirflowPermission(t *testing.T) {
+	cases := []struct {
+		version, action, resource string
+		wantAction, wantResource  string
+	}{
+		{"2.9.3", "can_read", "Assets", "can_read", "Datasets"},
+		{"2.9.3", "can_read", "Datasets", "can_read", "Datasets"},
+		{"3.0.2", "can_read", "Datasets", "can_read", "Assets"},
+		{"3.0.2", "menu_access", "Asset Aliases", "menu_access", "Asset Aliases"},
+		{"2.6.0", "can_dag_read", "all_dags", "can_read", "DAGs"},
+		{"2.6.0", "can_edit", "DAG:example", "can_edit", "DAG:example"},
+	}
+
+	for _, c := range cases {
+		action, resource := translateAirflowPermission(c.action, c.resource, goversion.Must(goversion.NewVersion(c.version)))
+		if action != c.wantAction || resource != c.wantResource {
+			t.Errorf("%s on %s for %s: expected %s on %s, got %s on %s", c.action, c.resource, c.version, c.wantAction, c.wantResource, action, resource)
+		}
+	}
+}
+
+func TestResourceRole_fakePermissionNames(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": "2.9.3"})
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceRole().Schema, map[string]interface{}{
+		"name": "assets",
+		"action": []interface{}{
+			map[string]interface{}{"action": "can_read", "resource": "Assets"},
+		},
+	})
+	if err := resourceRoleCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	actions := fake.object("roles", "assets")["actions"].([]interface{})
+	resource := actions[0].(map[string]interface{})["resource"].(map[string]interface{})["name"]
+	if resource != "Datasets" {
+		t.Fatalf("expected the resource to be sent as Datasets to Airflow 2, got %v", resource)
+	}
+
+	action := d.Get("action").(*schema.Set).List()[0].(map[string]interface{})
+	if action["resource"] != "Assets" {
+		t.Fatalf("expected the configured name to be kept in state, got %v", action["resource"])
+	}
+	if got := fake.requestCount(http.MethodGet, "/version"); got != 1 {
+		t.Fatalf("expected the version to be fetched once, got %d", got)
+	}
+}
+
+func TestAirflowVersion_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	// A failure isn't cached, so the version is found by the next call.
+	fake.failNext(http.MethodGet, "/version", http.StatusServiceUnavailable, 1)
+	fake.failNext(http.MethodGet, "/api/v2/version", http.StatusServiceUnavailable, 1)
+	if version := m.airflowVersion(); version != nil {
+		t.Fatalf("expected no version while the server fails, got %s", version)
+	}
+
+	fake.playAirflow3("3.0.2")
+	if !m.isAirflow3() {
+		t.Fatal("expected Airflow 3 to be detected through the v2 API")
+	}
+	m.isAirflow3()
+	if got := fake.requestCount(http.MethodGet, "/api/v2/version"); got != 2 {
+		t.Fatalf("expected the version to be cached once it was fetched, got %d requests", got)
+	}
+}
@@ -4,35 +4,154 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/apache/airflow-client-go/airflow"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 type ProviderConfig struct {
 	ApiClient   *airflow.APIClient
 	AuthContext context.Context
+	Metrics     *apiMetrics
+	BaseUrl     string
+
+	SensitiveStateMode   string
+	DefaultUserRoles     []string
+	UserLockoutThreshold int
+	PasswordPolicy       *passwordPolicy
+
+	ConnectionDefaults []connectionDefaults
+	VariableKeyPrefix  string
+	BackendAffinity    bool
+
+	DagNotFoundRetryTimeout    time.Duration
+	SkipRefreshWhenUnreachable bool
+	BulkParallelism            int
+
+	versionCache  *airflowVersionCache
+	readFallbacks *readFallbackCache
 }
 
 func AirflowProvider() *schema.Provider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"base_endpoint": {
 				Type:         schema.TypeString,
-				Required:     true,
+				Optional:     true,
 				DefaultFunc:  schema.EnvDefaultFunc("AIRFLOW_BASE_ENDPOINT", nil),
 				ValidateFunc: validation.IsURLWithHTTPorHTTPS,
 			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("AIRFLOW_TLS_SERVER_NAME", nil),
+				Description: "The hostname the TLS certificate of Airflow is verified against and that is sent for SNI, instead of the host of base_endpoint",
+			},
 			"oauth2_token": {
 				Type:          schema.TypeString,
 				Optional:      true,
 				Sensitive:     true,
 				Description:   "The oauth to use for API authentication",
 				DefaultFunc:   schema.EnvDefaultFunc("AIRFLOW_OAUTH2_TOKEN", nil),
-				ConflictsWith: []string{"username", "password"},
+				ConflictsWith: []string{"username", "password", "token_endpoint"},
+			},
+			"token_endpoint": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The token endpoint of an OAuth2 authorization server to get access tokens from with the client credentials flow",
+				DefaultFunc:   schema.EnvDefaultFunc("AIRFLOW_OAUTH2_TOKEN_ENDPOINT", nil),
+				ValidateFunc:  validation.IsURLWithHTTPorHTTPS,
+				RequiredWith:  []string{"client_id", "client_secret"},
+				ConflictsWith: []string{"username", "password", "oauth2_token"},
+			},
+			"client_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The client ID of the OAuth2 client credentials flow",
+				DefaultFunc:  schema.EnvDefaultFunc("AIRFLOW_OAUTH2_CLIENT_ID", nil),
+				RequiredWith: []string{"token_endpoint", "client_secret"},
+			},
+			"client_secret": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "The client secret of the OAuth2 client credentials flow",
+				DefaultFunc:  schema.EnvDefaultFunc("AIRFLOW_OAUTH2_CLIENT_SECRET", nil),
+				RequiredWith: []string{"token_endpoint", "client_id"},
+			},
+			"scopes": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Description:  "The scopes requested with the OAuth2 client credentials flow",
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				RequiredWith: []string{"token_endpoint"},
+			},
+			"mwaa_environment_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "The name of an Amazon MWAA environment to authenticate against with AWS credentials",
+				DefaultFunc:   schema.EnvDefaultFunc("AIRFLOW_MWAA_ENVIRONMENT_NAME", nil),
+				RequiredWith:  []string{"aws_region"},
+				ConflictsWith: []string{"username", "password", "oauth2_token", "token_endpoint"},
+			},
+			"aws_region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The AWS region of the MWAA environment",
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"AWS_REGION", "AWS_DEFAULT_REGION"}, nil),
+			},
+			"aws_access_key_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The AWS access key ID to create MWAA web login tokens with",
+				DefaultFunc: schema.EnvDefaultFunc("AWS_ACCESS_KEY_ID", nil),
+			},
+			"aws_secret_access_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The AWS secret access key to create MWAA web login tokens with",
+				DefaultFunc: schema.EnvDefaultFunc("AWS_SECRET_ACCESS_KEY", nil),
+			},
+			"aws_session_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "The AWS session token of temporary credentials",
+				DefaultFunc: schema.EnvDefaultFunc("AWS_SESSION_TOKEN", nil),
+			},
+			"google_auth": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Whether to authenticate against Cloud Composer with Google `access_token`s, or with `id_token`s for google_iap_audience",
+				ValidateFunc:  validation.StringInSlice(googleAuthModes, false),
+				ConflictsWith: []string{"username", "password", "oauth2_token", "token_endpoint", "mwaa_environment_name"},
+			},
+			"google_credentials": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				Description:  "The JSON of Google credentials, e.g. a service account key, instead of the application default credentials",
+				DefaultFunc:  schema.EnvDefaultFunc("GOOGLE_CREDENTIALS", nil),
+				ValidateFunc: validation.StringIsJSON,
+			},
+			"google_iap_audience": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  "The audience of the ID tokens, the OAuth client ID of the Identity-Aware Proxy in front of Airflow",
+				RequiredWith: []string{"google_auth"},
+			},
+			"headers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Headers that are sent with every API call, e.g. for a bearer token setup not covered by the other arguments",
+				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
 			"username": {
 				Type:          schema.TypeString,
@@ -40,7 +159,7 @@ func AirflowProvider() *schema.Provider {
 				Optional:      true,
 				Description:   "The username to use for API basic authentication",
 				RequiredWith:  []string{"password"},
-				ConflictsWith: []string{"oauth2_token"},
+				ConflictsWith: []string{"oauth2_token", "token_endpoint"},
 			},
 			"password": {
 				Type:          schema.TypeString,
@@ -49,24 +168,213 @@ func AirflowProvider() *schema.Provider {
 				Sensitive:     true,
 				Description:   "The password to use for API basic authentication",
 				RequiredWith:  []string{"username"},
-				ConflictsWith: []string{"oauth2_token"},
+				ConflictsWith: []string{"oauth2_token", "token_endpoint"},
+			},
+			"default_user_roles": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Roles that are added to the roles of every airflow_user",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"user_lockout_threshold": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				Description:  "The number of failed logins in a row after which users count as locked",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"password_policy": passwordPolicySchema(),
+			"connection_defaults": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Extra fields that are merged into the extra of every airflow_connection",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"conn_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"extra": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsJSON,
+						},
+					},
+				},
+			},
+			"variable_key_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A prefix that is added to the keys of every airflow_variable",
+			},
+			"backend_affinity": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to pin the API calls of each resource operation to a single webserver backend with the sticky session cookies of the load balancer",
+			},
+			"backend_affinity_header": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A header that is set to the correlation ID of the operation when backend_affinity is enabled, for load balancers that pin requests by hashing a header",
+			},
+			"skip_refresh_when_unreachable": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to keep the last known state of resources with a warning instead of failing the refresh when the Airflow webserver is unreachable",
+			},
+			"wait_for_healthy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to wait until the metadatabase and scheduler of Airflow report healthy before any API call",
+			},
+			"wait_for_healthy_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10m",
+				Description:  "How long to wait for Airflow to become healthy, e.g. `10m`",
+				ValidateFunc: validateDuration,
+			},
+			"dag_not_found_retry_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "0s",
+				Description:  "How long to retry calls to DAG scoped endpoints that fail because the DAG wasn't found, e.g. `5m` while Cloud Composer or MWAA sync the DAG folder",
+				ValidateFunc: validateDuration,
+			},
+			"circuit_breaker_threshold": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				Description:  "The number of consecutive failed API calls after which further calls are rejected for a while, or `0` to never reject calls",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				Description:  "The number of times API calls that were throttled with a 429, or failed with a 502, 503 or 504 or a dropped connection, are retried, or `0` to never retry",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_min_delay": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1s",
+				Description:  "How long to wait before the first retry of an API call, doubled for every further retry, e.g. `1s`",
+				ValidateFunc: validateDuration,
+			},
+			"retry_max_delay": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30s",
+				Description:  "The longest wait between retries of an API call, including waits requested with a Retry-After header, e.g. `30s`",
+				ValidateFunc: validateDuration,
+			},
+			"bulk_parallelism": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      8,
+				Description:  "The maximum number of concurrent API calls airflow_users, airflow_pools and airflow_roles make to reconcile their objects",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"sensitive_state_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      sensitiveStatePlain,
+				Description:  "How secret values such as passwords, connection extras and variable values are stored in state: `plain`, `hash` or `omit`",
+				ValidateFunc: validation.StringInSlice(sensitiveStateModes, false),
 			},
 		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"airflow_api":                       dataSourceApi(),
+			"airflow_assert_dag_paused":         dataSourceAssertDagPaused(),
+			"airflow_assert_no_import_errors":   dataSourceAssertNoImportErrors(),
+			"airflow_connection":                dataSourceConnection(),
+			"airflow_dag_run":                   dataSourceDagRun(),
+			"airflow_dag_stats":                 dataSourceDagStats(),
+			"airflow_dag_version":               dataSourceDagVersion(),
+			"airflow_dags":                      dataSourceDags(),
+			"airflow_last_event":                dataSourceLastEvent(),
+			"airflow_ping":                      dataSourcePing(),
+			"airflow_pool":                      dataSourcePool(),
+			"airflow_pools":                     dataSourcePools(),
+			"airflow_provider_connection_types": dataSourceProviderConnectionTypes(),
+			"airflow_queued_dataset_events":     dataSourceQueuedDatasetEvents(),
+			"airflow_role":                      dataSourceRole(),
+			"airflow_role_permissions":          dataSourceRolePermissions(),
+			"airflow_roles":                     dataSourceRoles(),
+			"airflow_scheduler_status":          dataSourceSchedulerStatus(),
+			"airflow_secrets_backend":           dataSourceSecretsBackend(),
+			"airflow_task_instance_links":       dataSourceTaskInstanceLinks(),
+			"airflow_task_instance_log":         dataSourceTaskInstanceLog(),
+			"airflow_triggerer_status":          dataSourceTriggererStatus(),
+			"airflow_unmanaged_users":           dataSourceUnmanagedUsers(),
+			"airflow_user":                      dataSourceUser(),
+			"airflow_user_bulk_import":          dataSourceUserBulkImport(),
+			"airflow_users":                     dataSourceUsers(),
+			"airflow_variable":                  dataSourceVariable(),
+			"airflow_xcom":                      dataSourceXcom(),
+		},
 		ResourcesMap: map[string]*schema.Resource{
-			"airflow_connection": resourceConnection(),
-			"airflow_dag":        resourceDag(),
-			"airflow_dag_run":    resourceDagRun(),
-			"airflow_variable":   resourceVariable(),
-			"airflow_pool":       resourcePool(),
-			"airflow_role":       resourceRole(),
-			"airflow_user":       resourceUser(),
+			"airflow_api_resource":      resourceApiResource(),
+			"airflow_connection":        resourceConnection(),
+			"airflow_dag":               resourceDag(),
+			"airflow_dag_run":           resourceDagRun(),
+			"airflow_dag_run_retention": resourceDagRunRetention(),
+			"airflow_environment_check": resourceEnvironmentCheck(),
+			"airflow_variable":          resourceVariable(),
+			"airflow_variables":         resourceVariables(),
+			"airflow_pool":              resourcePool(),
+			"airflow_pools":             resourcePools(),
+			"airflow_role":              resourceRole(),
+			"airflow_roles":             resourceRoles(),
+			"airflow_user":              resourceUser(),
+			"airflow_users":             resourceUsers(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
+
+	for _, name := range []string{"airflow_connection", "airflow_dag", "airflow_dag_run", "airflow_pool", "airflow_role", "airflow_user", "airflow_variable"} {
+		wrapApiPathOverrides(provider.ResourcesMap[name])
+	}
+	wrapOperations(provider)
+	wrapSelfManagementWarning(provider.ResourcesMap["airflow_user"])
+
+	return provider
 }
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	endpoint := d.Get("base_endpoint").(string)
+
+	var mwaa *mwaaSession
+	if v, ok := d.GetOk("mwaa_environment_name"); ok {
+		log.Printf("[DEBUG] Using API MWAA web login")
+
+		credentials := awsCredentials{
+			AccessKeyId:     d.Get("aws_access_key_id").(string),
+			SecretAccessKey: d.Get("aws_secret_access_key").(string),
+			SessionToken:    d.Get("aws_session_token").(string),
+		}
+		if credentials.AccessKeyId == "" || credentials.SecretAccessKey == "" {
+			return nil, fmt.Errorf("found mwaa_environment_name, but no AWS credentials: set aws_access_key_id and aws_secret_access_key or the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables")
+		}
+		mwaa = newMwaaSession(v.(string), d.Get("aws_region").(string), endpoint, credentials)
+
+		// Without a base_endpoint, the webserver of the environment is used.
+		hostname, err := mwaa.login()
+		if err != nil {
+			return nil, err
+		}
+		if endpoint == "" {
+			endpoint = "https://" + hostname
+		}
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("base_endpoint must be set unless mwaa_environment_name is")
+	}
+
 	u, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("invalid base_endpoint: %w", err)
@@ -77,6 +385,38 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		authCtx = context.WithValue(authCtx, airflow.ContextAccessToken, v)
 	}
 
+	if v, ok := d.GetOk("token_endpoint"); ok {
+		log.Printf("[DEBUG] Using API OAuth2 client credentials")
+
+		var scopes []string
+		for _, s := range d.Get("scopes").([]interface{}) {
+			scopes = append(scopes, s.(string))
+		}
+		// The token source caches the access token and gets a new one
+		// shortly before it expires.
+		cred := &clientcredentials.Config{
+			ClientID:     d.Get("client_id").(string),
+			ClientSecret: d.Get("client_secret").(string),
+			TokenURL:     v.(string),
+			Scopes:       scopes,
+		}
+		authCtx = context.WithValue(authCtx, airflow.ContextOAuth2, cred.TokenSource(context.Background()))
+	}
+
+	if v, ok := d.GetOk("google_auth"); ok {
+		log.Printf("[DEBUG] Using API Google %s", v)
+
+		audience := d.Get("google_iap_audience").(string)
+		if v == googleAuthIdToken && audience == "" {
+			return nil, fmt.Errorf("google_auth `id_token` requires google_iap_audience")
+		}
+		tokenSource, err := newGoogleTokenSource(v.(string), d.Get("google_credentials").(string), audience)
+		if err != nil {
+			return nil, err
+		}
+		authCtx = context.WithValue(authCtx, airflow.ContextOAuth2, tokenSource)
+	}
+
 	if username, ok := d.GetOk("username"); ok {
 		var password interface{}
 		if password, ok = d.GetOk("password"); !ok {
@@ -92,11 +432,50 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	}
 
 	path := strings.TrimRight(u.Path, "/")
+	metrics := newAPIMetrics()
+
+	headers := map[string]string{}
+	for k, v := range d.Get("headers").(map[string]interface{}) {
+		headers[k] = v.(string)
+	}
+
+	sensitiveHeaders := []string{d.Get("backend_affinity_header").(string)}
+	for name := range headers {
+		sensitiveHeaders = append(sensitiveHeaders, name)
+	}
+	transport, err := newTraceTransport(newTlsTransport(d.Get("tls_server_name").(string)), sensitiveHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if mwaa != nil {
+		transport = &mwaaTransport{next: transport, session: mwaa}
+	}
+
+	retryMinDelay, _ := time.ParseDuration(d.Get("retry_min_delay").(string))
+	retryMaxDelay, _ := time.ParseDuration(d.Get("retry_max_delay").(string))
+	if retryMaxDelay < retryMinDelay {
+		return nil, fmt.Errorf("retry_max_delay must not be shorter than retry_min_delay")
+	}
 
 	clientConf := &airflow.Configuration{
-		Scheme: u.Scheme,
-		Host:   u.Host,
-		Debug:  true,
+		Scheme:        u.Scheme,
+		Host:          u.Host,
+		Debug:         true,
+		DefaultHeader: headers,
+		HTTPClient: &http.Client{
+			Transport: &correlationIdTransport{
+				next: &apiPathTransport{
+					next: &backendAffinityTransport{
+						next: newCircuitBreakerTransport(newRetryTransport(&metricsTransport{
+							next:    transport,
+							metrics: metrics,
+						}, d.Get("max_retries").(int), retryMinDelay, retryMaxDelay, metrics), d.Get("circuit_breaker_threshold").(int)),
+						header: d.Get("backend_affinity_header").(string),
+					},
+					basePath: path,
+				},
+			},
+		},
 		Servers: airflow.ServerConfigurations{
 			{
 				URL:         fmt.Sprint(path, "/api/v1"),
@@ -105,8 +484,45 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		},
 	}
 
-	return ProviderConfig{
+	pcfg := ProviderConfig{
 		ApiClient:   airflow.NewAPIClient(clientConf),
 		AuthContext: authCtx,
-	}, nil
+		Metrics:     metrics,
+		BaseUrl:     fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, path),
+
+		SensitiveStateMode:   d.Get("sensitive_state_mode").(string),
+		DefaultUserRoles:     expandStringSet(d.Get("default_user_roles").(*schema.Set)),
+		UserLockoutThreshold: d.Get("user_lockout_threshold").(int),
+		PasswordPolicy:       expandPasswordPolicy(d.Get("password_policy").([]interface{})),
+		VariableKeyPrefix:    d.Get("variable_key_prefix").(string),
+		BackendAffinity:      d.Get("backend_affinity").(bool),
+
+		SkipRefreshWhenUnreachable: d.Get("skip_refresh_when_unreachable").(bool),
+		BulkParallelism:            d.Get("bulk_parallelism").(int),
+
+		versionCache:  &airflowVersionCache{},
+		readFallbacks: &readFallbackCache{},
+	}
+	connectionDefaults, err := expandConnectionDefaults(d.Get("connection_defaults").([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	pcfg.ConnectionDefaults = connectionDefaults
+	pcfg.DagNotFoundRetryTimeout, _ = time.ParseDuration(d.Get("dag_not_found_retry_timeout").(string))
+
+	if d.Get("wait_for_healthy").(bool) {
+		timeout, _ := time.ParseDuration(d.Get("wait_for_healthy_timeout").(string))
+		if err := waitForHealthy(pcfg, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	return pcfg, nil
+}
+
+func validateDuration(v interface{}, k string) (ws []string, errs []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration like `10m`: %w", k, err))
+	}
+	return
 }
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"testing"
 
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 var testAccProviders map[string]*schema.Provider
@@ -32,7 +36,7 @@ func testAccPreCheck(t *testing.T) {
 	_, userOk := os.LookupEnv("AIRFLOW_API_USERNAME")
 	_, passOk := os.LookupEnv("AIRFLOW_API_PASSWORD")
 
-	if tokenOk && !(userOk || passOk) {
+	if !tokenOk && !(userOk && passOk) {
 		t.Fatal("AIRFLOW_OAUTH2_TOKEN OR AIRFLOW_API_USERNAME/AIRFLOW_API_PASSWORD must be set for acceptance tests")
 	}
 
@@ -40,3 +44,33 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("AIRFLOW_BASE_ENDPOINT must be set for acceptance tests")
 	}
 }
+
+// testResourceDataUpdate returns the resource data for applying raw as the
+// new configuration of a resource currently in state, as during an update.
+func testResourceDataUpdate(t *testing.T, r *schema.Resource, state *terraform.InstanceState, raw map[string]interface{}, m interface{}) *schema.ResourceData {
+	t.Helper()
+
+	// The raw configuration is passed along as by Terraform, so that
+	// configured values can be told apart from suppressed ones.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatalf("failed to encode config: %s", err)
+	}
+	state = state.DeepCopy()
+	state.RawConfig, err = ctyjson.Unmarshal(b, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("failed to decode config: %s", err)
+	}
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(raw), m)
+	if err != nil {
+		t.Fatalf("failed to diff: %s", err)
+	}
+
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("failed to build resource data: %s", err)
+	}
+
+	return d
+}
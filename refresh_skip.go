@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// wrapRefreshSkipping makes the refresh of a resource keep the last known
+// state with a warning instead of failing when Airflow is unreachable and
+// skip_refresh_when_unreachable is set, so that plans of unrelated changes
+// aren't blocked by a maintenance window of the webserver.
+func wrapRefreshSkipping(r *schema.Resource) {
+	read := r.ReadContext
+	if r.Read != nil {
+		read = schema.ReadContextFunc(withOperationWarnings(operationFunc(r.Read)))
+		r.Read = nil
+	}
+	if read == nil {
+		return
+	}
+
+	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		diags := read(ctx, d, m)
+		if !diags.HasError() {
+			return diags
+		}
+
+		pcfg, ok := m.(ProviderConfig)
+		if !ok || !pcfg.SkipRefreshWhenUnreachable || !airflowUnreachable(pcfg) {
+			return diags
+		}
+
+		var failure string
+		for _, diagnostic := range diags {
+			if diagnostic.Severity == diag.Error {
+				failure = diagnostic.Summary
+				break
+			}
+		}
+
+		log.Printf("[WARN] Airflow is unreachable, keeping the last known state of `%s`: %s", d.Id(), failure)
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Airflow is unreachable, the refresh of `%s` was skipped", d.Id()),
+			Detail:   fmt.Sprintf("The plan uses the last known state, so it doesn't show changes made in Airflow since the last refresh. Refreshing failed with: %s", failure),
+		}}
+	}
+}
+
+// airflowUnreachable returns whether the health endpoint, which doesn't
+// require authentication, can't be reached either.
+func airflowUnreachable(pcfg ProviderConfig) bool {
+	_, _, err := pcfg.ApiClient.MonitoringApi.GetHealth(pcfg.AuthContext).Execute()
+	if err != nil {
+		log.Printf("[DEBUG] Airflow health check failed: %s", err)
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestWrapRefreshSkipping_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.SkipRefreshWhenUnreachable = true
+
+	fake.seed("variables", map[string]interface{}{"key": "foo", "value": "bar"})
+	fake.handle(http.MethodGet, "/health", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{})
+	})
+
+	r := resourceVariable()
+	wrapRefreshSkipping(r)
+
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{"key": "foo", "value": "old"})
+	d.SetId("foo")
+
+	// The webserver is down.
+	fake.failNext("", "/", http.StatusServiceUnavailable, 2)
+	diags := r.ReadContext(context.Background(), d, m)
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+	if d.Id() != "foo" || d.Get("value").(string) != "old" {
+		t.Fatalf("expected the last known state to be kept, got %q = %q", d.Id(), d.Get("value"))
+	}
+
+	// Only the read fails, which isn't skipped.
+	fake.failNext(http.MethodGet, "/variables/foo", http.StatusInternalServerError, 1)
+	if diags := r.ReadContext(context.Background(), d, m); !diags.HasError() {
+		t.Fatalf("expected an error while Airflow is reachable, got %v", diags)
+	}
+
+	// Without the option, the refresh fails.
+	m.SkipRefreshWhenUnreachable = false
+	fake.failNext("", "/", http.StatusServiceUnavailable, 1)
+	if diags := r.ReadContext(context.Background(), d, m); !diags.HasError() {
+		t.Fatalf("expected an error without skip_refresh_when_unreachable, got %v", diags)
+	}
+
+	if diags := r.ReadContext(context.Background(), d, m); diags.HasError() {
+		t.Fatalf("read: %v", diags)
+	}
+	if got := d.Get("value").(string); got != "bar" {
+		t.Fatalf("expected value bar once Airflow is back, got %q", got)
+	}
+}
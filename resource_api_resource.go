@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceApiResource manages an object of an API endpoint that the provider
+// doesn't model yet, using the provider's authentication.
+func resourceApiResource() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceApiResourceCreate,
+		Read:   resourceApiResourceRead,
+		Update: resourceApiResourceUpdate,
+		Delete: resourceApiResourceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceApiResourceImport,
+		},
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			// Without an update method the object is replaced instead.
+			if d.HasChange("body") && d.Get("update_method").(string) == "" {
+				return d.ForceNew("body")
+			}
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			"path": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"body": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressSameJsonDiff,
+			},
+			"create_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      http.MethodPost,
+				ValidateFunc: validation.StringInSlice([]string{http.MethodPost, http.MethodPut, http.MethodPatch}, false),
+			},
+			"id_attribute": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "id",
+			},
+			"object_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"update_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{http.MethodPatch, http.MethodPost, http.MethodPut}, false),
+			},
+			"delete_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      http.MethodDelete,
+				ValidateFunc: validation.StringInSlice([]string{http.MethodDelete, http.MethodPost, http.MethodPatch, http.MethodPut}, false),
+			},
+			"response": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// apiResourceObjectPath returns the path of the managed object. The `{id}`
+// placeholder of object_path is replaced with the escaped ID, which defaults
+// to `<path>/{id}`.
+func apiResourceObjectPath(d *schema.ResourceData) string {
+	template := d.Get("object_path").(string)
+	if template == "" {
+		template = strings.TrimRight(d.Get("path").(string), "/") + "/{id}"
+	}
+
+	return strings.ReplaceAll(template, "{id}", url.PathEscape(d.Id()))
+}
+
+func apiResourceBody(d *schema.ResourceData) interface{} {
+	v := d.Get("body").(string)
+	if v == "" {
+		return nil
+	}
+
+	return json.RawMessage(v)
+}
+
+func resourceApiResourceCreate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	path := d.Get("path").(string)
+
+	var created interface{}
+	if _, err := apiRequest(pcfg, d.Get("create_method").(string), path, nil, apiResourceBody(d), &created); err != nil {
+		return fmt.Errorf("failed to create object at `%s` from Airflow: %w", path, err)
+	}
+
+	idAttr := d.Get("id_attribute").(string)
+	id, err := extractJSONPath(created, idAttr)
+	if err != nil {
+		return fmt.Errorf("failed to get the ID of the object created at `%s`: %w", path, err)
+	}
+	if id == nil || fmt.Sprint(id) == "" {
+		return fmt.Errorf("failed to get the ID of the object created at `%s`: `%s` is empty", path, idAttr)
+	}
+	d.SetId(fmt.Sprint(id))
+
+	return resourceApiResourceRead(d, m)
+}
+
+func resourceApiResourceRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	path := apiResourceObjectPath(d)
+
+	var object interface{}
+	resp, err := apiRequest(pcfg, http.MethodGet, path, nil, nil, &object)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get object `%s` from Airflow: %w", path, apiPermissionError(resp, err, "can_read on the resource of "+path))
+	}
+
+	response, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("failed to encode object `%s`: %w", path, err)
+	}
+	d.Set("response", string(response))
+
+	return nil
+}
+
+func resourceApiResourceUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	path := apiResourceObjectPath(d)
+
+	if method := d.Get("update_method").(string); method != "" && d.HasChange("body") {
+		if _, err := apiRequest(pcfg, method, path, nil, apiResourceBody(d), nil); err != nil {
+			return fmt.Errorf("failed to update object `%s` from Airflow: %w", path, err)
+		}
+	}
+
+	return resourceApiResourceRead(d, m)
+}
+
+func resourceApiResourceDelete(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	path := apiResourceObjectPath(d)
+
+	resp, err := apiRequest(pcfg, d.Get("delete_method").(string), path, nil, nil, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete object `%s` from Airflow: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceApiResource_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceApiResource().Schema, map[string]interface{}{
+		"path":          "/pools",
+		"body":          `{"name": "team a", "slots": 2}`,
+		"id_attribute":  "name",
+		"update_method": http.MethodPatch,
+	})
+	if err := resourceApiResourceCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if d.Id() != "team a" {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+	if pool := fake.object("pools", "team a"); pool == nil || pool["slots"] != float64(2) {
+		t.Fatalf("unexpected pool %v", pool)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(d.Get("response").(string)), &response); err != nil {
+		t.Fatalf("invalid response: %s", err)
+	}
+	if response["name"] != "team a" {
+		t.Fatalf("unexpected response %v", response)
+	}
+
+	if err := resourceApiResourceDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("pools", "team a") != nil {
+		t.Fatal("pool was not deleted")
+	}
+
+	if err := resourceApiResourceRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected the deleted object to be removed from state, got %q", d.Id())
+	}
+}
+
+func TestResourceApiResource_fakeObjectPath(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"dag_run_id": "manual_1"}},
+		})
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/manual_1/taskInstances", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"total_entries": 0})
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceApiResource().Schema, map[string]interface{}{
+		"path":         "/dags/example/dagRuns",
+		"id_attribute": "items[0].dag_run_id",
+		"object_path":  "/dags/example/dagRuns/{id}/taskInstances",
+	})
+	if err := resourceApiResourceCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if d.Id() != "manual_1" {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+	if d.Get("response").(string) != `{"total_entries":0}` {
+		t.Fatalf("unexpected response %s", d.Get("response"))
+	}
+}
+
+func TestResourceApiResource_fakeImport(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("pools", map[string]interface{}{"name": "imported", "slots": 3})
+
+	d := resourceApiResource().Data(nil)
+	d.SetId("/pools:imported")
+	ds, err := resourceApiResourceImport(context.Background(), d, m)
+	if err != nil {
+		t.Fatalf("import: %s", err)
+	}
+	if err := resourceApiResourceRead(ds[0], m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if ds[0].Id() != "imported" || ds[0].Get("path").(string) != "/pools" || ds[0].Get("response").(string) == "" {
+		t.Fatalf("unexpected imported object %q at %q", ds[0].Id(), ds[0].Get("path"))
+	}
+
+	d.SetId("/pools")
+	if _, err := resourceApiResourceImport(context.Background(), d, m); err == nil {
+		t.Fatal("expected an error for an import ID without an object ID")
+	}
+}
+
+func TestExtractJSONPath(t *testing.T) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(`{"id": 1, "items": [{"name": "a"}, {"name": "b"}]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]interface{}{
+		"id":            float64(1),
+		"items[1].name": "b",
+	} {
+		got, err := extractJSONPath(v, path)
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		if got != want {
+			t.Fatalf("%s: expected %v, got %v", path, want, got)
+		}
+	}
+
+	for _, path := range []string{"missing", "items[2].name", "id.name"} {
+		if _, err := extractJSONPath(v, path); err == nil {
+			t.Fatalf("%s: expected an error", path)
+		}
+	}
+}
@@ -12,11 +12,12 @@ import (
 )
 
 func resourceConnection() *schema.Resource {
-	return &schema.Resource{
-		Create: resourceConnectionCreate,
-		Read:   resourceConnectionRead,
-		Update: resourceConnectionUpdate,
-		Delete: resourceConnectionDelete,
+	r := &schema.Resource{
+		Create:        resourceConnectionCreate,
+		Read:          resourceConnectionRead,
+		Update:        resourceConnectionUpdate,
+		Delete:        resourceConnectionDelete,
+		CustomizeDiff: customizeConnectionExtraDefaultsDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -48,16 +49,47 @@ func resourceConnection() *schema.Resource {
 				ValidateFunc: validation.IsPortNumberOrZero,
 			},
 			"password": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressSensitiveStateDiff,
 			},
 			"extra": {
 				Type:             schema.TypeString,
-				DiffSuppressFunc: suppressSameJsonDiff,
+				DiffSuppressFunc: suppressConnectionExtraDiff,
 				Optional:         true,
+				Sensitive:        true,
 			},
+			"extra_defaults": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"store_secrets_in_state": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"rotation_triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"check_secrets_backend": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"server_managed_attributes": serverManagedAttributesSchema("host", "login", "schema", "port", "extra"),
+			"sensitive_state_mode":      sensitiveStateModeSchema(),
+			"sensitive_state_digests":   sensitiveStateDigestsSchema(),
+			"manage":                    manageSchema(),
+			"deletion_protection":       deletionProtectionSchema(),
+			"ui_url":                    uiUrlSchema(),
 		},
 	}
+	addConnectionExtraBlocks(r.Schema)
+
+	return r
 }
 
 func suppressSameJsonDiff(k, oldo, newo string, d *schema.ResourceData) bool {
@@ -78,48 +110,56 @@ func suppressSameJsonDiff(k, oldo, newo string, d *schema.ResourceData) bool {
 	return reflect.DeepEqual(oldIface, newIface)
 }
 
-func resourceConnectionCreate(d *schema.ResourceData, m interface{}) error {
-	pcfg := m.(ProviderConfig)
-	client := pcfg.ApiClient
-	connId := d.Get("connection_id").(string)
-	connType := d.Get("conn_type").(string)
-
-	conn := airflow.Connection{
-		ConnectionId: &connId,
-		ConnType:     &connType,
-	}
-
-	if v, ok := d.GetOk("host"); ok {
-		conn.SetHost(v.(string))
+// connectionSensitiveStateMode returns the mode requested by the resource.
+// When secrets aren't stored in state, password and extra are treated as
+// write-only and omitted.
+func connectionSensitiveStateMode(d *schema.ResourceData) string {
+	if !d.Get("store_secrets_in_state").(bool) {
+		return sensitiveStateOmit
 	}
+	return sensitiveStatePlain
+}
 
-	if v, ok := d.GetOk("login"); ok {
-		conn.SetLogin(v.(string))
+func suppressConnectionExtraDiff(k, oldo, newo string, d *schema.ResourceData) bool {
+	// A typed extra block renders the extra that is sent to Airflow.
+	if newo == "" {
+		if rendered, ok := renderConnectionExtra(d); ok {
+			newo = rendered
+		}
 	}
+	newo = mergeConnectionExtraDefaults(d.Get("extra_defaults").(string), newo)
+	return suppressSensitiveStateDiff(k, oldo, newo, d) || suppressSameJsonDiff(k, oldo, newo, d)
+}
 
-	if v, ok := d.GetOk("schema"); ok {
-		conn.SetSchema(v.(string))
+func resourceConnectionCreate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+	connId := d.Get("connection_id").(string)
+	if isObserveOnly(d) {
+		return observeExisting(d, m, "connection", connId, resourceConnectionRead)
 	}
 
-	if v, ok := d.GetOk("port"); ok {
-		conn.SetPort(int32(v.(int)))
-	}
+	d.Set("extra_defaults", connectionExtraDefaults(m, d.Get("conn_type").(string)))
+	conn := expandAirflowConnection(d, connId)
+	password := configString(d, "password")
+	conn.SetPassword(password)
 
-	conn.SetPassword(d.Get("password").(string))
+	connApi := client.ConnectionApi
 
-	if v, ok := d.GetOk("extra"); ok {
-		conn.SetExtra(v.(string))
+	if err := checkSecretsBackend(d, pcfg, "connection", connId); err != nil {
+		return err
 	}
 
-	connApi := client.ConnectionApi
-
-	_, _, err := connApi.PostConnection(pcfg.AuthContext).Connection(conn).Execute()
-	if err != nil {
+	_, resp, err := connApi.PostConnection(pcfg.AuthContext).Connection(conn).Execute()
+	if err != nil && !createdDespiteError(d, m, "connection", connId, resp, err, resourceConnectionRead) {
 		return fmt.Errorf("failed to create connection `%s` from Airflow: %w", connId, err)
 	}
 	d.SetId(connId)
+	mode := effectiveSensitiveStateMode(m, connectionSensitiveStateMode(d))
+	setSensitiveStateValue(d, "password", mode, password)
+	recordSensitiveStateDigest(d, "extra", mode, conn.GetExtra())
 
-	return resourceConnectionRead(d, m)
+	return readAfterCreate(d, m, "connection", resourceConnectionRead)
 }
 
 func resourceConnectionRead(d *schema.ResourceData, m interface{}) error {
@@ -131,21 +171,28 @@ func resourceConnectionRead(d *schema.ResourceData, m interface{}) error {
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get connection `%s` from Airflow: %w", d.Id(), err)
+		return fmt.Errorf("failed to get connection `%s` from Airflow: %w", d.Id(), apiPermissionError(resp, err, "can_read on Connections"))
 	}
 
 	d.Set("connection_id", connection.GetConnectionId())
 	d.Set("conn_type", connection.GetConnType())
-	d.Set("host", connection.GetHost())
-	d.Set("login", connection.GetLogin())
-	d.Set("schema", connection.GetSchema())
-	d.Set("port", connection.GetPort())
-	d.Set("extra", connection.GetExtra())
-
+	setUnlessServerManaged(d, "host", connection.GetHost())
+	setUnlessServerManaged(d, "login", connection.GetLogin())
+	setUnlessServerManaged(d, "schema", connection.GetSchema())
+	setUnlessServerManaged(d, "port", connection.GetPort())
+
+	mode := effectiveSensitiveStateMode(m, connectionSensitiveStateMode(d))
+	d.Set("sensitive_state_mode", mode)
+	keepSensitiveStateDigests(d)
+	d.Set("ui_url", airflowUiUrl(m, "connection", d.Id()))
+	setUnlessServerManaged(d, "extra", sensitiveStateValue(mode, connection.GetExtra()))
+
+	// Airflow doesn't return passwords, in which case the value stored in
+	// state when it was last written is kept.
 	if v, ok := connection.GetPasswordOk(); ok {
-		d.Set("password", v)
-	} else if v, ok := d.GetOk("password"); ok {
-		d.Set("password", v)
+		d.Set("password", sensitiveStateValue(mode, *v))
+	} else if mode == sensitiveStateOmit {
+		d.Set("password", "")
 	}
 
 	return nil
@@ -155,35 +202,34 @@ func resourceConnectionUpdate(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 	connId := d.Id()
-	connType := d.Get("conn_type").(string)
-
-	conn := airflow.Connection{
-		ConnectionId: &connId,
-		ConnType:     &connType,
+	if isObserveOnly(d) {
+		observeOnly(d, m, "connection", "updating")
+		return resourceConnectionRead(d, m)
 	}
 
-	if v, ok := d.GetOk("host"); ok {
-		conn.SetHost(v.(string))
-	}
+	d.Set("extra_defaults", connectionExtraDefaults(m, d.Get("conn_type").(string)))
+	conn := expandAirflowConnection(d, connId)
 
-	if v, ok := d.GetOk("login"); ok {
-		conn.SetLogin(v.(string))
+	// Airflow keeps the attributes of a connection that are left out.
+	if !sendServerManaged(d, "host") {
+		conn.UnsetHost()
 	}
-
-	if v, ok := d.GetOk("schema"); ok {
-		conn.SetSchema(v.(string))
+	if !sendServerManaged(d, "login") {
+		conn.UnsetLogin()
 	}
-
-	if v, ok := d.GetOk("port"); ok {
-		conn.SetPort(int32(v.(int)))
+	if !sendServerManaged(d, "schema") {
+		conn.UnsetSchema()
 	}
-
-	if v, ok := d.GetOk("password"); ok && v.(string) != "" {
-		conn.SetPassword(v.(string))
+	if !sendServerManaged(d, "port") {
+		conn.UnsetPort()
+	}
+	if !sendServerManaged(d, "extra") {
+		conn.UnsetExtra()
 	}
 
-	if v, ok := d.GetOk("extra"); ok {
-		conn.SetExtra(v.(string))
+	password := configString(d, "password")
+	if password != "" {
+		conn.SetPassword(password)
 	}
 
 	_, _, err := client.ConnectionApi.PatchConnection(pcfg.AuthContext, connId).Connection(conn).Execute()
@@ -191,6 +237,14 @@ func resourceConnectionUpdate(d *schema.ResourceData, m interface{}) error {
 		return fmt.Errorf("failed to update connection `%s` from Airflow: %w", connId, err)
 	}
 
+	mode := effectiveSensitiveStateMode(m, connectionSensitiveStateMode(d))
+	if password != "" {
+		setSensitiveStateValue(d, "password", mode, password)
+	}
+	if conn.HasExtra() {
+		recordSensitiveStateDigest(d, "extra", mode, conn.GetExtra())
+	}
+
 	return resourceConnectionRead(d, m)
 }
 
@@ -198,6 +252,15 @@ func resourceConnectionDelete(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
+	if err := checkDeletionProtection(d, "connection"); err != nil {
+		return err
+	}
+
+	if isObserveOnly(d) {
+		observeOnly(d, m, "connection", "deleting")
+		return nil
+	}
+
 	resp, err := client.ConnectionApi.DeleteConnection(pcfg.AuthContext, d.Id()).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to delete connection `%s` from Airflow: %w", d.Id(), err)
@@ -209,3 +272,41 @@ func resourceConnectionDelete(d *schema.ResourceData, m interface{}) error {
 
 	return nil
 }
+
+// expandAirflowConnection builds the connection sent to Airflow from the
+// resource data. The password is handled by the callers as create and update
+// treat an empty password differently.
+func expandAirflowConnection(d *schema.ResourceData, connId string) airflow.Connection {
+	connType := d.Get("conn_type").(string)
+
+	conn := airflow.Connection{
+		ConnectionId: &connId,
+		ConnType:     &connType,
+	}
+
+	if v, ok := d.GetOk("host"); ok {
+		conn.SetHost(v.(string))
+	}
+
+	if v, ok := d.GetOk("login"); ok {
+		conn.SetLogin(v.(string))
+	}
+
+	if v, ok := d.GetOk("schema"); ok {
+		conn.SetSchema(v.(string))
+	}
+
+	if v, ok := d.GetOk("port"); ok {
+		conn.SetPort(int32(v.(int)))
+	}
+
+	extra := configString(d, "extra")
+	if v, ok := renderConnectionExtra(d); ok && extra == "" {
+		extra = v
+	}
+	if v := mergeConnectionExtraDefaults(d.Get("extra_defaults").(string), extra); v != "" {
+		conn.SetExtra(v)
+	}
+
+	return conn
+}
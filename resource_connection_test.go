@@ -2,10 +2,13 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -126,3 +129,210 @@ resource "airflow_connection" "test" {
 }
 `, rName, rName2, port)
 }
+
+func TestResourceConnection_fakeCRUD(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceConnection().Schema, map[string]interface{}{
+		"connection_id": "fake-conn",
+		"conn_type":     "http",
+		"host":          "example.com",
+		"port":          443,
+	})
+
+	if err := resourceConnectionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("host").(string); got != "example.com" {
+		t.Fatalf("expected host example.com, got %q", got)
+	}
+
+	d.Set("host", "updated.example.com")
+	if err := resourceConnectionUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := fake.object("connections", "fake-conn")["host"]; got != "updated.example.com" {
+		t.Fatalf("expected host to be updated, got %v", got)
+	}
+
+	fake.failNext(http.MethodGet, "/connections/fake-conn", http.StatusForbidden, 1)
+	if err := resourceConnectionRead(d, m); err == nil || !strings.Contains(err.Error(), "lacks the `can_read on Connections` permission") {
+		t.Fatalf("expected a missing permission error on 403, got %v", err)
+	}
+	if d.Id() != "fake-conn" {
+		t.Fatal("expected connection to be kept in state on 403")
+	}
+
+	fake.failNext(http.MethodGet, "/connections/fake-conn", http.StatusNotFound, 1)
+	if err := resourceConnectionRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() != "" {
+		t.Fatal("expected connection to be removed from state on 404")
+	}
+}
+
+func TestResourceConnection_fakeSecretsOmitted(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceConnection().Schema, map[string]interface{}{
+		"connection_id":          "fake-omitted",
+		"conn_type":              "http",
+		"password":               "secret",
+		"extra":                  `{"token": "secret"}`,
+		"store_secrets_in_state": false,
+	})
+
+	if err := resourceConnectionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := fake.object("connections", "fake-omitted")["extra"]; got != `{"token": "secret"}` {
+		t.Fatalf("expected extra to be sent to Airflow, got %v", got)
+	}
+	if d.Get("password").(string) != "" || d.Get("extra").(string) != "" {
+		t.Fatal("expected password and extra to be omitted from state")
+	}
+	if !suppressConnectionExtraDiff("extra", "", `{"token": "secret"}`, d) {
+		t.Fatal("expected no diff for an omitted extra")
+	}
+	if !suppressSensitiveStateDiff("password", "", "secret", d) {
+		t.Fatal("expected no diff for an omitted password")
+	}
+
+	// Changes are still found by the digests of the last written values.
+	if suppressConnectionExtraDiff("extra", "", `{"token": "rotated"}`, d) {
+		t.Fatal("expected a diff for a changed extra")
+	}
+	if suppressSensitiveStateDiff("password", "", "rotated", d) {
+		t.Fatal("expected a diff for a changed password")
+	}
+
+	raw := map[string]interface{}{
+		"connection_id":          "fake-omitted",
+		"conn_type":              "http",
+		"password":               "rotated",
+		"extra":                  `{"token": "rotated"}`,
+		"store_secrets_in_state": false,
+	}
+	d = testResourceDataUpdate(t, resourceConnection(), d.State(), raw, m)
+	if !d.HasChange("password") || !d.HasChange("extra") {
+		t.Fatal("expected the plan to change password and extra")
+	}
+	if err := resourceConnectionUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := fake.object("connections", "fake-omitted")["extra"]; got != `{"token": "rotated"}` {
+		t.Fatalf("expected the changed extra to be sent to Airflow, got %v", got)
+	}
+	if got := d.Get("sensitive_state_digests.password").(string); got != sha256Hex("rotated") {
+		t.Fatalf("expected the digest of the changed password, got %q", got)
+	}
+	if d.Get("password").(string) != "" || d.Get("extra").(string) != "" {
+		t.Fatal("expected password and extra to stay omitted from state")
+	}
+}
+
+func TestResourceConnection_fakeProviderHashMode(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.SensitiveStateMode = sensitiveStateHash
+
+	d := schema.TestResourceDataRaw(t, resourceConnection().Schema, map[string]interface{}{
+		"connection_id": "fake-hashed",
+		"conn_type":     "http",
+		"password":      "secret",
+		"extra":         `{"token": "secret"}`,
+	})
+
+	if err := resourceConnectionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("sensitive_state_mode").(string); got != sensitiveStateHash {
+		t.Fatalf("expected the provider-wide mode to apply, got %q", got)
+	}
+	if got := d.Get("password").(string); got != sha256Hex("secret") {
+		t.Fatalf("expected the password digest in state, got %q", got)
+	}
+	if got := d.Get("extra").(string); got != sha256Hex(`{"token": "secret"}`) {
+		t.Fatalf("expected the extra digest in state, got %q", got)
+	}
+	if !suppressConnectionExtraDiff("extra", sha256Hex(`{"token": "secret"}`), `{"token": "secret"}`, d) {
+		t.Fatal("expected no diff when the digest matches the configured extra")
+	}
+}
+
+func TestResourceConnection_fakeTypedExtra(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceConnection().Schema, map[string]interface{}{
+		"connection_id": "fake-aws",
+		"conn_type":     "aws",
+		"aws_extra": []interface{}{map[string]interface{}{
+			"region_name": "eu-west-1",
+			"role_arn":    "arn:aws:iam::123456789012:role/airflow",
+			"external_id": "shared",
+		}},
+	})
+
+	if err := resourceConnectionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	want := `{"assume_role_kwargs":{"ExternalId":"shared"},"region_name":"eu-west-1","role_arn":"arn:aws:iam::123456789012:role/airflow"}`
+	if got := fake.object("connections", "fake-aws")["extra"]; got != want {
+		t.Fatalf("expected the rendered extra to be sent to Airflow, got %v", got)
+	}
+	if !suppressConnectionExtraDiff("extra", `{"region_name": "eu-west-1", "role_arn": "arn:aws:iam::123456789012:role/airflow", "assume_role_kwargs": {"ExternalId": "shared"}}`, "", d) {
+		t.Fatal("expected no diff when the extra matches the typed block")
+	}
+	if suppressConnectionExtraDiff("extra", `{"region_name": "us-east-1"}`, "", d) {
+		t.Fatal("expected a diff when the extra drifted from the typed block")
+	}
+}
+
+func TestResourceConnection_fakeConnectionDefaults(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.ConnectionDefaults = []connectionDefaults{
+		{Extra: map[string]interface{}{"owner": "data"}},
+		{ConnType: "aws", Extra: map[string]interface{}{"region_name": "eu-west-1"}},
+	}
+
+	raw := map[string]interface{}{
+		"connection_id": "fake-defaults",
+		"conn_type":     "aws",
+		"extra":         `{"role_arn": "arn:aws:iam::123456789012:role/airflow", "owner": "platform"}`,
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceConnection().Schema, raw)
+	if err := resourceConnectionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	want := `{"owner":"platform","region_name":"eu-west-1","role_arn":"arn:aws:iam::123456789012:role/airflow"}`
+	if got := fake.object("connections", "fake-defaults")["extra"]; got != want {
+		t.Fatalf("expected the defaults to be merged into extra, got %v", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceConnection(), d.State(), raw, m)
+	if d.HasChange("extra") || d.HasChange("extra_defaults") {
+		t.Fatal("expected no diff for the merged defaults")
+	}
+
+	// Changed defaults update existing connections.
+	m.ConnectionDefaults[1].Extra["region_name"] = "us-east-1"
+	d = testResourceDataUpdate(t, resourceConnection(), d.State(), raw, m)
+	if !d.HasChange("extra_defaults") {
+		t.Fatal("expected an extra_defaults diff")
+	}
+	d = schema.TestResourceDataRaw(t, resourceConnection().Schema, raw)
+	d.SetId("fake-defaults")
+	if err := resourceConnectionUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	want = `{"owner":"platform","region_name":"us-east-1","role_arn":"arn:aws:iam::123456789012:role/airflow"}`
+	if got := fake.object("connections", "fake-defaults")["extra"]; got != want {
+		t.Fatalf("expected the new defaults to be sent to Airflow, got %v", got)
+	}
+}
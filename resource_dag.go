@@ -1,7 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
 
 	"github.com/apache/airflow-client-go/airflow"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -27,9 +31,16 @@ func resourceDag() *schema.Resource {
 				Computed: true,
 			},
 			"delete_dag": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"pause_on_delete"},
+			},
+			"pause_on_delete": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"delete_dag"},
 			},
 			"file_token": {
 				Type:     schema.TypeString,
@@ -55,22 +66,54 @@ func resourceDag() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"tags": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"owners": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"max_active_runs": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"max_active_tasks": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"bundle_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"bundle_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ui_url": uiUrlSchema(),
 		},
 	}
 }
 
 func resourceDagUpdate(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
-	client := pcfg.ApiClient
 
 	dagId := d.Get("dag_id").(string)
-	dagApi := client.DAGApi
-	dag := *airflow.NewDAG()
-	dag.SetIsPaused(d.Get("is_paused").(bool))
-
-	_, res, err := dagApi.PatchDag(pcfg.AuthContext, dagId).DAG(dag).Execute()
-	if res.StatusCode != 200 {
-		return fmt.Errorf("failed to update DAG `%s` from Airflow: %w", dagId, err)
+	res, err := retryWhileDagNotFound(pcfg, dagId, func() (*http.Response, error) {
+		return pauseAirflowDag(pcfg, dagId, d.Get("is_paused").(bool))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update DAG `%s` from Airflow: %w", dagId, apiPermissionError(res, err, "can_edit on DAG:"+dagId))
+	}
+	// The v2 API may answer without a body, e.g. with 204.
+	if res == nil || res.StatusCode < 200 || res.StatusCode >= 300 {
+		status := "no response"
+		if res != nil {
+			status = res.Status
+		}
+		return fmt.Errorf("failed to update DAG `%s` from Airflow: unexpected status %s", dagId, status)
 	}
 	d.SetId(dagId)
 
@@ -79,15 +122,14 @@ func resourceDagUpdate(d *schema.ResourceData, m interface{}) error {
 
 func resourceDagRead(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
-	client := pcfg.ApiClient
 
-	DAG, resp, err := client.DAGApi.GetDag(pcfg.AuthContext, d.Id()).Execute()
+	DAG, bundle, resp, err := getAirflowDag(pcfg, d.Id())
 	if resp != nil && resp.StatusCode == 404 {
 		d.SetId("")
 		return nil
 	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("failed to get DAG `%s` from Airflow: %w", d.Id(), err)
+	if err != nil {
+		return fmt.Errorf("failed to get DAG `%s` from Airflow: %w", d.Id(), apiPermissionError(resp, err, "can_read on DAG:"+d.Id()))
 	}
 
 	d.Set("dag_id", DAG.DagId)
@@ -98,16 +140,22 @@ func resourceDagRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("file_token", DAG.FileToken)
 	d.Set("fileloc", DAG.Fileloc)
 	d.Set("root_dag_id", DAG.RootDagId.Get())
+	d.Set("tags", flattenAirflowDagTags(DAG.Tags))
+	d.Set("owners", DAG.GetOwners())
+	d.Set("max_active_runs", DAG.GetMaxActiveRuns())
+	d.Set("max_active_tasks", DAG.GetMaxActiveTasks())
+	d.Set("bundle_name", bundle.BundleName)
+	d.Set("bundle_version", bundle.BundleVersion)
+	d.Set("ui_url", airflowUiUrl(m, "dag", d.Id()))
 
 	return nil
 }
 
 func resourceDagDelete(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
-	client := pcfg.ApiClient.DAGApi
 
 	if d.Get("delete_dag").(bool) {
-		resp, err := client.DeleteDag(pcfg.AuthContext, d.Id()).Execute()
+		resp, err := deleteAirflowDag(pcfg, d.Id())
 		if err != nil {
 			return fmt.Errorf("failed to delete DAG `%s` from Airflow: %w", d.Id(), err)
 		}
@@ -117,5 +165,71 @@ func resourceDagDelete(d *schema.ResourceData, m interface{}) error {
 		}
 	}
 
+	if d.Get("pause_on_delete").(bool) {
+		resp, err := pauseAirflowDag(pcfg, d.Id(), true)
+		if resp != nil && resp.StatusCode == 404 {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to pause DAG `%s` from Airflow: %w", d.Id(), err)
+		}
+	}
+
 	return nil
 }
+
+// getAirflowDag reads a DAG and, on Airflow 3, its DAG bundle. Airflow 3 only
+// serves the DAG API as v2.
+func getAirflowDag(pcfg ProviderConfig, dagId string) (airflow.DAG, airflowDagBundle, *http.Response, error) {
+	if !pcfg.isAirflow3() {
+		dag, resp, err := pcfg.ApiClient.DAGApi.GetDag(pcfg.AuthContext, dagId).Execute()
+		return dag, airflowDagBundle{}, resp, err
+	}
+
+	var body json.RawMessage
+	resp, err := uiRequest(pcfg, http.MethodGet, "/api/v2/dags/"+url.PathEscape(dagId), nil, &body)
+	if err != nil {
+		return airflow.DAG{}, airflowDagBundle{}, resp, err
+	}
+
+	dag, bundle, err := decodeAirflowDagV2(body)
+	return dag, bundle, resp, err
+}
+
+// pauseAirflowDag pauses or unpauses a DAG. Neither the stable API nor the v2
+// API of Airflow 3 allow patching other attributes of DAGs, which are
+// declared in the DAG files and only read.
+func pauseAirflowDag(pcfg ProviderConfig, dagId string, paused bool) (*http.Response, error) {
+	if !pcfg.isAirflow3() {
+		dag := *airflow.NewDAG()
+		dag.SetIsPaused(paused)
+		_, resp, err := pcfg.ApiClient.DAGApi.PatchDag(pcfg.AuthContext, dagId).DAG(dag).UpdateMask([]string{"is_paused"}).Execute()
+		return resp, err
+	}
+
+	path := "/api/v2/dags/" + url.PathEscape(dagId)
+	u, err := uiUrl(pcfg, path, url.Values{"update_mask": {"is_paused"}})
+	if err != nil {
+		return nil, err
+	}
+	return sendRequest(pcfg, http.MethodPatch, u, path, map[string]interface{}{"is_paused": paused}, nil)
+}
+
+func deleteAirflowDag(pcfg ProviderConfig, dagId string) (*http.Response, error) {
+	if !pcfg.isAirflow3() {
+		return pcfg.ApiClient.DAGApi.DeleteDag(pcfg.AuthContext, dagId).Execute()
+	}
+	return uiRequest(pcfg, http.MethodDelete, "/api/v2/dags/"+url.PathEscape(dagId), nil, nil)
+}
+
+func flattenAirflowDagTags(apiObjects []airflow.Tag) []string {
+	vs := make([]string, 0, len(apiObjects))
+	for _, v := range apiObjects {
+		if v.Name == nil {
+			continue
+		}
+		vs = append(vs, *v.Name)
+	}
+	sort.Strings(vs)
+	return vs
+}
@@ -2,22 +2,33 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/apache/airflow-client-go/airflow"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	dagRunOnDestroyDelete = "delete"
+	dagRunOnDestroyKeep   = "keep"
 )
 
 func resourceDagRun() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceDagRunCreate,
 		Read:   resourceDagRunRead,
+		Update: resourceDagRunUpdate,
 		Delete: resourceDagRunDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceDagRunImport,
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
@@ -29,10 +40,17 @@ func resourceDagRun() *schema.Resource {
 				ForceNew: true,
 			},
 			"dag_run_id": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
-				Computed: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				Computed:      true,
+				ConflictsWith: []string{"dag_run_id_prefix"},
+			},
+			"dag_run_id_prefix": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"dag_run_id"},
 			},
 			"conf": {
 				Type:     schema.TypeMap,
@@ -40,10 +58,84 @@ func resourceDagRun() *schema.Resource {
 				ForceNew: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"recorded_conf": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"conf_matches": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"logical_date": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Computed:         true,
+				ValidateFunc:     validation.IsRFC3339Time,
+				StateFunc:        normalizeTime,
+				DiffSuppressFunc: suppressEquivalentTimeDiff,
+			},
+			"data_interval_start": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Computed:         true,
+				RequiredWith:     []string{"data_interval_end"},
+				ValidateFunc:     validation.IsRFC3339Time,
+				StateFunc:        normalizeTime,
+				DiffSuppressFunc: suppressEquivalentTimeDiff,
+			},
+			"data_interval_end": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Computed:         true,
+				RequiredWith:     []string{"data_interval_start"},
+				ValidateFunc:     validation.IsRFC3339Time,
+				StateFunc:        normalizeTime,
+				DiffSuppressFunc: suppressEquivalentTimeDiff,
+			},
+			"run_type": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"note": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"wait_for_completion": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"fail_on_state": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"on_destroy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      dagRunOnDestroyDelete,
+				ValidateFunc: validation.StringInSlice([]string{dagRunOnDestroyDelete, dagRunOnDestroyKeep}, false),
+			},
 			"state": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"start_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"end_date": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"duration": {
+				Type:     schema.TypeFloat,
+				Computed: true,
+			},
+			"ui_url": uiUrlSchema(),
 		},
 	}
 }
@@ -57,33 +149,121 @@ func resourceDagRunCreate(d *schema.ResourceData, m interface{}) error {
 
 	if v, ok := d.GetOk("dag_run_id"); ok {
 		dagRun.SetDagRunId(v.(string))
+	} else if v, ok := d.GetOk("dag_run_id_prefix"); ok {
+		// Every create, including a replacement, triggers a run with a new ID.
+		dagRun.SetDagRunId(resource.PrefixedUniqueId(v.(string)))
 	}
 
 	if v, ok := d.GetOk("conf"); ok {
 		dagRun.SetConf(v.(map[string]interface{}))
 	}
 
-	res, _, err := client.PostDagRun(pcfg.AuthContext, dagId).DAGRun(dagRun).Execute()
-	if err != nil {
+	// Without a data interval, Airflow derives one from the logical date and
+	// the DAG schedule.
+	for key, set := range map[string]func(time.Time){
+		"logical_date":        dagRun.SetLogicalDate,
+		"data_interval_start": dagRun.SetDataIntervalStart,
+		"data_interval_end":   dagRun.SetDataIntervalEnd,
+	} {
+		if v, ok := d.GetOk(key); ok {
+			t, err := time.Parse(time.RFC3339, v.(string))
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", key, err)
+			}
+			set(t)
+		}
+	}
+
+	var res airflow.DAGRun
+	resp, err := retryWhileDagNotFound(pcfg, dagId, func() (*http.Response, error) {
+		var resp *http.Response
+		var err error
+		res, resp, err = client.PostDagRun(pcfg.AuthContext, dagId).DAGRun(dagRun).Execute()
+		return resp, err
+	})
+	if resp != nil && resp.StatusCode == http.StatusConflict && dagRun.DagRunId.IsSet() {
+		// A run with the given ID was already triggered, e.g. by an apply
+		// that failed while waiting for it. It is adopted instead of
+		// triggering the DAG twice, as long as it is the configured run.
+		if reason := dagRunNotAdoptable(pcfg, dagId, dagRun); reason != "" {
+			return fmt.Errorf("failed to create Dag Run `%s` from Airflow, %s: %w", dagId, reason, err)
+		}
+		log.Printf("[INFO] Dag Run `%s` of `%s` already exists, adopting it", *dagRun.DagRunId.Get(), dagId)
+		d.SetId(fmt.Sprintf("%s:%s", dagId, *dagRun.DagRunId.Get()))
+	} else if err != nil {
 		return fmt.Errorf("failed to create Dag Run `%s` from Airflow: %w", dagId, err)
+	} else {
+		d.SetId(fmt.Sprintf("%s:%s", dagId, *res.DagRunId.Get()))
+	}
+
+	if v, ok := d.GetOk("note"); ok {
+		if err := setDagRunNote(pcfg, d.Id(), v.(string)); err != nil {
+			return err
+		}
+	}
+
+	if !d.Get("wait_for_completion").(bool) {
+		return resourceDagRunRead(d, m)
+	}
+
+	failOnState := []string{"failed"}
+	if v, ok := d.GetOk("fail_on_state"); ok {
+		failOnState = expandStringSet(v.(*schema.Set))
 	}
-	d.SetId(fmt.Sprintf("%s:%s", dagId, *res.DagRunId.Get()))
 
 	stateConf := &resource.StateChangeConf{
-		Pending: []string{"queued", "running", "success"},
-		Target:  []string{"success"},
+		Pending: []string{"queued", "running"},
+		Target:  append([]string{"success", "failed"}, failOnState...),
 		Refresh: resourceDagRunStateRefreshFunc(d.Id(), pcfg.AuthContext, client),
 		Timeout: d.Timeout(schema.TimeoutCreate),
 	}
 
-	_, err = stateConf.WaitForStateContext(pcfg.AuthContext)
+	raw, err := stateConf.WaitForStateContext(pcfg.AuthContext)
 	if err != nil {
 		return fmt.Errorf("error waiting for Dag Run %q to finish: %s", d.Id(), err)
 	}
 
+	// The apply fails so that resources depending on a successful run
+	// aren't created. The run is recorded in state as tainted and is
+	// triggered again by the next apply.
+	finished := raw.(airflow.DAGRun)
+	state := string(finished.GetState())
+	for _, s := range failOnState {
+		if state == s {
+			if err := resourceDagRunRead(d, m); err != nil {
+				return err
+			}
+			return fmt.Errorf("Dag Run %q finished in state `%s`", d.Id(), state)
+		}
+	}
+
 	return resourceDagRunRead(d, m)
 }
 
+// dagRunNotAdoptable returns why the existing run with the ID of a run that
+// conflicted on create can't be adopted, or an empty string if it can.
+func dagRunNotAdoptable(pcfg ProviderConfig, dagId string, requested airflow.DAGRun) string {
+	dagRunId := *requested.DagRunId.Get()
+	existing, _, err := pcfg.ApiClient.DAGRunApi.GetDagRun(pcfg.AuthContext, dagId, dagRunId).Execute()
+	if err != nil {
+		return fmt.Sprintf("the existing run `%s` can't be read: %s", dagRunId, err)
+	}
+
+	if existing.GetDagId() != dagId {
+		return fmt.Sprintf("the existing run `%s` belongs to DAG `%s`", dagRunId, existing.GetDagId())
+	}
+	if !dagRunConfMatches(requested.GetConf(), existing.GetConf()) {
+		return fmt.Sprintf("the existing run `%s` was triggered with another conf", dagRunId)
+	}
+	if want, ok := requested.GetLogicalDateOk(); ok && want != nil {
+		if got := existing.GetLogicalDate(); !got.Equal(*want) {
+			return fmt.Sprintf("the existing run `%s` has the logical date %s", dagRunId, got.Format(time.RFC3339))
+		}
+	}
+
+	return ""
+}
+
 func resourceDagRunRead(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient.DAGRunApi
@@ -99,17 +279,150 @@ func resourceDagRunRead(d *schema.ResourceData, m interface{}) error {
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get dagRunId `%s` from Airflow: %w", d.Id(), err)
+		return fmt.Errorf("failed to get dagRunId `%s` from Airflow: %w", d.Id(), apiPermissionError(resp, err, "can_read on DAG Runs"))
 	}
 
 	d.Set("dag_id", dagRun.DagId)
 	d.Set("dag_run_id", dagRun.DagRunId.Get())
-	d.Set("conf", dagRun.Conf)
+	// conf keeps the requested conf, as Airflow may record a different one,
+	// e.g. with the defaults of the DAG params, which would otherwise show up
+	// as drift replacing the run.
+	conf := dagRun.GetConf()
+	if conf == nil {
+		conf = map[string]interface{}{}
+	}
+	recordedConf, err := json.Marshal(conf)
+	if err != nil {
+		return fmt.Errorf("failed to encode the conf of Dag Run `%s`: %w", d.Id(), err)
+	}
+	d.Set("recorded_conf", string(recordedConf))
+	d.Set("conf_matches", dagRunConfMatches(d.Get("conf").(map[string]interface{}), conf))
 	d.Set("state", dagRun.State)
+	d.Set("logical_date", formatDagRunTime(dagRun.LogicalDate))
+	d.Set("data_interval_start", formatDagRunTime(dagRun.DataIntervalStart))
+	d.Set("data_interval_end", formatDagRunTime(dagRun.DataIntervalEnd))
+	d.Set("run_type", dagRun.GetRunType())
+	d.Set("start_date", formatDagRunTime(dagRun.StartDate))
+	d.Set("end_date", formatDagRunTime(dagRun.EndDate))
+	d.Set("duration", dagRunDuration(dagRun))
+	d.Set("ui_url", airflowUiUrl(m, "dag_run", dagId, dagRunId))
+
+	// The API client predates notes, so they are only read, with a call of
+	// their own, when one is managed.
+	if d.Get("note").(string) != "" {
+		var note struct {
+			Note *string `json:"note"`
+		}
+		path := fmt.Sprintf("/dags/%s/dagRuns/%s", url.PathEscape(dagId), url.PathEscape(dagRunId))
+		if _, err := apiRequest(pcfg, http.MethodGet, path, nil, nil, &note); err != nil {
+			return fmt.Errorf("failed to get the note of dagRunId `%s` from Airflow: %w", d.Id(), err)
+		}
+		d.Set("note", note.Note)
+	}
 
 	return nil
 }
 
+// resourceDagRunUpdate handles the note and the arguments controlling how a
+// run is waited for. Every other change triggers a new run.
+func resourceDagRunUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	if d.HasChange("note") {
+		if err := setDagRunNote(pcfg, d.Id(), d.Get("note").(string)); err != nil {
+			return err
+		}
+	}
+
+	return resourceDagRunRead(d, m)
+}
+
+// setDagRunNote sets the note of a run, which requires Airflow 2.5 or later.
+func setDagRunNote(pcfg ProviderConfig, id, note string) error {
+	dagId, dagRunId, err := airflowDagRunId(id)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/dags/%s/dagRuns/%s/setNote", url.PathEscape(dagId), url.PathEscape(dagRunId))
+	if _, err := apiRequest(pcfg, http.MethodPatch, path, nil, map[string]interface{}{"note": note}, nil); err != nil {
+		return fmt.Errorf("failed to set the note of dagRunId `%s` from Airflow: %w", id, err)
+	}
+
+	return nil
+}
+
+// dagRunConfMatches returns whether Airflow recorded every key of the
+// requested conf with the requested value. Values of the requested conf are
+// strings, so recorded values of other types are compared as JSON.
+func dagRunConfMatches(requested, recorded map[string]interface{}) bool {
+	for k, v := range requested {
+		r, ok := recorded[k]
+		if !ok {
+			return false
+		}
+		if s, ok := r.(string); ok {
+			if s != v.(string) {
+				return false
+			}
+			continue
+		}
+		encoded, err := json.Marshal(r)
+		if err != nil || string(encoded) != v.(string) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dagRunDuration returns the number of seconds a finished run took, or 0
+// while it is still running.
+func dagRunDuration(dagRun airflow.DAGRun) float64 {
+	start, end := dagRun.StartDate.Get(), dagRun.EndDate.Get()
+	if start == nil || end == nil {
+		return 0
+	}
+
+	return end.Sub(*start).Seconds()
+}
+
+// suppressEquivalentTimeDiff suppresses the diff of timestamps that denote
+// the same instant, as Airflow returns them in UTC.
+func suppressEquivalentTimeDiff(k, oldo, newo string, d *schema.ResourceData) bool {
+	o, err := time.Parse(time.RFC3339, oldo)
+	if err != nil {
+		return false
+	}
+	n, err := time.Parse(time.RFC3339, newo)
+	if err != nil {
+		return false
+	}
+
+	return o.Equal(n)
+}
+
+// normalizeTime stores configured timestamps in UTC, like the timestamps
+// read from Airflow, so that e.g. `+00:00` and `Z` don't show up as drift.
+func normalizeTime(v interface{}) string {
+	t, err := time.Parse(time.RFC3339, v.(string))
+	if err != nil {
+		return v.(string)
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// formatDagRunTime formats a timestamp of Airflow in UTC. Airflow returns
+// timestamps with a `+00:00` offset, which would otherwise be kept.
+func formatDagRunTime(t airflow.NullableTime) string {
+	if t.Get() == nil {
+		return ""
+	}
+
+	return t.Get().UTC().Format(time.RFC3339Nano)
+}
+
 func resourceDagRunDelete(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient.DAGRunApi
@@ -119,6 +432,11 @@ func resourceDagRunDelete(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
+	if d.Get("on_destroy").(string) == dagRunOnDestroyKeep {
+		log.Printf("[INFO] Keeping Dag Run `%s` in Airflow, only removing it from state", d.Id())
+		return nil
+	}
+
 	resp, err := client.DeleteDagRun(pcfg.AuthContext, dagId, dagRunId).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to delete dagRunId `%s` from Airflow: %w", d.Id(), err)
@@ -156,3 +474,11 @@ func resourceDagRunStateRefreshFunc(id string, pcfg context.Context, client *air
 		return dagRun, string(dagRun.GetState()), nil
 	}
 }
+
+func expandStringSet(tfSet *schema.Set) []string {
+	vs := make([]string, 0, tfSet.Len())
+	for _, v := range tfSet.List() {
+		vs = append(vs, v.(string))
+	}
+	return vs
+}
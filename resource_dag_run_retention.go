@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// dagRunRetentionStates are the states of the runs that are deleted. Runs
+// that haven't finished are always kept.
+var dagRunRetentionStates = []string{"success", "failed"}
+
+// resourceDagRunRetention deletes the finished runs of DAGs that are older
+// than max_age or beyond the newest max_runs, e.g. the runs left behind by
+// airflow_dag_run bootstrap runs. Expired runs are found on every refresh
+// and deleted by the following apply.
+func resourceDagRunRetention() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceDagRunRetentionCreate,
+		Read:   resourceDagRunRetentionRead,
+		Update: resourceDagRunRetentionUpdate,
+		Delete: resourceDagRunRetentionDelete,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			if len(d.Get("expired_runs").([]interface{})) > 0 {
+				return d.SetNewComputed("expired_runs")
+			}
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			"dag_ids": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"max_age": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				AtLeastOneOf: []string{"max_age", "max_runs"},
+			},
+			"max_runs": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"run_types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{"backfill", "manual", "scheduled", "dataset_triggered"}, false),
+				},
+			},
+			"expired_runs": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceDagRunRetentionCreate(d *schema.ResourceData, m interface{}) error {
+	d.SetId(resource.UniqueId())
+
+	return resourceDagRunRetentionUpdate(d, m)
+}
+
+func resourceDagRunRetentionRead(d *schema.ResourceData, m interface{}) error {
+	expired, err := findExpiredDagRuns(d, m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	d.Set("expired_runs", expired)
+
+	return nil
+}
+
+func resourceDagRunRetentionUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient.DAGRunApi
+
+	expired, err := findExpiredDagRuns(d, m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range expired {
+		dagId, dagRunId, err := airflowDagRunId(id)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[INFO] Deleting expired Dag Run `%s`", id)
+		resp, err := client.DeleteDagRun(pcfg.AuthContext, dagId, dagRunId).Execute()
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("failed to delete dagRunId `%s` from Airflow: %w", id, err)
+		}
+	}
+
+	return resourceDagRunRetentionRead(d, m)
+}
+
+// resourceDagRunRetentionDelete only removes the retention from state, the
+// remaining runs are kept.
+func resourceDagRunRetentionDelete(d *schema.ResourceData, m interface{}) error {
+	return nil
+}
+
+// findExpiredDagRuns returns the `dag_id:dag_run_id` of the finished runs of
+// the DAGs that ended before now minus max_age, or are older than the newest
+// max_runs runs. Runs of DAGs that don't exist are ignored.
+func findExpiredDagRuns(d *schema.ResourceData, m interface{}, now time.Time) ([]string, error) {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient.DAGRunApi
+
+	var cutoff time.Time
+	if v, ok := d.GetOk("max_age"); ok {
+		maxAge, _ := time.ParseDuration(v.(string))
+		cutoff = now.Add(-maxAge)
+	}
+	maxRuns := d.Get("max_runs").(int)
+
+	runTypes := map[string]bool{}
+	for _, v := range d.Get("run_types").(*schema.Set).List() {
+		runTypes[v.(string)] = true
+	}
+
+	dagIds := expandStringSet(d.Get("dag_ids").(*schema.Set))
+	sort.Strings(dagIds)
+
+	expired := []string{}
+	for _, dagId := range dagIds {
+		kept := 0
+		key := func(run airflow.DAGRun) string { return run.GetDagRunId() }
+		err := forEachPage("dag runs", key, func(limit, offset int32) ([]airflow.DAGRun, int32, error) {
+			page, resp, err := client.GetDagRuns(pcfg.AuthContext, dagId).Limit(limit).Offset(offset).OrderBy("-execution_date").Execute()
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, 0, nil
+			}
+			return page.GetDagRuns(), page.GetTotalEntries(), apiPermissionError(resp, err, "can_read on DAG Runs")
+		}, func(run airflow.DAGRun) bool {
+			if len(runTypes) > 0 && !runTypes[run.GetRunType()] {
+				return true
+			}
+
+			finished := false
+			for _, s := range dagRunRetentionStates {
+				finished = finished || string(run.GetState()) == s
+			}
+			tooOld := !cutoff.IsZero() && run.EndDate.Get() != nil && run.EndDate.Get().Before(cutoff)
+			beyondCount := maxRuns > 0 && kept >= maxRuns
+
+			if finished && (tooOld || beyondCount) {
+				expired = append(expired, dagId+":"+run.GetDagRunId())
+			} else {
+				kept++
+			}
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the runs of DAG `%s` from Airflow: %w", dagId, err)
+		}
+	}
+
+	return expired, nil
+}
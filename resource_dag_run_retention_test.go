@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceDagRunRetention_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	now := time.Now().UTC()
+	ended := func(hours int) string { return now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+	runs := []interface{}{
+		map[string]interface{}{"dag_id": "bootstrap", "dag_run_id": "run-4", "state": "running", "run_type": "manual"},
+		map[string]interface{}{"dag_id": "bootstrap", "dag_run_id": "run-3", "state": "success", "run_type": "manual", "end_date": ended(1)},
+		map[string]interface{}{"dag_id": "bootstrap", "dag_run_id": "sched-1", "state": "success", "run_type": "scheduled", "end_date": ended(2)},
+		map[string]interface{}{"dag_id": "bootstrap", "dag_run_id": "run-2", "state": "success", "run_type": "manual", "end_date": ended(48)},
+		map[string]interface{}{"dag_id": "bootstrap", "dag_run_id": "run-1", "state": "failed", "run_type": "manual", "end_date": ended(100)},
+	}
+	fake.handle(http.MethodGet, "/dags/bootstrap/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("order_by"); got != "-execution_date" {
+			t.Errorf("expected runs ordered by -execution_date, got %q", got)
+		}
+		page := runs
+		if r.URL.Query().Get("offset") != "0" {
+			page = nil
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_runs": page, "total_entries": len(runs)})
+	})
+	var deleted []string
+	for _, id := range []string{"run-1", "run-2", "run-3"} {
+		id := id
+		fake.handle(http.MethodDelete, "/dags/bootstrap/dagRuns/"+id, func(w http.ResponseWriter, r *http.Request) {
+			deleted = append(deleted, id)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+	fake.handle(http.MethodGet, "/dags/missing/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowError(w, http.StatusNotFound, "DAG not found")
+	})
+
+	cases := []struct {
+		raw      map[string]interface{}
+		expected []string
+	}{
+		{
+			raw:      map[string]interface{}{"max_runs": 2, "run_types": []interface{}{"manual"}},
+			expected: []string{"bootstrap:run-2", "bootstrap:run-1"},
+		},
+		{
+			raw:      map[string]interface{}{"max_age": "24h"},
+			expected: []string{"bootstrap:run-2", "bootstrap:run-1"},
+		},
+		{
+			raw:      map[string]interface{}{"max_runs": 4, "max_age": "72h"},
+			expected: []string{"bootstrap:run-1"},
+		},
+	}
+	for _, c := range cases {
+		c.raw["dag_ids"] = []interface{}{"bootstrap", "missing"}
+		d := schema.TestResourceDataRaw(t, resourceDagRunRetention().Schema, c.raw)
+		expired, err := findExpiredDagRuns(d, m, now)
+		if err != nil {
+			t.Fatalf("%v: %s", c.raw, err)
+		}
+		if !reflect.DeepEqual(expired, c.expected) {
+			t.Fatalf("%v: expected %v to expire, got %v", c.raw, c.expected, expired)
+		}
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceDagRunRetention().Schema, map[string]interface{}{
+		"dag_ids":  []interface{}{"bootstrap"},
+		"max_runs": 4,
+		"max_age":  "72h",
+	})
+	if err := resourceDagRunRetentionCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"run-1"}) {
+		t.Fatalf("expected run-1 to be deleted, got %v", deleted)
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -162,3 +167,384 @@ resource "airflow_dag_run" "test" {
 }
 `, dagId)
 }
+
+func TestResourceDagRun_fakeAdoptExisting(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowError(w, http.StatusConflict, "DAGRun with DAG ID: 'example' and DAGRun ID: 'run-1' already exists")
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run-1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_id":     "example",
+			"dag_run_id": "run-1",
+			"state":      "success",
+			"conf":       map[string]interface{}{},
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, map[string]interface{}{
+		"dag_id":     "example",
+		"dag_run_id": "run-1",
+	})
+	if err := resourceDagRunCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if d.Id() != "example:run-1" {
+		t.Fatalf("expected the existing run to be adopted, got ID %q", d.Id())
+	}
+	if got := d.Get("state").(string); got != "success" {
+		t.Fatalf("expected state success, got %q", got)
+	}
+	if got := fake.requestCount(http.MethodPost, "/dags/example/dagRuns"); got != 1 {
+		t.Fatalf("expected a single trigger attempt, got %d", got)
+	}
+}
+
+func TestResourceDagRun_fakeAdoptMismatch(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowError(w, http.StatusConflict, "DAGRun with DAG ID: 'example' and DAGRun ID: 'run-1' already exists")
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run-1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_id":       "example",
+			"dag_run_id":   "run-1",
+			"state":        "success",
+			"logical_date": "2024-01-01T00:00:00Z",
+			"conf":         map[string]interface{}{"env": "staging"},
+		})
+	})
+
+	for name, tc := range map[string]struct {
+		raw    map[string]interface{}
+		reason string
+	}{
+		"conf": {
+			raw:    map[string]interface{}{"conf": map[string]interface{}{"env": "prod"}},
+			reason: "another conf",
+		},
+		"logical_date": {
+			raw:    map[string]interface{}{"logical_date": "2024-02-01T00:00:00Z"},
+			reason: "the logical date 2024-01-01T00:00:00Z",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			raw := map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1"}
+			for k, v := range tc.raw {
+				raw[k] = v
+			}
+			d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, raw)
+			err := resourceDagRunCreate(d, m)
+			if err == nil || !strings.Contains(err.Error(), tc.reason) || !strings.Contains(err.Error(), "409") {
+				t.Fatalf("expected the conflict to be returned, got %v", err)
+			}
+			if d.Id() != "" {
+				t.Fatalf("expected the run not to be adopted, got ID %q", d.Id())
+			}
+		})
+	}
+
+	// A run matching the configuration is adopted.
+	d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, map[string]interface{}{
+		"dag_id":       "example",
+		"dag_run_id":   "run-1",
+		"logical_date": "2024-01-01T00:00:00Z",
+		"conf":         map[string]interface{}{"env": "staging"},
+	})
+	if err := resourceDagRunCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if d.Id() != "example:run-1" {
+		t.Fatalf("expected the existing run to be adopted, got ID %q", d.Id())
+	}
+}
+
+func TestResourceDagRun_fakeFailOnState(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "state": "queued"})
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run-1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_id":     "example",
+			"dag_run_id": "run-1",
+			"state":      "failed",
+			"start_date": "2022-05-01T10:00:00+00:00",
+			"end_date":   "2022-05-01T10:01:30.5+00:00",
+		})
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, map[string]interface{}{
+		"dag_id":        "example",
+		"fail_on_state": []interface{}{"failed"},
+	})
+	err := resourceDagRunCreate(d, m)
+	if err == nil {
+		t.Fatal("expected create to fail for a failed run")
+	}
+
+	if d.Id() != "example:run-1" {
+		t.Fatalf("expected the run to be recorded in state, got ID %q", d.Id())
+	}
+	if got := d.Get("state").(string); got != "failed" {
+		t.Fatalf("expected state failed, got %q", got)
+	}
+	if got := d.Get("duration").(float64); got != 90.5 {
+		t.Fatalf("expected a duration of 90.5s, got %v", got)
+	}
+	if got := d.Get("end_date").(string); got != "2022-05-01T10:01:30.5Z" {
+		t.Fatalf("unexpected end date %q", got)
+	}
+}
+
+func TestResourceDagRun_fakeNoWait(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "state": "queued"})
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run-1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "state": "queued"})
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, map[string]interface{}{
+		"dag_id":              "example",
+		"wait_for_completion": false,
+	})
+	if err := resourceDagRunCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("state").(string); got != "queued" {
+		t.Fatalf("expected state queued, got %q", got)
+	}
+}
+
+func TestResourceDagRun_fakeNote(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	note := ""
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "state": "queued"})
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run-1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "state": "queued", "note": note})
+	})
+	fake.handle(http.MethodPatch, "/dags/example/dagRuns/run-1/setNote", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Note string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeAirflowError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		note = body.Note
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "note": note})
+	})
+
+	r := resourceDagRun()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"dag_id":              "example",
+		"wait_for_completion": false,
+		"note":                "seeded by terraform",
+	})
+	if err := resourceDagRunCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if note != "seeded by terraform" {
+		t.Fatalf("expected the note to be set, got %q", note)
+	}
+
+	note = "changed in the UI"
+	if err := resourceDagRunRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("note").(string); got != "changed in the UI" {
+		t.Fatalf("expected the changed note in state, got %q", got)
+	}
+
+	d = testResourceDataUpdate(t, r, d.State(), map[string]interface{}{
+		"dag_id":              "example",
+		"wait_for_completion": false,
+		"note":                "migrated",
+	}, m)
+	if err := resourceDagRunUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if note != "migrated" {
+		t.Fatalf("expected the note to be updated, got %q", note)
+	}
+}
+
+func TestResourceDagRun_fakeRecordedConf(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	// Airflow records the defaults of the DAG params along with the
+	// requested conf.
+	recorded := map[string]interface{}{"dag_id": "example", "dag_run_id": "run-1", "state": "queued", "conf": map[string]interface{}{"target": "prod", "batch_size": 100}}
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, recorded)
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/run-1", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, recorded)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, map[string]interface{}{
+		"dag_id":              "example",
+		"conf":                map[string]interface{}{"target": "prod"},
+		"wait_for_completion": false,
+	})
+	if err := resourceDagRunCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("conf").(map[string]interface{}); len(got) != 1 || got["target"] != "prod" {
+		t.Fatalf("expected the requested conf to be kept, got %v", got)
+	}
+	if got := d.Get("recorded_conf").(string); got != `{"batch_size":100,"target":"prod"}` {
+		t.Fatalf("unexpected recorded_conf %s", got)
+	}
+	if !d.Get("conf_matches").(bool) {
+		t.Fatal("expected the requested conf to match the recorded one")
+	}
+
+	if dagRunConfMatches(map[string]interface{}{"batch_size": "10"}, map[string]interface{}{"batch_size": float64(100)}) {
+		t.Fatal("expected a changed value not to match")
+	}
+	if !dagRunConfMatches(map[string]interface{}{"batch_size": "100"}, map[string]interface{}{"batch_size": float64(100)}) {
+		t.Fatal("expected a number to match its JSON encoding")
+	}
+}
+
+func TestResourceDagRun_fakeDataInterval(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var posted map[string]interface{}
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"dag_id": "example", "dag_run_id": "backfill", "state": "queued"})
+	})
+	fake.handle(http.MethodGet, "/dags/example/dagRuns/backfill", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"dag_id":              "example",
+			"dag_run_id":          "backfill",
+			"state":               "queued",
+			"run_type":            "manual",
+			"logical_date":        "2022-05-01T00:00:00+00:00",
+			"data_interval_start": "2022-05-01T00:00:00+00:00",
+			"data_interval_end":   "2022-05-02T00:00:00+00:00",
+		})
+	})
+
+	raw := map[string]interface{}{
+		"dag_id":              "example",
+		"dag_run_id":          "backfill",
+		"logical_date":        "2022-05-01T02:00:00+02:00",
+		"data_interval_start": "2022-05-01T00:00:00Z",
+		"data_interval_end":   "2022-05-02T00:00:00Z",
+		"wait_for_completion": false,
+	}
+	d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, raw)
+	if err := resourceDagRunCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if posted["data_interval_start"] != "2022-05-01T00:00:00Z" || posted["data_interval_end"] != "2022-05-02T00:00:00Z" {
+		t.Fatalf("expected the data interval to be sent, got %v", posted)
+	}
+	if got := d.Get("run_type").(string); got != "manual" {
+		t.Fatalf("expected run type manual, got %q", got)
+	}
+	if got := d.Get("logical_date").(string); got != "2022-05-01T00:00:00Z" {
+		t.Fatalf("expected the logical date in UTC, got %q", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceDagRun(), d.State(), raw, m)
+	if d.HasChanges("logical_date", "data_interval_start", "data_interval_end") {
+		t.Fatal("expected equivalent timestamps not to cause a diff")
+	}
+}
+
+func TestResourceDagRun_fakeReplaceWithPrefix(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var runIds []string
+	fake.handle(http.MethodPost, "/dags/example/dagRuns", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		runId := body["dag_run_id"].(string)
+		runIds = append(runIds, runId)
+
+		fake.handle(http.MethodGet, "/dags/example/dagRuns/"+runId, func(w http.ResponseWriter, r *http.Request) {
+			writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+				"dag_id":     "example",
+				"dag_run_id": runId,
+				"state":      "success",
+			})
+		})
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	raw := map[string]interface{}{
+		"dag_id":            "example",
+		"dag_run_id_prefix": "rerun-",
+		"on_destroy":        "keep",
+	}
+
+	// A replacement destroys the old run and creates a new one.
+	for i := 0; i < 2; i++ {
+		d := schema.TestResourceDataRaw(t, resourceDagRun().Schema, raw)
+		if err := resourceDagRunCreate(d, m); err != nil {
+			t.Fatalf("create %d: %s", i, err)
+		}
+		if !strings.HasPrefix(d.Get("dag_run_id").(string), "rerun-") {
+			t.Fatalf("expected a prefixed dag_run_id, got %q", d.Get("dag_run_id"))
+		}
+		if err := resourceDagRunDelete(d, m); err != nil {
+			t.Fatalf("delete %d: %s", i, err)
+		}
+	}
+
+	if len(runIds) != 2 || runIds[0] == runIds[1] {
+		t.Fatalf("expected two runs with distinct IDs, got %v", runIds)
+	}
+	if got := fake.requestCount(http.MethodDelete, "/dags/example/dagRuns"); got != 0 {
+		t.Fatalf("expected the runs to be kept in Airflow, got %d deletes", got)
+	}
+}
+
+func TestResourceDagRun_import(t *testing.T) {
+	for _, id := range []string{"example:manual__2024-01-01T00:00:00+00:00", "example/manual__2024-01-01T00:00:00+00:00"} {
+		d := resourceDagRun().Data(nil)
+		d.SetId(id)
+
+		ds, err := resourceDagRunImport(context.Background(), d, nil)
+		if err != nil {
+			t.Fatalf("import %s: %s", id, err)
+		}
+		if got := ds[0].Id(); got != "example:manual__2024-01-01T00:00:00+00:00" {
+			t.Fatalf("expected the canonical ID, got %q", got)
+		}
+		if ds[0].Get("dag_id").(string) != "example" || ds[0].Get("dag_run_id").(string) != "manual__2024-01-01T00:00:00+00:00" {
+			t.Fatalf("unexpected imported attributes for %s", id)
+		}
+	}
+
+	for _, id := range []string{"example", ":run", "example:", "bad dag:run"} {
+		d := resourceDagRun().Data(nil)
+		d.SetId(id)
+		if _, err := resourceDagRunImport(context.Background(), d, nil); err == nil {
+			t.Fatalf("expected an error importing %q", id)
+		}
+	}
+}
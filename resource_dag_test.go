@@ -1,11 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -89,3 +94,173 @@ resource "airflow_dag" "test" {
 }
 `, paused)
 }
+
+func TestResourceDag_fakeMetadata(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	dag := map[string]interface{}{
+		"dag_id":           "example",
+		"is_paused":        true,
+		"owners":           []interface{}{"data-eng"},
+		"tags":             []interface{}{map[string]interface{}{"name": "team:b"}, map[string]interface{}{"name": "etl"}},
+		"max_active_runs":  3,
+		"max_active_tasks": 16,
+	}
+	fake.handle(http.MethodPatch, "/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("update_mask"); got != "is_paused" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected update_mask "+got)
+			return
+		}
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+	fake.handle(http.MethodGet, "/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDag().Schema, map[string]interface{}{
+		"dag_id":    "example",
+		"is_paused": true,
+	})
+	if err := resourceDagUpdate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if got := d.Get("tags").([]interface{}); !reflect.DeepEqual(got, []interface{}{"etl", "team:b"}) {
+		t.Fatalf("unexpected tags %v", got)
+	}
+	if got := d.Get("owners").([]interface{}); !reflect.DeepEqual(got, []interface{}{"data-eng"}) {
+		t.Fatalf("unexpected owners %v", got)
+	}
+	if d.Get("max_active_runs").(int) != 3 || d.Get("max_active_tasks").(int) != 16 {
+		t.Fatalf("unexpected limits %v %v", d.Get("max_active_runs"), d.Get("max_active_tasks"))
+	}
+}
+
+func TestResourceDag_fakeAirflow3(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	dag := map[string]interface{}{
+		"dag_id":         "example",
+		"is_paused":      true,
+		"is_stale":       false,
+		"bundle_name":    "dags-repo",
+		"bundle_version": "4f1c2ab",
+	}
+	fake.playAirflow3("3.0.2")
+	fake.handle(http.MethodPatch, "/api/v2/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("update_mask") != "is_paused" {
+			writeFakeAirflowError(w, http.StatusBadRequest, "unexpected update_mask "+r.URL.Query().Get("update_mask"))
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeAirflowError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		dag["is_paused"] = body["is_paused"]
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+	fake.handle(http.MethodGet, "/api/v2/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDag().Schema, map[string]interface{}{
+		"dag_id":    "example",
+		"is_paused": false,
+	})
+	if err := resourceDagUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if dag["is_paused"] != false || d.Get("is_paused").(bool) {
+		t.Fatal("expected the DAG to be unpaused")
+	}
+	if !d.Get("is_active").(bool) {
+		t.Fatal("expected a DAG that isn't stale to be active")
+	}
+	if d.Get("bundle_name").(string) != "dags-repo" || d.Get("bundle_version").(string) != "4f1c2ab" {
+		t.Fatalf("unexpected bundle %q at %q", d.Get("bundle_name"), d.Get("bundle_version"))
+	}
+	if got := fake.requestCount("", "/dags"); got != 0 {
+		t.Fatalf("expected no requests to the stable API, got %d", got)
+	}
+}
+
+func TestResourceDag_fakeUpdateStatus(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0})
+
+	dag := map[string]interface{}{"dag_id": "example", "is_paused": true, "is_stale": false}
+	fake.playAirflow3("3.0.2")
+	status := http.StatusNoContent
+	fake.handle(http.MethodPatch, "/api/v2/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusNoContent {
+			writeFakeAirflowError(w, status, "forbidden")
+			return
+		}
+		dag["is_paused"] = false
+		w.WriteHeader(status)
+	})
+	fake.handle(http.MethodGet, "/api/v2/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDag().Schema, map[string]interface{}{
+		"dag_id":    "example",
+		"is_paused": false,
+	})
+	if err := resourceDagUpdate(d, m); err != nil {
+		t.Fatalf("expected a 204 to be accepted, got %s", err)
+	}
+	if d.Get("is_paused").(bool) {
+		t.Fatal("expected the DAG to be unpaused")
+	}
+
+	status = http.StatusForbidden
+	err := resourceDagUpdate(d, m)
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "can_edit on DAG:example") {
+		t.Fatalf("expected the error of the update, got %v", err)
+	}
+}
+
+func TestResourceDag_fakePauseOnDelete(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	dag := map[string]interface{}{"dag_id": "example", "is_paused": false}
+	fake.handle(http.MethodPatch, "/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeFakeAirflowError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		dag["is_paused"] = body["is_paused"]
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+	fake.handle(http.MethodGet, "/dags/example", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, dag)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceDag().Schema, map[string]interface{}{
+		"dag_id":          "example",
+		"is_paused":       false,
+		"pause_on_delete": true,
+	})
+	if err := resourceDagUpdate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if dag["is_paused"] != false {
+		t.Fatal("expected the DAG to be unpaused")
+	}
+
+	if err := resourceDagDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if dag["is_paused"] != true {
+		t.Fatal("expected the DAG to be paused on delete")
+	}
+	if got := fake.requestCount(http.MethodDelete, "/dags/example"); got != 0 {
+		t.Fatalf("expected the DAG not to be deleted, got %d requests", got)
+	}
+}
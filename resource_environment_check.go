@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	goversion "github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceEnvironmentCheck fails the apply with a report of every unmet
+// requirement of the Airflow environment, before the resources depending on
+// it run into them one by one.
+func resourceEnvironmentCheck() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceEnvironmentCheckCreate,
+		Read:   resourceEnvironmentCheckRead,
+		Update: resourceEnvironmentCheckUpdate,
+		Delete: resourceEnvironmentCheckDelete,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			// Check the environment again while it doesn't meet the
+			// requirements.
+			if len(d.Get("failures").([]interface{})) > 0 {
+				return d.SetNewComputed("failures")
+			}
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			"required_airflow_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ValidateFunc: func(v interface{}, k string) (ws []string, errs []error) {
+					if _, err := goversion.NewConstraint(v.(string)); err != nil {
+						errs = append(errs, fmt.Errorf("%q must be a version constraint: %w", k, err))
+					}
+					return
+				},
+			},
+			"required_provider_packages": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"required_permission": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"resource": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+			"username": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"airflow_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provider_packages": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"failures": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceEnvironmentCheckCreate(d *schema.ResourceData, m interface{}) error {
+	if err := checkEnvironment(d, m); err != nil {
+		return err
+	}
+	d.SetId(resource.UniqueId())
+
+	return nil
+}
+
+func resourceEnvironmentCheckRead(d *schema.ResourceData, m interface{}) error {
+	_, err := runEnvironmentCheck(d, m)
+	return err
+}
+
+func resourceEnvironmentCheckUpdate(d *schema.ResourceData, m interface{}) error {
+	return checkEnvironment(d, m)
+}
+
+func resourceEnvironmentCheckDelete(d *schema.ResourceData, m interface{}) error {
+	return nil
+}
+
+// checkEnvironment runs the check and fails with a report of all failures.
+func checkEnvironment(d *schema.ResourceData, m interface{}) error {
+	failures, err := runEnvironmentCheck(d, m)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("the Airflow environment doesn't meet %d requirement(s):\n  - %s", len(failures), strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// runEnvironmentCheck sets the facts about the environment and returns the
+// requirements it doesn't meet. Errors are only returned for failures to set
+// the attributes, API errors are reported as failures.
+func runEnvironmentCheck(d *schema.ResourceData, m interface{}) ([]string, error) {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	var failures []string
+
+	version, _, err := getAirflowVersionInfo(pcfg)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("failed to get the Airflow version: %s", err))
+	}
+	d.Set("airflow_version", version.GetVersion())
+	if v := d.Get("required_airflow_version").(string); v != "" && err == nil {
+		if failure := checkVersionConstraint("Airflow", version.GetVersion(), v); failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+
+	packages := map[string]string{}
+	required := d.Get("required_provider_packages").(map[string]interface{})
+	if len(required) > 0 {
+		providers, _, err := client.ProviderApi.GetProviders(pcfg.AuthContext).Execute()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("failed to get the provider packages: %s", err))
+		}
+		for _, p := range providers.GetProviders() {
+			packages[p.GetPackageName()] = p.GetVersion()
+		}
+
+		names := make([]string, 0, len(required))
+		for name := range required {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			installed, ok := packages[name]
+			switch {
+			case err != nil:
+			case !ok:
+				failures = append(failures, fmt.Sprintf("provider package `%s` isn't installed", name))
+			case required[name].(string) != "":
+				if failure := checkVersionConstraint(fmt.Sprintf("provider package `%s`", name), installed, required[name].(string)); failure != "" {
+					failures = append(failures, failure)
+				}
+			}
+		}
+	}
+	if err := d.Set("provider_packages", packages); err != nil {
+		return nil, fmt.Errorf("error setting provider_packages: %w", err)
+	}
+
+	if permissions := d.Get("required_permission").(*schema.Set); permissions.Len() > 0 {
+		failures = append(failures, checkRequiredPermissions(pcfg, d.Get("username").(string), permissions)...)
+	}
+
+	if err := d.Set("failures", failures); err != nil {
+		return nil, fmt.Errorf("error setting failures: %w", err)
+	}
+
+	return failures, nil
+}
+
+func checkVersionConstraint(what, installed, constraint string) string {
+	c, err := goversion.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Sprintf("invalid version constraint `%s` of %s: %s", constraint, what, err)
+	}
+	v, err := goversion.NewVersion(installed)
+	if err != nil {
+		return fmt.Sprintf("failed to parse version `%s` of %s: %s", installed, what, err)
+	}
+	if !c.Check(v) {
+		return fmt.Sprintf("%s version %s doesn't satisfy `%s`", what, installed, constraint)
+	}
+	return ""
+}
+
+// checkRequiredPermissions returns the required permissions none of the
+// roles of the user grant. The user defaults to the one the provider
+// authenticates as with basic authentication.
+func checkRequiredPermissions(pcfg ProviderConfig, username string, required *schema.Set) []string {
+	if username == "" {
+		if auth, ok := pcfg.AuthContext.Value(airflow.ContextBasicAuth).(airflow.BasicAuth); ok {
+			username = auth.UserName
+		}
+	}
+	if username == "" {
+		return []string{"the user to check the required permissions of is unknown, set `username` when not using basic authentication"}
+	}
+
+	client := pcfg.ApiClient
+	user, resp, err := client.UserApi.GetUser(pcfg.AuthContext, username).Execute()
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return []string{fmt.Sprintf("user `%s` doesn't exist", username)}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("failed to get the roles of user `%s`: %s", username, err)}
+	}
+
+	granted := map[string]bool{}
+	for _, r := range user.GetRoles() {
+		role, _, err := client.RoleApi.GetRole(pcfg.AuthContext, r.GetName()).Execute()
+		if err != nil {
+			return []string{fmt.Sprintf("failed to get the permissions of role `%s`: %s", r.GetName(), err)}
+		}
+		for _, apiObject := range role.GetActions() {
+			granted[apiObject.Action.GetName()+"|"+apiObject.Resource.GetName()] = true
+		}
+	}
+
+	var failures []string
+	for _, v := range required.List() {
+		tfMap := v.(map[string]interface{})
+		action, res := tfMap["action"].(string), tfMap["resource"].(string)
+		if !granted[action+"|"+res] {
+			failures = append(failures, fmt.Sprintf("user `%s` lacks permission `%s` on `%s`", username, action, res))
+		}
+	}
+	sort.Strings(failures)
+	return failures
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceEnvironmentCheck_fake(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": "2.7.3"})
+	})
+	fake.handle(http.MethodGet, "/providers", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{
+			"providers": []interface{}{
+				map[string]interface{}{"package_name": "apache-airflow-providers-amazon", "version": "8.10.0"},
+			},
+			"total_entries": 1,
+		})
+	})
+	fake.seed("roles", map[string]interface{}{
+		"name": "Op",
+		"actions": []interface{}{
+			map[string]interface{}{"action": map[string]interface{}{"name": "can_read"}, "resource": map[string]interface{}{"name": "Connections"}},
+		},
+	})
+	fake.seed("users", map[string]interface{}{
+		"username": "admin",
+		"roles":    []interface{}{map[string]interface{}{"name": "Op"}},
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceEnvironmentCheck().Schema, map[string]interface{}{
+		"required_airflow_version": ">= 2.8.0",
+		"required_provider_packages": map[string]interface{}{
+			"apache-airflow-providers-amazon":    ">= 8.0.0",
+			"apache-airflow-providers-snowflake": "",
+		},
+		"required_permission": []interface{}{
+			map[string]interface{}{"action": "can_read", "resource": "Connections"},
+			map[string]interface{}{"action": "can_edit", "resource": "Connections"},
+		},
+	})
+
+	err := resourceEnvironmentCheckCreate(d, m)
+	if err == nil {
+		t.Fatal("expected the check to fail")
+	}
+	for _, want := range []string{
+		"doesn't meet 3 requirement(s)",
+		"Airflow version 2.7.3 doesn't satisfy `>= 2.8.0`",
+		"provider package `apache-airflow-providers-snowflake` isn't installed",
+		"user `admin` lacks permission `can_edit` on `Connections`",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected the report to contain %q, got:\n%s", want, err)
+		}
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceEnvironmentCheck().Schema, map[string]interface{}{
+		"required_airflow_version": ">= 2.7.0, < 3.0.0",
+		"required_provider_packages": map[string]interface{}{
+			"apache-airflow-providers-amazon": ">= 8.0.0",
+		},
+		"required_permission": []interface{}{
+			map[string]interface{}{"action": "can_read", "resource": "Connections"},
+		},
+	})
+	if err := resourceEnvironmentCheckCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if d.Id() == "" || d.Get("airflow_version").(string) != "2.7.3" || len(d.Get("failures").([]interface{})) != 0 {
+		t.Fatalf("unexpected check state: id %q, version %q, failures %v", d.Id(), d.Get("airflow_version"), d.Get("failures"))
+	}
+	if got := d.Get("provider_packages").(map[string]interface{})["apache-airflow-providers-amazon"]; got != "8.10.0" {
+		t.Fatalf("expected the installed provider packages, got %v", got)
+	}
+}
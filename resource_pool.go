@@ -42,6 +42,8 @@ func resourcePool() *schema.Resource {
 				Type:     schema.TypeInt,
 				Computed: true,
 			},
+			"ui_url":              uiUrlSchema(),
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
@@ -59,26 +61,30 @@ func resourcePoolCreate(d *schema.ResourceData, m interface{}) error {
 		Slots: &slots,
 	}
 
-	_, _, err := varApi.PostPool(pcfg.AuthContext).Pool(pool).Execute()
-	if err != nil {
+	_, resp, err := varApi.PostPool(pcfg.AuthContext).Pool(pool).Execute()
+	if err != nil && !createdDespiteError(d, m, "pool", name, resp, err, resourcePoolRead) {
 		return fmt.Errorf("failed to create pool `%s` from Airflow: %w", name, err)
 	}
 	d.SetId(name)
 
-	return resourcePoolRead(d, m)
+	return readAfterCreate(d, m, "pool", resourcePoolRead)
 }
 
 func resourcePoolRead(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
-	pool, resp, err := client.PoolApi.GetPool(pcfg.AuthContext, d.Id()).Execute()
+	pool, resp, err := getWithListFallback(pcfg, "pool", d.Id(), client.PoolApi.GetPool(pcfg.AuthContext, d.Id()).Execute,
+		func(fn func(airflow.Pool) bool) error {
+			_, err := listAirflowPools(pcfg, fn)
+			return err
+		}, func(p airflow.Pool) string { return p.GetName() })
 	if resp != nil && resp.StatusCode == 404 {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get pool `%s` from Airflow: %w", d.Id(), err)
+		return fmt.Errorf("failed to get pool `%s` from Airflow: %w", d.Id(), apiPermissionError(resp, err, "can_read on Pools"))
 	}
 
 	d.Set("name", pool.Name)
@@ -87,6 +93,7 @@ func resourcePoolRead(d *schema.ResourceData, m interface{}) error {
 	d.Set("queued_slots", pool.QueuedSlots)
 	d.Set("open_slots", pool.OpenSlots)
 	d.Set("used_slots", pool.UsedSlots)
+	d.Set("ui_url", airflowUiUrl(m, "pool", d.Id()))
 
 	return nil
 }
@@ -115,6 +122,10 @@ func resourcePoolDelete(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
+	if err := checkDeletionProtection(d, "pool"); err != nil {
+		return err
+	}
+
 	resp, err := client.PoolApi.DeletePool(pcfg.AuthContext, d.Id()).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to delete pool `%s` from Airflow: %w", d.Id(), err)
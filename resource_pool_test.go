@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -74,3 +75,32 @@ resource "airflow_pool" "test" {
 }
 `, rName, slots)
 }
+
+func TestResourcePool_fakeDeletionProtection(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourcePool().Schema, map[string]interface{}{
+		"name":                "fake-protected",
+		"slots":               2,
+		"deletion_protection": true,
+	})
+	if err := resourcePoolCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if err := resourcePoolDelete(d, m); err == nil {
+		t.Fatal("expected deleting a protected pool to fail")
+	}
+	if fake.object("pools", "fake-protected") == nil {
+		t.Fatal("expected the protected pool to be kept in Airflow")
+	}
+
+	d.Set("deletion_protection", false)
+	if err := resourcePoolDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("pools", "fake-protected") != nil {
+		t.Fatal("pool still exists in Airflow")
+	}
+}
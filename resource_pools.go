@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// airflowDefaultPool is the pool Airflow creates itself and doesn't allow
+// deleting.
+const airflowDefaultPool = "default_pool"
+
+// resourcePools manages many pools in a single resource, e.g. a pool per
+// tenant of a platform module. All pools are read with one paginated list
+// call instead of one call per pool.
+func resourcePools() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePoolsCreate,
+		Read:   resourcePoolsRead,
+		Update: resourcePoolsUpdate,
+		Delete: resourcePoolsDelete,
+		CustomizeDiff: customizePendingChangesDiff("pool", "name", expandAirflowPools,
+			func(p airflow.Pool) string { return p.GetName() }, airflowPoolEqual),
+		Schema: map[string]*schema.Schema{
+			"pool": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"slots": {
+							Type:     schema.TypeInt,
+							Required: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"open_slots": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"authoritative_prefix": authoritativePrefixSchema(),
+			"unmanaged":            unmanagedSchema(),
+			"pending_changes":      pendingChangesSchema(),
+		},
+	}
+}
+
+func resourcePoolsCreate(d *schema.ResourceData, m interface{}) error {
+	pools, err := expandAirflowPools(d.Get("pool").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(resource.UniqueId())
+	calls := make([]func() error, 0, len(pools))
+	for _, pool := range pools {
+		pool := pool
+		calls = append(calls, func() error { return createAirflowPool(m, pool) })
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	// Unmanaged objects are only found by this read and deleted by the
+	// following apply, after the plan showed them.
+	return resourcePoolsRead(d, m)
+}
+
+func resourcePoolsRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	var names []string
+	for _, v := range d.Get("pool").([]interface{}) {
+		names = append(names, v.(map[string]interface{})["name"].(string))
+	}
+
+	remote := map[string]airflow.Pool{}
+	err := listWithGetFallback(pcfg, "pool", names, func(fn func(airflow.Pool) bool) (*http.Response, error) {
+		return listAirflowPools(pcfg, fn)
+	}, func(name string) (airflow.Pool, *http.Response, error) {
+		return client.PoolApi.GetPool(pcfg.AuthContext, name).Execute()
+	}, func(p airflow.Pool) bool {
+		remote[p.GetName()] = p
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keep the order of the state so the list doesn't show a diff. Pools
+	// that were removed outside of Terraform are dropped to be recreated.
+	var pools []interface{}
+	managed := map[string]bool{}
+	for _, v := range d.Get("pool").([]interface{}) {
+		tfMap := v.(map[string]interface{})
+		managed[tfMap["name"].(string)] = true
+		pool, exists := remote[tfMap["name"].(string)]
+		if !exists {
+			log.Printf("[WARN] Pool `%s` not found in Airflow, removing it from state", tfMap["name"])
+			continue
+		}
+
+		pools = append(pools, map[string]interface{}{
+			"name":        pool.GetName(),
+			"slots":       pool.GetSlots(),
+			"description": pool.GetDescription(),
+			"open_slots":  pool.GetOpenSlots(),
+		})
+	}
+
+	if err := d.Set("pool", pools); err != nil {
+		return fmt.Errorf("error setting pool: %w", err)
+	}
+
+	unmanaged := []string{}
+	for name := range remote {
+		if isUnmanaged(d.Get("authoritative_prefix").(string), name, managed[name]) && name != airflowDefaultPool {
+			unmanaged = append(unmanaged, name)
+		}
+	}
+	setUnmanaged(d, unmanaged)
+	d.Set("pending_changes", []interface{}{})
+
+	return nil
+}
+
+// listAirflowPools calls fn for every pool of Airflow. The response is the
+// one of the last page fetched, e.g. to tell whether listing is forbidden.
+func listAirflowPools(pcfg ProviderConfig, fn func(airflow.Pool) bool) (*http.Response, error) {
+	var last *http.Response
+	key := func(p airflow.Pool) string { return p.GetName() }
+	err := forEachPage("pools", key, func(limit, offset int32) ([]airflow.Pool, int32, error) {
+		page, resp, err := pcfg.ApiClient.PoolApi.GetPools(pcfg.AuthContext).Limit(limit).Offset(offset).Execute()
+		last = resp
+		return page.GetPools(), page.GetTotalEntries(), apiPermissionError(resp, err, "can_read on Pools")
+	}, fn)
+	return last, err
+}
+
+func resourcePoolsUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	o, n := d.GetChange("pool")
+	oldPools, err := expandAirflowPools(o.([]interface{}))
+	if err != nil {
+		return err
+	}
+	newPools, err := expandAirflowPools(n.([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	oldByName := make(map[string]airflow.Pool, len(oldPools))
+	for _, pool := range oldPools {
+		oldByName[pool.GetName()] = pool
+	}
+
+	var calls []func() error
+	for _, pool := range newPools {
+		pool, name := pool, pool.GetName()
+		old, exists := oldByName[name]
+		delete(oldByName, name)
+
+		if !exists {
+			calls = append(calls, func() error { return createAirflowPool(m, pool) })
+			continue
+		}
+
+		if airflowPoolEqual(old, pool) {
+			continue
+		}
+
+		calls = append(calls, func() error {
+			if _, _, err := client.PoolApi.PatchPool(pcfg.AuthContext, name).Pool(pool).Execute(); err != nil {
+				return fmt.Errorf("failed to update pool `%s` from Airflow: %w", name, err)
+			}
+			return nil
+		})
+	}
+
+	for name := range oldByName {
+		name := name
+		calls = append(calls, func() error { return deleteAirflowPool(m, name) })
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	if err := deleteUnmanaged(d, m, "pool", "name", func(name string) error { return deleteAirflowPool(m, name) }); err != nil {
+		return err
+	}
+
+	return resourcePoolsRead(d, m)
+}
+
+func resourcePoolsDelete(d *schema.ResourceData, m interface{}) error {
+	var calls []func() error
+	for _, v := range d.Get("pool").([]interface{}) {
+		name := v.(map[string]interface{})["name"].(string)
+		calls = append(calls, func() error { return deleteAirflowPool(m, name) })
+	}
+
+	return runConcurrently(m, calls)
+}
+
+// createAirflowPool creates a pool. The default_pool always exists, so it is
+// updated instead.
+func createAirflowPool(m interface{}, pool airflow.Pool) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	if pool.GetName() == airflowDefaultPool {
+		if _, _, err := client.PoolApi.PatchPool(pcfg.AuthContext, airflowDefaultPool).Pool(pool).Execute(); err != nil {
+			return fmt.Errorf("failed to update pool `%s` from Airflow: %w", airflowDefaultPool, err)
+		}
+		return nil
+	}
+
+	if _, _, err := client.PoolApi.PostPool(pcfg.AuthContext).Pool(pool).Execute(); err != nil {
+		return fmt.Errorf("failed to create pool `%s` from Airflow: %w", pool.GetName(), err)
+	}
+	return nil
+}
+
+// deleteAirflowPool deletes a pool. The default_pool can't be deleted, so it
+// is only left unmanaged.
+func deleteAirflowPool(m interface{}, name string) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	if name == airflowDefaultPool {
+		log.Printf("[INFO] Not deleting pool `%s`, Airflow doesn't allow it, only removing it from state", name)
+		return nil
+	}
+
+	resp, err := client.PoolApi.DeletePool(pcfg.AuthContext, name).Execute()
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return fmt.Errorf("failed to delete pool `%s` from Airflow: %w", name, err)
+	}
+	return nil
+}
+
+func airflowPoolEqual(a, b airflow.Pool) bool {
+	return a.GetSlots() == b.GetSlots() && a.GetDescription() == b.GetDescription()
+}
+
+func expandAirflowPools(tfList []interface{}) ([]airflow.Pool, error) {
+	apiObjects := make([]airflow.Pool, 0, len(tfList))
+	seen := make(map[string]bool, len(tfList))
+
+	for _, v := range tfList {
+		tfMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name := tfMap["name"].(string)
+		if seen[name] {
+			return nil, fmt.Errorf("pool `%s` is defined more than once", name)
+		}
+		seen[name] = true
+
+		slots := int32(tfMap["slots"].(int))
+		apiObject := airflow.Pool{
+			Name:  &name,
+			Slots: &slots,
+		}
+		if description := tfMap["description"].(string); description != "" {
+			apiObject.SetDescription(description)
+		} else {
+			apiObject.SetDescriptionNil()
+		}
+
+		apiObjects = append(apiObjects, apiObject)
+	}
+
+	return apiObjects, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourcePools_fakeReconcile(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("pools", map[string]interface{}{"name": airflowDefaultPool, "slots": 128})
+
+	pool := func(name string, slots int) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"slots":       slots,
+			"description": "pool of " + name,
+		}
+	}
+
+	raw := map[string]interface{}{
+		"pool": []interface{}{pool("tenant_a", 2), pool("tenant_b", 4), pool(airflowDefaultPool, 64)},
+	}
+	d := schema.TestResourceDataRaw(t, resourcePools().Schema, raw)
+	if err := resourcePoolsCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("pool.#").(int); got != 3 {
+		t.Fatalf("expected 3 pools in state, got %d", got)
+	}
+	if got := fake.object("pools", airflowDefaultPool)["slots"]; got != float64(64) {
+		t.Fatalf("expected the default pool to be updated, got %v", got)
+	}
+
+	// Drop tenant_a, resize tenant_b and add tenant_c.
+	raw["pool"] = []interface{}{pool("tenant_b", 8), pool("tenant_c", 1), pool(airflowDefaultPool, 64)}
+	d = testResourceDataUpdate(t, resourcePools(), d.State(), raw, m)
+	expected := []interface{}{map[string]interface{}{
+		"create": []interface{}{"tenant_c"},
+		"update": []interface{}{"tenant_b"},
+		"delete": []interface{}{"tenant_a"},
+	}}
+	if got := d.Get("pending_changes"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected pending_changes: %v", got)
+	}
+	if err := resourcePoolsUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := d.Get("pending_changes.#"); got != 0 {
+		t.Fatalf("expected no pending_changes in state, got %v", d.Get("pending_changes"))
+	}
+
+	if fake.object("pools", "tenant_a") != nil {
+		t.Fatal("expected tenant_a to be deleted")
+	}
+	if got := fake.object("pools", "tenant_b")["slots"]; got != float64(8) {
+		t.Fatalf("expected tenant_b to be resized, got %v", got)
+	}
+	if fake.object("pools", "tenant_c") == nil {
+		t.Fatal("expected tenant_c to be created")
+	}
+
+	if err := resourcePoolsDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("pools", "tenant_b") != nil || fake.object("pools", "tenant_c") != nil {
+		t.Fatal("pools still exist in Airflow")
+	}
+	if fake.object("pools", airflowDefaultPool) == nil {
+		t.Fatal("expected the default pool to be kept")
+	}
+}
+
+func TestResourcePools_duplicateName(t *testing.T) {
+	_, err := expandAirflowPools([]interface{}{
+		map[string]interface{}{"name": "dup", "slots": 1, "description": ""},
+		map[string]interface{}{"name": "dup", "slots": 2, "description": ""},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a duplicate name")
+	}
+}
+
+func TestResourcePools_fakeAuthoritativePrefix(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("pools", map[string]interface{}{"name": "team_a_old", "slots": 1})
+	fake.seed("pools", map[string]interface{}{"name": "team_b_pool", "slots": 1})
+
+	raw := map[string]interface{}{
+		"authoritative_prefix": "team_a_",
+		"pool": []interface{}{
+			map[string]interface{}{"name": "team_a_pool", "slots": 2, "description": ""},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourcePools().Schema, raw)
+	if err := resourcePoolsCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// The first apply deletes nothing, as no plan showed the deletion yet.
+	if fake.object("pools", "team_a_old") == nil {
+		t.Fatal("expected the unmanaged pool to be kept by the create")
+	}
+	if got := d.Get("unmanaged").([]interface{}); !reflect.DeepEqual(got, []interface{}{"team_a_old"}) {
+		t.Fatalf("unexpected unmanaged pools: %v", got)
+	}
+
+	// A pool created outside of Terraform is found on refresh and deleted by
+	// the next apply.
+	fake.seed("pools", map[string]interface{}{"name": "team_a_stray", "slots": 1})
+	if err := resourcePoolsRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("unmanaged").([]interface{}); !reflect.DeepEqual(got, []interface{}{"team_a_old", "team_a_stray"}) {
+		t.Fatalf("unexpected unmanaged pools: %v", got)
+	}
+
+	d = testResourceDataUpdate(t, resourcePools(), d.State(), raw, m)
+	expected := []interface{}{map[string]interface{}{
+		"create": []interface{}{},
+		"update": []interface{}{},
+		"delete": []interface{}{"team_a_old", "team_a_stray"},
+	}}
+	if got := d.Get("pending_changes"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected pending_changes: %v", got)
+	}
+
+	// A pool appearing between the plan and the apply isn't in the plan,
+	// so it is left to the next apply.
+	fake.seed("pools", map[string]interface{}{"name": "team_a_late", "slots": 1})
+	if err := resourcePoolsUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if fake.object("pools", "team_a_old") != nil || fake.object("pools", "team_a_stray") != nil {
+		t.Fatal("expected the planned pools to be deleted")
+	}
+	if fake.object("pools", "team_a_late") == nil {
+		t.Fatal("expected the pool missing from the plan to be kept")
+	}
+	if fake.object("pools", "team_b_pool") == nil {
+		t.Fatal("expected the pool of another team to be kept")
+	}
+	if got := d.Get("unmanaged").([]interface{}); !reflect.DeepEqual(got, []interface{}{"team_a_late"}) {
+		t.Fatalf("unexpected unmanaged pools: %v", got)
+	}
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 
 	"github.com/apache/airflow-client-go/airflow"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -23,8 +26,9 @@ func resourceRole() *schema.Resource {
 				ForceNew: true,
 			},
 			"action": {
-				Type:     schema.TypeSet,
-				Required: true,
+				Type:         schema.TypeSet,
+				Optional:     true,
+				AtLeastOneOf: []string{"action", "dag_permissions"},
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"action": {
@@ -38,10 +42,52 @@ func resourceRole() *schema.Resource {
 					},
 				},
 			},
+			"dag_permissions": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dag_id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"can_read": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"can_edit": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+						"can_delete": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"check_assigned_users": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"force_detach_users": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"ui_url":              uiUrlSchema(),
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
 
+// airflowRoleDagActions are the permissions of a DAG, which Airflow grants on
+// the `DAG:<dag_id>` resource.
+var airflowRoleDagActions = []string{"can_read", "can_edit", "can_delete"}
+
+const airflowRoleDagResourcePrefix = "DAG:"
+
 func resourceRoleCreate(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
@@ -52,37 +98,54 @@ func resourceRoleCreate(d *schema.ResourceData, m interface{}) error {
 		Name: &name,
 	}
 
-	if v, ok := d.GetOk("action"); ok && v.(*schema.Set).Len() > 0 {
-		actions := expandAirflowRoleActions(d.Get("action").(*schema.Set).List())
+	if actions := translateAirflowRoleActions(pcfg, expandAirflowRoleAllActions(d)); len(actions) > 0 {
 		role.Actions = &actions
 	}
 
-	_, _, err := varApi.PostRole(pcfg.AuthContext).Role(role).Execute()
-	if err != nil {
+	_, resp, err := varApi.PostRole(pcfg.AuthContext).Role(role).Execute()
+	if err != nil && !createdDespiteError(d, m, "role", name, resp, err, resourceRoleRead) {
 		return fmt.Errorf("failed to create role `%s` from Airflow: %w", name, err)
 	}
 	d.SetId(name)
 
-	return resourceRoleRead(d, m)
+	return readAfterCreate(d, m, "role", resourceRoleRead)
 }
 
 func resourceRoleRead(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
-	role, resp, err := client.RoleApi.GetRole(pcfg.AuthContext, d.Id()).Execute()
+	role, resp, err := getWithListFallback(pcfg, "role", d.Id(), client.RoleApi.GetRole(pcfg.AuthContext, d.Id()).Execute,
+		func(fn func(airflow.Role) bool) error {
+			_, err := listAirflowRoles(pcfg, fn)
+			return err
+		}, func(r airflow.Role) string { return r.GetName() })
 	if resp != nil && resp.StatusCode == 404 {
 		d.SetId("")
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get role `%s` from Airflow: %w", d.Id(), err)
+		return fmt.Errorf("failed to get role `%s` from Airflow: %w", d.Id(), apiPermissionError(resp, err, "can_read on Roles"))
 	}
 
 	d.Set("name", role.Name)
-	if err := d.Set("action", flattenAirflowRoleActions(*role.Actions)); err != nil {
+	d.Set("ui_url", airflowUiUrl(m, "role", d.Id()))
+
+	// Only the DAGs of dag_permissions are reported there, so that
+	// permissions on other DAGs still show up as drift of action.
+	dagIds := map[string]bool{}
+	for _, v := range d.Get("dag_permissions").(*schema.Set).List() {
+		dagIds[v.(map[string]interface{})["dag_id"].(string)] = true
+	}
+	apiObjects := spellAirflowRoleActionsAs(role.GetActions(), expandAirflowRoleAllActions(d))
+	actions, dagPermissions := splitAirflowRoleDagPermissions(apiObjects, dagIds)
+
+	if err := d.Set("action", flattenAirflowRoleActions(actions)); err != nil {
 		return fmt.Errorf("error setting action: %w", err)
 	}
+	if err := d.Set("dag_permissions", dagPermissions); err != nil {
+		return fmt.Errorf("error setting dag_permissions: %w", err)
+	}
 
 	return nil
 }
@@ -92,12 +155,21 @@ func resourceRoleUpdate(d *schema.ResourceData, m interface{}) error {
 	client := pcfg.ApiClient
 
 	name := d.Id()
-	actions := expandAirflowRoleActions(d.Get("action").(*schema.Set).List())
+	actions := translateAirflowRoleActions(pcfg, expandAirflowRoleAllActions(d))
 	role := airflow.Role{
 		Name:    &name,
 		Actions: &actions,
 	}
 
+	// Log the permissions that change against the role in Airflow, so that
+	// applies leave an audit record of them.
+	if current, _, err := client.RoleApi.GetRole(pcfg.AuthContext, name).Execute(); err == nil {
+		added, removed := diffAirflowRoleActions(current.GetActions(), actions)
+		log.Printf("[INFO] Updating permissions of role `%s`: adding %d %v, removing %d %v", name, len(added), added, len(removed), removed)
+	} else {
+		log.Printf("[WARN] Failed to get the permissions of role `%s` before updating them: %s", name, err)
+	}
+
 	_, _, err := client.RoleApi.PatchRole(pcfg.AuthContext, name).Role(role).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to update role `%s` from Airflow: %w", name, err)
@@ -110,6 +182,16 @@ func resourceRoleDelete(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
+	if err := checkDeletionProtection(d, "role"); err != nil {
+		return err
+	}
+
+	if d.Get("check_assigned_users").(bool) || d.Get("force_detach_users").(bool) {
+		if err := detachAirflowRoleUsers(pcfg, d.Id(), d.Get("force_detach_users").(bool)); err != nil {
+			return err
+		}
+	}
+
 	resp, err := client.RoleApi.DeleteRole(pcfg.AuthContext, d.Id()).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to delete role `%s` from Airflow: %w", d.Id(), err)
@@ -122,6 +204,101 @@ func resourceRoleDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
+// detachAirflowRoleUsers removes a role from the users it is assigned to when
+// force is set, and fails with the list of those users otherwise. Airflow
+// refuses deleting a role that is still assigned with an integrity error that
+// doesn't tell which users block it.
+func detachAirflowRoleUsers(pcfg ProviderConfig, name string, force bool) error {
+	users, err := listAllUsers(pcfg)
+	if err != nil {
+		return fmt.Errorf("failed to list the users of role `%s` from Airflow: %w", name, err)
+	}
+
+	var assigned []airflow.UserCollectionItem
+	for _, u := range users {
+		for _, role := range u.GetRoles() {
+			if role.GetName() == name {
+				assigned = append(assigned, u)
+				break
+			}
+		}
+	}
+	if len(assigned) == 0 {
+		return nil
+	}
+
+	if !force {
+		usernames := make([]string, 0, len(assigned))
+		for _, u := range assigned {
+			usernames = append(usernames, u.GetUsername())
+		}
+		sort.Strings(usernames)
+		return fmt.Errorf("refusing to delete role `%s`, it is still assigned to the users %s. Remove it from them or set force_detach_users = true", name, strings.Join(usernames, ", "))
+	}
+
+	for _, u := range assigned {
+		roles := []airflow.UserCollectionItemRoles{}
+		for _, role := range u.GetRoles() {
+			if role.GetName() != name {
+				roles = append(roles, role)
+			}
+		}
+
+		user := airflow.User{
+			Email:     u.Email,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+			Username:  u.Username,
+			Roles:     &roles,
+		}
+		log.Printf("[INFO] Detaching role `%s` from user `%s`", name, u.GetUsername())
+		if _, _, err := pcfg.ApiClient.UserApi.PatchUser(pcfg.AuthContext, u.GetUsername()).User(user).UpdateMask([]string{"roles"}).Execute(); err != nil {
+			return fmt.Errorf("failed to detach role `%s` from user `%s` in Airflow: %w", name, u.GetUsername(), err)
+		}
+	}
+
+	return nil
+}
+
+// diffAirflowRoleActions returns the permissions of desired that current
+// lacks and those of current that desired lacks, as sorted
+// `<action> on <resource>` strings.
+func diffAirflowRoleActions(current, desired []airflow.ActionResource) ([]string, []string) {
+	key := formatAirflowRoleAction
+
+	currentKeys := map[string]bool{}
+	for _, apiObject := range current {
+		currentKeys[key(apiObject)] = true
+	}
+	desiredKeys := map[string]bool{}
+	for _, apiObject := range desired {
+		desiredKeys[key(apiObject)] = true
+	}
+
+	added := []string{}
+	for k := range desiredKeys {
+		if !currentKeys[k] {
+			added = append(added, k)
+		}
+	}
+	removed := []string{}
+	for k := range currentKeys {
+		if !desiredKeys[k] {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	return added, removed
+}
+
+// formatAirflowRoleAction returns a permission as `<action> on <resource>`,
+// e.g. `can_read on DAGs`.
+func formatAirflowRoleAction(apiObject airflow.ActionResource) string {
+	return apiObject.Action.GetName() + " on " + apiObject.Resource.GetName()
+}
+
 func expandAirflowRoleActions(tfList []interface{}) []airflow.ActionResource {
 	if len(tfList) == 0 {
 		return nil
@@ -153,17 +330,113 @@ func expandAirflowRoleActions(tfList []interface{}) []airflow.ActionResource {
 	return apiObjects
 }
 
+// expandAirflowRoleAllActions returns the permissions of action together with
+// the ones dag_permissions expands to.
+func expandAirflowRoleAllActions(d *schema.ResourceData) []airflow.ActionResource {
+	actions := expandAirflowRoleActions(d.Get("action").(*schema.Set).List())
+	return append(actions, expandAirflowRoleDagPermissions(d.Get("dag_permissions").(*schema.Set).List())...)
+}
+
+func expandAirflowRoleDagPermissions(tfList []interface{}) []airflow.ActionResource {
+	var apiObjects []airflow.ActionResource
+
+	for _, tfMapRaw := range tfList {
+		tfMap, ok := tfMapRaw.(map[string]interface{})
+
+		if !ok {
+			continue
+		}
+
+		resource := airflowRoleDagResourcePrefix + tfMap["dag_id"].(string)
+		for _, action := range airflowRoleDagActions {
+			if !tfMap[action].(bool) {
+				continue
+			}
+
+			action := action
+			apiObjects = append(apiObjects, airflow.ActionResource{
+				Action: &airflow.Action{
+					Name: &action,
+				},
+				Resource: &airflow.Resource{
+					Name: &resource,
+				},
+			})
+		}
+	}
+
+	return apiObjects
+}
+
+// splitAirflowRoleDagPermissions separates the permissions on the DAGs in
+// dagIds, flattened as dag_permissions, from the other permissions.
+func splitAirflowRoleDagPermissions(apiObjects []airflow.ActionResource, dagIds map[string]bool) ([]airflow.ActionResource, []interface{}) {
+	var actions []airflow.ActionResource
+	byDagId := map[string]map[string]interface{}{}
+
+	for _, apiObject := range apiObjects {
+		resource := apiObject.Resource.GetName()
+		action := apiObject.Action.GetName()
+		dagId := strings.TrimPrefix(resource, airflowRoleDagResourcePrefix)
+		if !strings.HasPrefix(resource, airflowRoleDagResourcePrefix) || !dagIds[dagId] || !isAirflowRoleDagAction(action) {
+			actions = append(actions, apiObject)
+			continue
+		}
+
+		tfMap, ok := byDagId[dagId]
+		if !ok {
+			tfMap = map[string]interface{}{"dag_id": dagId}
+			for _, a := range airflowRoleDagActions {
+				tfMap[a] = false
+			}
+			byDagId[dagId] = tfMap
+		}
+		tfMap[action] = true
+	}
+
+	dagPermissions := make([]interface{}, 0, len(byDagId))
+	for _, tfMap := range byDagId {
+		dagPermissions = append(dagPermissions, tfMap)
+	}
+
+	return actions, dagPermissions
+}
+
+func isAirflowRoleDagAction(action string) bool {
+	for _, a := range airflowRoleDagActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
 func flattenAirflowRoleActions(apiObjects []airflow.ActionResource) []interface{} {
 	if len(apiObjects) == 0 {
 		return nil
 	}
 
+	sorted := make([]airflow.ActionResource, 0, len(apiObjects))
+	for _, apiObject := range apiObjects {
+		if apiObject.Action == nil || apiObject.Action.Name == nil || apiObject.Resource == nil || apiObject.Resource.Name == nil {
+			continue
+		}
+		sorted = append(sorted, apiObject)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if *sorted[i].Resource.Name == *sorted[j].Resource.Name {
+			return *sorted[i].Action.Name < *sorted[j].Action.Name
+		}
+		return *sorted[i].Resource.Name < *sorted[j].Resource.Name
+	})
+
 	var tfList []interface{}
 
-	for _, apiObject := range apiObjects {
+	for _, apiObject := range sorted {
 		tfList = append(tfList, map[string]interface{}{
-			"action":   apiObject.Action.Name,
-			"resource": apiObject.Resource.Name,
+			"action":   *apiObject.Action.Name,
+			"resource": *apiObject.Resource.Name,
 		})
 	}
 
@@ -2,10 +2,15 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/apache/airflow-client-go/airflow"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -73,3 +78,208 @@ resource "airflow_role" "test" {
 }
 `, rName, action, resource)
 }
+
+func TestResourceRole_fakeCRUD(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceRole().Schema, map[string]interface{}{
+		"name": "fake-role",
+		"action": []interface{}{
+			map[string]interface{}{"action": "can_read", "resource": "Audit Logs"},
+		},
+	})
+
+	if err := resourceRoleCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("action").(*schema.Set).Len(); got != 1 {
+		t.Fatalf("expected 1 action, got %d", got)
+	}
+
+	if err := resourceRoleDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("roles", "fake-role") != nil {
+		t.Fatal("role still exists in Airflow")
+	}
+}
+
+func TestResourceRole_fakeActionOrder(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceRole().Schema, map[string]interface{}{
+		"name": "fake-role",
+		"action": []interface{}{
+			map[string]interface{}{"action": "can_read", "resource": "Audit Logs"},
+			map[string]interface{}{"action": "can_read", "resource": "Connections"},
+			map[string]interface{}{"action": "can_edit", "resource": "Connections"},
+		},
+	})
+	configured := d.Get("action").(*schema.Set)
+
+	if err := resourceRoleCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// Airflow doesn't keep the order the permissions were sent in.
+	role := fake.object("roles", "fake-role")
+	actions := role["actions"].([]interface{})
+	for i, j := 0, len(actions)-1; i < j; i, j = i+1, j-1 {
+		actions[i], actions[j] = actions[j], actions[i]
+	}
+
+	if err := resourceRoleRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("action").(*schema.Set); !got.Equal(configured) {
+		t.Fatalf("expected the reordered permissions to match the configuration, got %v", got.List())
+	}
+}
+
+func TestResourceRole_fakeAssignedUsers(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("roles", map[string]interface{}{"name": "analyst", "actions": []interface{}{}})
+	for _, username := range []string{"bob", "alice"} {
+		fake.seed("users", map[string]interface{}{
+			"username": username,
+			"email":    username + "@example.com",
+			"roles":    []interface{}{map[string]interface{}{"name": "analyst"}, map[string]interface{}{"name": "Viewer"}},
+		})
+	}
+	fake.seed("users", map[string]interface{}{
+		"username": "carol",
+		"email":    "carol@example.com",
+		"roles":    []interface{}{map[string]interface{}{"name": "Viewer"}},
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceRole().Schema, map[string]interface{}{
+		"name":                 "analyst",
+		"check_assigned_users": true,
+	})
+	d.SetId("analyst")
+
+	err := resourceRoleDelete(d, m)
+	if err == nil || !strings.Contains(err.Error(), "assigned to the users alice, bob") {
+		t.Fatalf("expected delete to fail with the assigned users, got %v", err)
+	}
+	if fake.object("roles", "analyst") == nil {
+		t.Fatal("expected the role to be kept")
+	}
+
+	d.Set("force_detach_users", true)
+	if err := resourceRoleDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("roles", "analyst") != nil {
+		t.Fatal("role still exists in Airflow")
+	}
+	roles := fake.object("users", "alice")["roles"].([]interface{})
+	if len(roles) != 1 || roles[0].(map[string]interface{})["name"] != "Viewer" {
+		t.Fatalf("expected analyst to be detached from alice, got %v", roles)
+	}
+	if got := fake.requestCount(http.MethodPatch, "/users/"); got != 2 {
+		t.Fatalf("expected 2 users to be patched, got %d", got)
+	}
+}
+
+func TestResourceRole_fakeDagPermissions(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceRole().Schema, map[string]interface{}{
+		"name": "fake-role",
+		"action": []interface{}{
+			map[string]interface{}{"action": "can_read", "resource": "Audit Logs"},
+		},
+		"dag_permissions": []interface{}{
+			map[string]interface{}{"dag_id": "example", "can_read": true, "can_edit": true},
+		},
+	})
+
+	if err := resourceRoleCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	role := fake.object("roles", "fake-role")
+	if got := len(role["actions"].([]interface{})); got != 3 {
+		t.Fatalf("expected 3 permissions in Airflow, got %d: %v", got, role["actions"])
+	}
+
+	if got := d.Get("action").(*schema.Set).Len(); got != 1 {
+		t.Fatalf("expected 1 action, got %d", got)
+	}
+	dagPermissions := d.Get("dag_permissions").(*schema.Set).List()
+	expected := []interface{}{
+		map[string]interface{}{"dag_id": "example", "can_read": true, "can_edit": true, "can_delete": false},
+	}
+	if !reflect.DeepEqual(dagPermissions, expected) {
+		t.Fatalf("unexpected dag_permissions: %v", dagPermissions)
+	}
+}
+
+func TestSplitAirflowRoleDagPermissions(t *testing.T) {
+	read, edit := "can_read", "can_edit"
+	managed, other, dags := "DAG:managed", "DAG:other", "DAGs"
+
+	actions, dagPermissions := splitAirflowRoleDagPermissions([]airflow.ActionResource{
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &managed}},
+		{Action: &airflow.Action{Name: &edit}, Resource: &airflow.Resource{Name: &managed}},
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &other}},
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &dags}},
+	}, map[string]bool{"managed": true})
+
+	if len(actions) != 2 {
+		t.Fatalf("expected the permissions on other DAGs to be kept as actions, got %v", actions)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"dag_id": "managed", "can_read": true, "can_edit": true, "can_delete": false},
+	}
+	if !reflect.DeepEqual(dagPermissions, expected) {
+		t.Fatalf("unexpected dag_permissions: %v", dagPermissions)
+	}
+}
+
+func TestFlattenAirflowRoleActions(t *testing.T) {
+	read, edit := "can_read", "can_edit"
+	dags, logs := "DAGs", "Audit Logs"
+
+	got := flattenAirflowRoleActions([]airflow.ActionResource{
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &dags}},
+		{Action: &airflow.Action{Name: &read}, Resource: nil},
+		{Action: &airflow.Action{Name: &edit}, Resource: &airflow.Resource{Name: &dags}},
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &logs}},
+	})
+
+	expected := []interface{}{
+		map[string]interface{}{"action": "can_read", "resource": "Audit Logs"},
+		map[string]interface{}{"action": "can_edit", "resource": "DAGs"},
+		map[string]interface{}{"action": "can_read", "resource": "DAGs"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected actions: %v", got)
+	}
+}
+
+func TestDiffAirflowRoleActions(t *testing.T) {
+	read, edit := "can_read", "can_edit"
+	dags, logs := "DAGs", "Audit Logs"
+
+	added, removed := diffAirflowRoleActions([]airflow.ActionResource{
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &dags}},
+		{Action: &airflow.Action{Name: &edit}, Resource: &airflow.Resource{Name: &dags}},
+	}, []airflow.ActionResource{
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &dags}},
+		{Action: &airflow.Action{Name: &read}, Resource: &airflow.Resource{Name: &logs}},
+	})
+
+	if !reflect.DeepEqual(added, []string{"can_read on Audit Logs"}) {
+		t.Fatalf("unexpected added permissions: %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"can_edit on DAGs"}) {
+		t.Fatalf("unexpected removed permissions: %v", removed)
+	}
+}
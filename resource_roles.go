@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceRoles manages many roles in a single resource, e.g. a role per
+// team. All roles are read with one paginated list call and only the roles
+// whose permissions changed are updated.
+func resourceRoles() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRolesCreate,
+		Read:   resourceRolesRead,
+		Update: resourceRolesUpdate,
+		Delete: resourceRolesDelete,
+		CustomizeDiff: customizePendingChangesDiff("role", "name", expandAirflowRoles,
+			func(r airflow.Role) string { return r.GetName() }, airflowRoleEqual),
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"action": {
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"action": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"resource": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"authoritative_prefix": authoritativePrefixSchema(),
+			"unmanaged":            unmanagedSchema(),
+			"pending_changes":      pendingChangesSchema(),
+		},
+	}
+}
+
+func resourceRolesCreate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	roles, err := expandAirflowRoles(d.Get("role").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(resource.UniqueId())
+	calls := make([]func() error, 0, len(roles))
+	for _, role := range roles {
+		role := role
+		calls = append(calls, func() error { return createAirflowRole(pcfg, role) })
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	// Unmanaged objects are only found by this read and deleted by the
+	// following apply, after the plan showed them.
+	return resourceRolesRead(d, m)
+}
+
+func resourceRolesRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	managed := d.Get("role").([]interface{})
+	wanted := make(map[string]bool, len(managed))
+	for _, v := range managed {
+		wanted[v.(map[string]interface{})["name"].(string)] = true
+	}
+
+	// Only keep the managed roles and stop paging once all are found,
+	// unless all roles under the authoritative_prefix are looked for.
+	prefix := d.Get("authoritative_prefix").(string)
+	remote := make(map[string]airflow.Role, len(managed))
+	unmanaged := []string{}
+	names := make([]string, 0, len(managed))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	err := listWithGetFallback(pcfg, "role", names, func(fn func(airflow.Role) bool) (*http.Response, error) {
+		return listAirflowRoles(pcfg, fn)
+	}, func(name string) (airflow.Role, *http.Response, error) {
+		return client.RoleApi.GetRole(pcfg.AuthContext, name).Execute()
+	}, func(r airflow.Role) bool {
+		if wanted[r.GetName()] {
+			remote[r.GetName()] = r
+		} else if isUnmanaged(prefix, r.GetName(), false) {
+			unmanaged = append(unmanaged, r.GetName())
+		}
+		return prefix != "" || len(remote) < len(wanted)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keep the order of the state so the list doesn't show a diff. Roles
+	// that were removed outside of Terraform are dropped to be recreated.
+	var roles []interface{}
+	for _, v := range managed {
+		name := v.(map[string]interface{})["name"].(string)
+		role, exists := remote[name]
+		if !exists {
+			log.Printf("[WARN] Role `%s` not found in Airflow, removing it from state", name)
+			continue
+		}
+
+		configured := expandAirflowRoleActions(v.(map[string]interface{})["action"].(*schema.Set).List())
+		roles = append(roles, map[string]interface{}{
+			"name":   role.GetName(),
+			"action": flattenAirflowRoleActions(spellAirflowRoleActionsAs(role.GetActions(), configured)),
+		})
+	}
+
+	if err := d.Set("role", roles); err != nil {
+		return fmt.Errorf("error setting role: %w", err)
+	}
+	setUnmanaged(d, unmanaged)
+	d.Set("pending_changes", []interface{}{})
+
+	return nil
+}
+
+// listAirflowRoles calls fn for every role of Airflow until it returns
+// false. The response is the one of the last page fetched, e.g. to tell
+// whether listing is forbidden.
+func listAirflowRoles(pcfg ProviderConfig, fn func(airflow.Role) bool) (*http.Response, error) {
+	var last *http.Response
+	key := func(r airflow.Role) string { return r.GetName() }
+	err := forEachPage("roles", key, func(limit, offset int32) ([]airflow.Role, int32, error) {
+		page, resp, err := pcfg.ApiClient.RoleApi.GetRoles(pcfg.AuthContext).Limit(limit).Offset(offset).Execute()
+		last = resp
+		return page.GetRoles(), page.GetTotalEntries(), apiPermissionError(resp, err, "can_read on Roles")
+	}, fn)
+	return last, err
+}
+
+func resourceRolesUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	o, n := d.GetChange("role")
+	oldRoles, err := expandAirflowRoles(o.([]interface{}))
+	if err != nil {
+		return err
+	}
+	newRoles, err := expandAirflowRoles(n.([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	oldByName := make(map[string]airflow.Role, len(oldRoles))
+	for _, role := range oldRoles {
+		oldByName[role.GetName()] = role
+	}
+
+	var calls []func() error
+	for _, role := range newRoles {
+		role, name := role, role.GetName()
+		old, exists := oldByName[name]
+		delete(oldByName, name)
+
+		if !exists {
+			calls = append(calls, func() error { return createAirflowRole(pcfg, role) })
+			continue
+		}
+
+		added, removed := diffAirflowRoleActions(old.GetActions(), role.GetActions())
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		log.Printf("[INFO] Updating permissions of role `%s`: adding %d %v, removing %d %v", name, len(added), added, len(removed), removed)
+		calls = append(calls, func() error {
+			role.SetActions(translateAirflowRoleActions(pcfg, role.GetActions()))
+			if _, _, err := client.RoleApi.PatchRole(pcfg.AuthContext, name).Role(role).Execute(); err != nil {
+				return fmt.Errorf("failed to update role `%s` from Airflow: %w", name, err)
+			}
+			return nil
+		})
+	}
+
+	for name := range oldByName {
+		name := name
+		calls = append(calls, func() error { return deleteAirflowRoleByName(pcfg, name) })
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	if err := deleteUnmanaged(d, m, "role", "name", func(name string) error { return deleteAirflowRoleByName(pcfg, name) }); err != nil {
+		return err
+	}
+
+	return resourceRolesRead(d, m)
+}
+
+func resourceRolesDelete(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	var calls []func() error
+	for _, v := range d.Get("role").([]interface{}) {
+		name := v.(map[string]interface{})["name"].(string)
+		calls = append(calls, func() error { return deleteAirflowRoleByName(pcfg, name) })
+	}
+
+	return runConcurrently(m, calls)
+}
+
+// createAirflowRole creates a role of the bulk resource with its permissions
+// spelled the way the Airflow version expects.
+func createAirflowRole(pcfg ProviderConfig, role airflow.Role) error {
+	role.SetActions(translateAirflowRoleActions(pcfg, role.GetActions()))
+	if _, _, err := pcfg.ApiClient.RoleApi.PostRole(pcfg.AuthContext).Role(role).Execute(); err != nil {
+		return fmt.Errorf("failed to create role `%s` from Airflow: %w", role.GetName(), err)
+	}
+	return nil
+}
+
+func deleteAirflowRoleByName(pcfg ProviderConfig, name string) error {
+	resp, err := pcfg.ApiClient.RoleApi.DeleteRole(pcfg.AuthContext, name).Execute()
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return fmt.Errorf("failed to delete role `%s` from Airflow: %w", name, err)
+	}
+	return nil
+}
+
+func airflowRoleEqual(a, b airflow.Role) bool {
+	added, removed := diffAirflowRoleActions(a.GetActions(), b.GetActions())
+	return len(added) == 0 && len(removed) == 0
+}
+
+func expandAirflowRoles(tfList []interface{}) ([]airflow.Role, error) {
+	apiObjects := make([]airflow.Role, 0, len(tfList))
+	seen := make(map[string]bool, len(tfList))
+
+	for _, v := range tfList {
+		tfMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name := tfMap["name"].(string)
+		if seen[name] {
+			return nil, fmt.Errorf("role `%s` is defined more than once", name)
+		}
+		seen[name] = true
+
+		actions := expandAirflowRoleActions(tfMap["action"].(*schema.Set).List())
+		if actions == nil {
+			actions = []airflow.ActionResource{}
+		}
+		apiObjects = append(apiObjects, airflow.Role{
+			Name:    &name,
+			Actions: &actions,
+		})
+	}
+
+	return apiObjects, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceRoles_fakeReconcile(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	role := func(name string, resources ...string) map[string]interface{} {
+		actions := make([]interface{}, 0, len(resources))
+		for _, r := range resources {
+			actions = append(actions, map[string]interface{}{"action": "can_read", "resource": r})
+		}
+		return map[string]interface{}{"name": name, "action": actions}
+	}
+
+	raw := map[string]interface{}{
+		"role": []interface{}{role("team_a", "DAGs"), role("team_b", "DAGs"), role("team_c", "DAGs")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceRoles().Schema, raw)
+	if err := resourceRolesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("role.#").(int); got != 3 {
+		t.Fatalf("expected 3 roles in state, got %d", got)
+	}
+
+	// Drop team_a, grant team_b more and add team_d. team_c is unchanged.
+	raw["role"] = []interface{}{role("team_b", "DAGs", "Audit Logs"), role("team_c", "DAGs"), role("team_d", "DAGs")}
+	d = testResourceDataUpdate(t, resourceRoles(), d.State(), raw, m)
+	if err := resourceRolesUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	if fake.object("roles", "team_a") != nil {
+		t.Fatal("expected team_a to be deleted")
+	}
+	if got := fake.object("roles", "team_b")["actions"].([]interface{}); len(got) != 2 {
+		t.Fatalf("expected team_b to be updated, got %v", got)
+	}
+	if fake.object("roles", "team_d") == nil {
+		t.Fatal("expected team_d to be created")
+	}
+	if n := fake.requestCount(http.MethodPatch, "/roles"); n != 1 {
+		t.Fatalf("expected only the changed role to be updated, got %d updates", n)
+	}
+
+	if err := resourceRolesDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	for _, name := range []string{"team_b", "team_c", "team_d"} {
+		if fake.object("roles", name) != nil {
+			t.Fatalf("role %s still exists in Airflow", name)
+		}
+	}
+}
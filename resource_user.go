@@ -1,25 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"log"
+	"sort"
 
 	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-var airflowUsers = map[string]airflow.UserCollectionItem{}
-var airflowUsersFetch sync.Mutex
-
 func resourceUser() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		Create: resourceUserCreate,
 		Read:   resourceUserRead,
 		Update: resourceUserUpdate,
 		Delete: resourceUserDelete,
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceUserImport,
 		},
+		CustomizeDiff: customdiff.All(customizeUserRolesAllDiff, customizeUserPasswordPolicyDiff, customizeUserGeneratedPasswordDiff),
 		Schema: map[string]*schema.Schema{
 			"active": {
 				Type:     schema.TypeBool,
@@ -42,28 +43,126 @@ func resourceUser() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
-			"login_count": {
+			"last_login": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"login_count": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"locked": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 			"roles": {
 				Type:     schema.TypeSet,
 				Required: true,
 				MinItems: 1,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"roles_all": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 			"username": {
 				Type:     schema.TypeString,
 				Required: true,
 				ForceNew: true,
 			},
 			"password": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"password_wo", "generate_password"},
+				DiffSuppressFunc: suppressUserPasswordDiff,
+			},
+			"password_wo": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"password", "generate_password"},
+				DiffSuppressFunc: suppressWriteOnlyDiff,
+			},
+			"generate_password": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"password", "password_wo"},
+			},
+			"generated_password": {
 				Type:      schema.TypeString,
-				Required:  true,
+				Computed:  true,
 				Sensitive: true,
 			},
+			"password_wo_version": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{"password_wo"},
+			},
+			"rotation_triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"create_missing_roles": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"skip_password_update": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"server_managed_attributes": serverManagedAttributesSchema("roles"),
+			"sensitive_state_mode":      sensitiveStateModeSchema(),
+			"sensitive_state_digests":   sensitiveStateDigestsSchema(),
+			"manage":                    manageSchema(),
+			"deletion_protection":       deletionProtectionSchema(),
+			"allow_self_management": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"ui_url": uiUrlSchema(),
+		},
+		SchemaVersion: 1,
+	}
+	r.StateUpgraders = []schema.StateUpgrader{
+		{
+			Version: 0,
+			Type:    resourceUserV0(r.Schema).CoreConfigSchema().ImpliedType(),
+			Upgrade: resourceUserUpgradeV0,
 		},
 	}
+
+	return r
+}
+
+// resourceUserV0 is the schema of version 0, which stored the last login of
+// the user in login_count.
+func resourceUserV0(s map[string]*schema.Schema) *schema.Resource {
+	v0 := make(map[string]*schema.Schema, len(s))
+	for k, v := range s {
+		v0[k] = v
+	}
+	delete(v0, "last_login")
+	delete(v0, "locked")
+	v0["login_count"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+
+	return &schema.Resource{Schema: v0}
+}
+
+func resourceUserUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	rawState["last_login"] = rawState["login_count"]
+	rawState["login_count"] = 0
+
+	return rawState, nil
 }
 
 func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
@@ -74,12 +173,42 @@ func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
 	firstName := d.Get("first_name").(string)
 	lastName := d.Get("last_name").(string)
 	username := d.Get("username").(string)
-	password := d.Get("password").(string)
-	roles := expandAirflowUserRoles(d.Get("roles").(*schema.Set))
+	password := configString(d, "password")
+	if password == "" {
+		password = configString(d, "password_wo")
+	}
+	var generated string
+	if d.Get("generate_password").(bool) {
+		var err error
+		if generated, err = generateUserPassword(m); err != nil {
+			return err
+		}
+		password = generated
+	}
+	roles := expandAirflowUserRoles(mergeDefaultUserRoles(m, d.Get("roles").(*schema.Set)))
+
+	if isObserveOnly(d) {
+		return observeExisting(d, m, "user", email, resourceUserRead)
+	}
+
+	if d.Get("create_missing_roles").(bool) {
+		if err := createMissingRoles(pcfg, roles); err != nil {
+			return err
+		}
+	}
+
+	// An empty password is only sent when it is configured explicitly, e.g.
+	// for users that log in through Cloud Composer.
+	if password == "" && !configSet(d, "password") {
+		return fmt.Errorf("failed to create user `%s`: one of `password`, `password_wo` or `generate_password` must be set", email)
+	}
+	if err := checkPassword(m, email, password); err != nil {
+		return err
+	}
 
 	userApi := client.UserApi
 
-	_, _, err := userApi.PostUser(pcfg.AuthContext).User(airflow.User{
+	_, resp, err := userApi.PostUser(pcfg.AuthContext).User(airflow.User{
 		Email:     &email,
 		FirstName: &firstName,
 		LastName:  &lastName,
@@ -87,7 +216,7 @@ func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
 		Password:  &password,
 		Roles:     &roles,
 	}).Execute()
-	if err != nil {
+	if err != nil && !createdDespiteError(d, m, "user", email, resp, err, resourceUserRead) {
 		return fmt.Errorf("failed to create user `%s` from Airflow: %w", email, err)
 	}
 
@@ -96,61 +225,112 @@ func resourceUserCreate(d *schema.ResourceData, m interface{}) error {
 	// be changed by an external auth layer. This will conflict with the
 	// Terraform state so it's safer to use the e-mail as the Id.
 	d.SetId(email)
+	d.Set("generated_password", generated)
+	if v := configString(d, "password"); v != "" {
+		if err := setSensitiveStateValue(d, "password", effectiveSensitiveStateMode(m, sensitiveStatePlain), v); err != nil {
+			return err
+		}
+	}
 
-	return resourceUserRead(d, m)
+	return readAfterCreate(d, m, "user", resourceUserRead)
+}
+
+// listAllUsers returns every user of Airflow in the order of the API.
+func listAllUsers(m interface{}) ([]airflow.UserCollectionItem, error) {
+	var users []airflow.UserCollectionItem
+	err := searchUsers(m, func(u airflow.UserCollectionItem) bool {
+		users = append(users, u)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
 }
 
-func fetchAllUsers(users map[string]airflow.UserCollectionItem, offset int32, m interface{}) error {
+// searchUsers calls fn for every user of Airflow page by page until fn
+// returns false. Instances with many auto-registered SSO users are searched
+// without keeping all users in memory.
+func searchUsers(m interface{}, fn func(airflow.UserCollectionItem) bool) error {
+	return searchUsersOrderedBy(m, "", fn)
+}
+
+// searchUsersOrderedBy is searchUsers in the order of the given order_by
+// parameter of the API, or the API default if it is empty.
+func searchUsersOrderedBy(m interface{}, orderBy string, fn func(airflow.UserCollectionItem) bool) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
-	// This is the Airflow API default maximum page size.
-	limit := int32(100)
 
-	usersInResponse, resp, err := client.UserApi.GetUsers(pcfg.AuthContext).Limit(limit).Offset(offset).Execute()
-	if resp != nil && err == nil {
-		for _, u := range usersInResponse.GetUsers() {
-			users[*u.Email] = u
+	key := func(u airflow.UserCollectionItem) string { return u.GetUsername() }
+	return forEachPage("users", key, func(limit, offset int32) ([]airflow.UserCollectionItem, int32, error) {
+		req := client.UserApi.GetUsers(pcfg.AuthContext).Limit(limit).Offset(offset)
+		if orderBy != "" {
+			req = req.OrderBy(orderBy)
 		}
-	}
+		page, resp, err := req.Execute()
+		return page.GetUsers(), page.GetTotalEntries(), apiPermissionError(resp, err, "can_read on Users")
+	}, fn)
+}
 
-	if err != nil {
-		return err
-	}
+// lookupAirflowUser returns the user with the given e-mail, the ID of an
+// airflow_user. It is read by username when that is known and still has the
+// e-mail. Otherwise, e.g. after an import or when an auth layer such as Cloud
+// Composer renamed the user, the users are searched for the e-mail.
+func lookupAirflowUser(m interface{}, email, username string) (airflow.UserCollectionItem, bool, error) {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
 
-	// Recurse to the next page in case there are more users to fetch.
-	if *usersInResponse.TotalEntries > int32(len(users)) {
-		return fetchAllUsers(users, offset+limit, m)
+	if username != "" {
+		user, resp, err := client.UserApi.GetUser(pcfg.AuthContext, username).Execute()
+		if err == nil && user.GetEmail() == email {
+			return user, true, nil
+		}
+		if err != nil && (resp == nil || resp.StatusCode != 404) {
+			return user, false, fmt.Errorf("failed to get user `%s` from Airflow: %w", username, apiPermissionError(resp, err, "can_read on Users"))
+		}
 	}
 
-	return nil
+	var found airflow.UserCollectionItem
+	exists := false
+	err := searchUsers(m, func(u airflow.UserCollectionItem) bool {
+		if u.GetEmail() == email {
+			found, exists = u, true
+		}
+		return !exists
+	})
+
+	return found, exists, err
 }
 
 func resourceUserRead(d *schema.ResourceData, m interface{}) error {
-	// Use a lock to prevent concurrent map access.
-	airflowUsersFetch.Lock()
-	
-	err := fetchAllUsers(airflowUsers, 0, m)
+	user, exists, err := lookupAirflowUser(m, d.Id(), d.Get("username").(string))
 	if err != nil {
-		airflowUsersFetch.Unlock()
-		return fmt.Errorf("failed to get all users from Airflow: %w", err)
+		return err
 	}
-	user, exists := airflowUsers[d.Id()]
-	airflowUsersFetch.Unlock()
-
 	if !exists {
 		d.SetId("")
 		return nil
 	}
-	
+
 	d.Set("active", user.GetActive())
 	d.Set("email", user.Email)
 	d.Set("failed_login_count", user.GetFailedLoginCount())
 	d.Set("first_name", user.FirstName)
 	d.Set("last_name", user.LastName)
-	d.Set("login_count", user.GetLastLogin())
+	d.Set("last_login", user.GetLastLogin())
+	d.Set("login_count", user.GetLoginCount())
+	d.Set("locked", airflowUserLocked(m, user))
 	d.Set("username", user.Username)
-	d.Set("password", d.Get("password").(string))
-	d.Set("roles", flattenAirflowUserRoles(*user.Roles))
+	d.Set("ui_url", airflowUiUrl(m, "user", user.GetUsername()))
+	d.Set("password_wo", "")
+	d.Set("sensitive_state_mode", effectiveSensitiveStateMode(m, sensitiveStatePlain))
+	keepSensitiveStateDigests(d)
+	rolesAll := flattenAirflowUserRoles(user.GetRoles())
+	d.Set("roles_all", rolesAll)
+	if err := setUnlessServerManaged(d, "roles", withoutDefaultUserRoles(m, rolesAll, d.Get("roles").(*schema.Set))); err != nil {
+		return fmt.Errorf("error setting roles: %w", err)
+	}
 
 	return nil
 }
@@ -159,26 +339,79 @@ func resourceUserUpdate(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
+	if isObserveOnly(d) {
+		observeOnly(d, m, "user", "updating")
+		return resourceUserRead(d, m)
+	}
+
 	email := d.Id()
 	firstName := d.Get("first_name").(string)
 	lastName := d.Get("last_name").(string)
-	password := d.Get("password").(string)
-	roles := expandAirflowUserRoles(d.Get("roles").(*schema.Set))
 	username := d.Get("username").(string)
 
-	// Do use username and not the resource Id (=e-mail) when making API calls.
-	_, _, err := client.UserApi.PatchUser(pcfg.AuthContext, username).User(airflow.User{
+	user := airflow.User{
 		Email:     &email,
 		FirstName: &firstName,
 		LastName:  &lastName,
-		Password:  &password,
-		Roles:     &roles,
 		Username:  &username,
-	}).Execute()
+	}
+
+	// Airflow keeps the roles of a user when they are left out.
+	if sendServerManaged(d, "roles") || d.HasChange("roles_all") {
+		roles := expandAirflowUserRoles(mergeDefaultUserRoles(m, d.Get("roles").(*schema.Set)))
+		if d.Get("create_missing_roles").(bool) && d.HasChanges("roles", "roles_all") {
+			if err := createMissingRoles(pcfg, roles); err != nil {
+				return err
+			}
+		}
+		user.SetRoles(roles)
+	}
+
+	// Only send the password when it changed. A write-only password is
+	// re-sent whenever its version changes. Both are re-sent when any of
+	// the rotation triggers change. With skip_password_update the password
+	// is only ever sent on create.
+	rotate := d.HasChange("rotation_triggers")
+	password := configString(d, "password")
+	generate := d.Get("generate_password").(bool)
+	var generated string
+	if d.Get("skip_password_update").(bool) {
+		password = ""
+	} else if generate && (d.HasChange("generate_password") || rotate) {
+		var err error
+		if generated, err = generateUserPassword(m); err != nil {
+			return err
+		}
+		user.SetPassword(generated)
+	} else if password != "" && (d.HasChange("password") || userPasswordUnknown(d) || rotate) {
+		user.SetPassword(password)
+	} else if d.HasChange("password_wo_version") || rotate {
+		if v := configString(d, "password_wo"); v != "" {
+			user.SetPassword(v)
+		}
+	}
+
+	if err := checkPassword(m, email, user.GetPassword()); err != nil {
+		return err
+	}
+
+	// Do use username and not the resource Id (=e-mail) when making API calls.
+	_, _, err := client.UserApi.PatchUser(pcfg.AuthContext, username).User(user).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to update user `%s` from Airflow: %w", email, err)
 	}
 
+	if user.HasPassword() && password != "" {
+		if err := setSensitiveStateValue(d, "password", effectiveSensitiveStateMode(m, sensitiveStatePlain), password); err != nil {
+			return err
+		}
+	}
+	if !generate {
+		d.Set("generated_password", "")
+	} else if generated != "" {
+		d.Set("generated_password", generated)
+	}
+
 	return resourceUserRead(d, m)
 }
 
@@ -187,6 +420,19 @@ func resourceUserDelete(d *schema.ResourceData, m interface{}) error {
 	client := pcfg.ApiClient
 	username := d.Get("username").(string)
 
+	if err := checkDeletionProtection(d, "user"); err != nil {
+		return err
+	}
+
+	if isObserveOnly(d) {
+		observeOnly(d, m, "user", "deleting")
+		return nil
+	}
+
+	if err := checkSelfManagement(d, m); err != nil {
+		return err
+	}
+
 	// Do use username and not the resource Id (=e-mail) when making API calls.
 	resp, err := client.UserApi.DeleteUser(pcfg.AuthContext, username).Execute()
 	if err != nil {
@@ -200,6 +446,121 @@ func resourceUserDelete(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
+// airflowUserLocked returns whether a user can't log in: Airflow deactivates
+// users, and the provider user_lockout_threshold counts users with as many
+// failed logins in a row as locked out, like auth managers that lock them.
+func airflowUserLocked(m interface{}, user airflow.UserCollectionItem) bool {
+	if !user.GetActive() {
+		return true
+	}
+
+	threshold := m.(ProviderConfig).UserLockoutThreshold
+	return threshold > 0 && int(user.GetFailedLoginCount()) >= threshold
+}
+
+// createMissingRoles creates the roles that don't exist yet without any
+// permissions, so that users can be created before their roles are managed.
+func createMissingRoles(pcfg ProviderConfig, roles []airflow.UserCollectionItemRoles) error {
+	roleApi := pcfg.ApiClient.RoleApi
+
+	for _, r := range roles {
+		name := r.GetName()
+
+		_, resp, err := roleApi.GetRole(pcfg.AuthContext, name).Execute()
+		if err == nil {
+			continue
+		}
+		if resp == nil || resp.StatusCode != 404 {
+			return fmt.Errorf("failed to get role `%s` from Airflow: %w", name, err)
+		}
+
+		log.Printf("[INFO] Creating missing role %q", name)
+		actions := []airflow.ActionResource{}
+		_, resp, err = roleApi.PostRole(pcfg.AuthContext).Role(airflow.Role{
+			Name:    &name,
+			Actions: &actions,
+		}).Execute()
+		// The role may have been created concurrently for another user.
+		if err != nil && (resp == nil || resp.StatusCode != 409) {
+			return fmt.Errorf("failed to create role `%s` from Airflow: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// userPasswordUnknown reports whether the password of an existing user isn't
+// known to Terraform, as is the case right after an import. Passwords that
+// are omitted from state are known by their recorded digest.
+func userPasswordUnknown(d *schema.ResourceData) bool {
+	old, _ := d.GetChange("password")
+	_, recorded := sensitiveStateDigest(d, "password")
+	return d.Id() != "" && old.(string) == "" && !recorded
+}
+
+// suppressUserPasswordDiff suppresses the password diff of imported users,
+// whose password isn't known. The configured password is only sent along
+// with the next change of the user instead of forcing an update of its own.
+// Password diffs are never shown when password updates are skipped.
+func suppressUserPasswordDiff(k, oldo, newo string, d *schema.ResourceData) bool {
+	if d.Id() != "" && d.Get("skip_password_update").(bool) {
+		return true
+	}
+	return suppressSensitiveStateDiff(k, oldo, newo, d) || userPasswordUnknown(d)
+}
+
+// mergeDefaultUserRoles returns roles together with the default_user_roles of
+// the provider.
+func mergeDefaultUserRoles(m interface{}, roles *schema.Set) *schema.Set {
+	merged := schema.NewSet(schema.HashString, roles.List())
+	for _, role := range m.(ProviderConfig).DefaultUserRoles {
+		merged.Add(role)
+	}
+	return merged
+}
+
+// withoutDefaultUserRoles returns the roles read from Airflow without the
+// default_user_roles of the provider, unless they are also configured.
+func withoutDefaultUserRoles(m interface{}, roles []string, configured *schema.Set) []string {
+	defaults := map[string]bool{}
+	for _, role := range m.(ProviderConfig).DefaultUserRoles {
+		defaults[role] = true
+	}
+
+	vs := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if defaults[role] && !configured.Contains(role) {
+			continue
+		}
+		vs = append(vs, role)
+	}
+	return vs
+}
+
+// customizeUserRolesAllDiff plans roles_all as the configured roles merged
+// with the default_user_roles of the provider, so that users are updated
+// when the default roles change.
+func customizeUserRolesAllDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("roles") {
+		return d.SetNewComputed("roles_all")
+	}
+	if d.Get("server_managed_attributes").(*schema.Set).Contains("roles") {
+		if d.HasChange("roles") {
+			return d.SetNewComputed("roles_all")
+		}
+		return nil
+	}
+
+	want := mergeDefaultUserRoles(m, d.Get("roles").(*schema.Set))
+	if !want.Equal(d.Get("roles_all").(*schema.Set)) {
+		return d.SetNew("roles_all", want)
+	}
+
+	return nil
+}
+
+// expandAirflowUserRoles returns the roles of a set sorted by name, so that
+// the same roles are always sent in the same order.
 func expandAirflowUserRoles(tfList *schema.Set) []airflow.UserCollectionItemRoles {
 	if tfList.Len() == 0 {
 		return nil
@@ -207,7 +568,9 @@ func expandAirflowUserRoles(tfList *schema.Set) []airflow.UserCollectionItemRole
 
 	apiObjects := make([]airflow.UserCollectionItemRoles, 0)
 
-	for _, tfMapRaw := range tfList.List() {
+	tfRaw := tfList.List()
+	sort.Slice(tfRaw, func(i, j int) bool { return fmt.Sprint(tfRaw[i]) < fmt.Sprint(tfRaw[j]) })
+	for _, tfMapRaw := range tfRaw {
 		val, ok := tfMapRaw.(string)
 
 		if !ok {
@@ -223,11 +586,19 @@ func expandAirflowUserRoles(tfList *schema.Set) []airflow.UserCollectionItemRole
 	return apiObjects
 }
 
+// flattenAirflowUserRoles returns the names of roles sorted and without
+// duplicates, which some Airflow versions return for roles assigned twice,
+// e.g. by an auth manager syncing them from an identity provider.
 func flattenAirflowUserRoles(apiObjects []airflow.UserCollectionItemRoles) []string {
 	vs := make([]string, 0, len(apiObjects))
+	seen := make(map[string]bool, len(apiObjects))
 	for _, v := range apiObjects {
-		name := *v.Name
-		vs = append(vs, name)
+		if v.Name == nil || seen[*v.Name] {
+			continue
+		}
+		seen[*v.Name] = true
+		vs = append(vs, *v.Name)
 	}
+	sort.Strings(vs)
 	return vs
 }
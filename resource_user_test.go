@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -99,3 +107,790 @@ resource "airflow_user" "test" {
 }
 `, rName, fName)
 }
+
+func TestResourceUser_fakeCRUD(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":      "fake-crud@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "fake-crud",
+		"password":   "secret",
+		"roles":      []interface{}{"Viewer"},
+	})
+
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if d.Id() != "fake-crud@example.com" {
+		t.Fatalf("unexpected id %q", d.Id())
+	}
+	if fake.object("users", "fake-crud") == nil {
+		t.Fatal("user was not created in Airflow")
+	}
+
+	d.Set("first_name", "updated")
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := fake.object("users", "fake-crud")["first_name"]; got != "updated" {
+		t.Fatalf("expected first_name to be updated, got %v", got)
+	}
+
+	if err := resourceUserDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("users", "fake-crud") != nil {
+		t.Fatal("user still exists in Airflow")
+	}
+}
+
+func TestResourceUser_fakePagination(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	for i := 0; i < 250; i++ {
+		fake.seed("users", map[string]interface{}{
+			"username":   fmt.Sprintf("paged-%03d", i),
+			"email":      fmt.Sprintf("paged-%03d@example.com", i),
+			"first_name": "first",
+			"last_name":  "last",
+			"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}},
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+	d.SetId("paged-240@example.com")
+
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() == "" {
+		t.Fatal("user on the last page was not found")
+	}
+	if got := d.Get("username").(string); got != "paged-240" {
+		t.Fatalf("expected username paged-240, got %q", got)
+	}
+}
+
+func TestFlattenAirflowUserRoles(t *testing.T) {
+	admin, viewer := "Admin", "Viewer"
+
+	got := flattenAirflowUserRoles([]airflow.UserCollectionItemRoles{
+		{Name: &viewer},
+		{Name: nil},
+		{Name: &admin},
+		{Name: &viewer},
+	})
+
+	if !reflect.DeepEqual(got, []string{"Admin", "Viewer"}) {
+		t.Fatalf("unexpected roles: %v", got)
+	}
+
+	if got := flattenAirflowUserRoles(nil); len(got) != 0 {
+		t.Fatalf("expected no roles, got %v", got)
+	}
+}
+
+func TestResourceUser_fakeRolesNormalized(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	raw := map[string]interface{}{
+		"email":      "fake-roles@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "fake-roles",
+		"password":   "secret",
+		"roles":      []interface{}{"Viewer", "Admin"},
+	}
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// Airflow returns a role twice.
+	user := fake.object("users", "fake-roles")
+	user["roles"] = []interface{}{
+		map[string]interface{}{"name": "Viewer"},
+		map[string]interface{}{"name": "Admin"},
+		map[string]interface{}{"name": "Viewer"},
+	}
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	// A dynamic role list in another order and with a duplicate.
+	raw["roles"] = []interface{}{"Admin", "Viewer", "Admin"}
+	diff, err := resourceUser().Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(raw), m)
+	if err != nil {
+		t.Fatalf("diff: %s", err)
+	}
+	if diff != nil && len(diff.Attributes) > 0 {
+		t.Fatalf("expected no diff, got %v", diff.Attributes)
+	}
+}
+
+func TestResourceUser_fakeWriteOnlyPassword(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var sentPassword string
+	fake.handle(http.MethodPost, "/users", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sentPassword, _ = body["password"].(string)
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":               "fake-wo@example.com",
+		"first_name":          "first",
+		"last_name":           "last",
+		"username":            "fake-wo",
+		"password_wo":         "secret",
+		"password_wo_version": 1,
+		"roles":               []interface{}{"Viewer"},
+	})
+
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if sentPassword != "secret" {
+		t.Fatalf("expected the write-only password to be sent, got %q", sentPassword)
+	}
+	if got := d.Get("password_wo").(string); got != "" {
+		t.Fatalf("expected the write-only password to be cleared from state, got %q", got)
+	}
+	if !suppressWriteOnlyDiff("password_wo", "", "changed", d) {
+		t.Fatal("expected write-only password diffs to be suppressed")
+	}
+}
+
+func TestResourceUser_fakeMissingPassword(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":      "fake-nopw@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "fake-nopw",
+		"roles":      []interface{}{"Viewer"},
+	})
+
+	err := resourceUserCreate(d, m)
+	if err == nil || !strings.Contains(err.Error(), "one of `password`, `password_wo` or `generate_password` must be set") {
+		t.Fatalf("expected a user without a password to be rejected, got %v", err)
+	}
+	if got := fake.requestCount(http.MethodPost, "/users"); got != 0 {
+		t.Fatalf("expected no user to be created, got %d requests", got)
+	}
+}
+
+func TestResourceUser_fakeRotationTriggers(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var patchedPasswords []interface{}
+	fake.handle(http.MethodPatch, "/users/fake-rotate", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		patchedPasswords = append(patchedPasswords, body["password"])
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	raw := map[string]interface{}{
+		"email":             "fake-rotate@example.com",
+		"first_name":        "first",
+		"last_name":         "last",
+		"username":          "fake-rotate",
+		"password":          "secret",
+		"roles":             []interface{}{"Viewer"},
+		"rotation_triggers": map[string]interface{}{"rotation": "1"},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	raw["first_name"] = "updated"
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	raw["rotation_triggers"] = map[string]interface{}{"rotation": "2"}
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	if len(patchedPasswords) != 2 || patchedPasswords[0] != nil || patchedPasswords[1] != "secret" {
+		t.Fatalf("expected the password to be sent only on rotation, got %v", patchedPasswords)
+	}
+}
+
+func TestResourceUser_fakeGeneratePassword(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.PasswordPolicy = &passwordPolicy{MinLength: 40, RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true}
+
+	var postedPassword interface{}
+	fake.handle(http.MethodPost, "/users", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		postedPassword = body["password"]
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+	var patchedPasswords []interface{}
+	fake.handle(http.MethodPatch, "/users/fake-generated", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		patchedPasswords = append(patchedPasswords, body["password"])
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	raw := map[string]interface{}{
+		"email":             "fake-generated@example.com",
+		"first_name":        "first",
+		"last_name":         "last",
+		"username":          "fake-generated",
+		"roles":             []interface{}{"Viewer"},
+		"generate_password": true,
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	generated := d.Get("generated_password").(string)
+	if len(generated) != 40 {
+		t.Fatalf("expected a password of the policy min_length, got %d characters", len(generated))
+	}
+	if err := checkPassword(m, "fake-generated@example.com", generated); err != nil {
+		t.Fatalf("expected the generated password to meet the policy: %s", err)
+	}
+	if postedPassword != generated {
+		t.Fatal("expected the generated password to be sent to Airflow")
+	}
+
+	// Rotating generates a new password.
+	raw["rotation_triggers"] = map[string]interface{}{"rotation": "1"}
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	rotated := d.Get("generated_password").(string)
+	if len(patchedPasswords) != 1 || patchedPasswords[0] != rotated || rotated == generated {
+		t.Fatalf("expected a new generated password to be sent on rotation, got %v", patchedPasswords)
+	}
+
+	raw["first_name"] = "updated"
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if len(patchedPasswords) != 2 || patchedPasswords[1] != nil || d.Get("generated_password") != rotated {
+		t.Fatalf("expected the generated password to be kept on other updates, got %v", patchedPasswords)
+	}
+}
+
+func TestResourceUser_fakeSkipPasswordUpdate(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var postedPassword interface{}
+	var patchedPasswords []interface{}
+	fake.handle(http.MethodPost, "/users", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		postedPassword = body["password"]
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+	fake.handle(http.MethodPatch, "/users/fake-skip", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		patchedPasswords = append(patchedPasswords, body["password"])
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	raw := map[string]interface{}{
+		"email":                "fake-skip@example.com",
+		"first_name":           "first",
+		"last_name":            "last",
+		"username":             "fake-skip",
+		"password":             "initial",
+		"roles":                []interface{}{"Viewer"},
+		"skip_password_update": true,
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if postedPassword != "initial" {
+		t.Fatalf("expected the password to be sent on create, got %v", postedPassword)
+	}
+
+	raw["first_name"] = "updated"
+	raw["password"] = "changed"
+	raw["rotation_triggers"] = map[string]interface{}{"rotation": "1"}
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if d.HasChange("password") {
+		t.Fatal("expected the password diff to be suppressed")
+	}
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	if len(patchedPasswords) != 1 || patchedPasswords[0] != nil {
+		t.Fatalf("expected the password never to be patched, got %v", patchedPasswords)
+	}
+}
+
+// TestResourceUser_fakeOmittedPassword covers a password that isn't stored
+// in state: it is only sent when its configuration changes, which its
+// recorded digest detects.
+func TestResourceUser_fakeOmittedPassword(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0, "sensitive_state_mode": sensitiveStateOmit})
+
+	var patchedPasswords []interface{}
+	fake.handle(http.MethodPatch, "/users/fake-omitted", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		patchedPasswords = append(patchedPasswords, body["password"])
+		delete(body, "password")
+		fake.seed("users", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	raw := map[string]interface{}{
+		"email":      "fake-omitted@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "fake-omitted",
+		"password":   "initial",
+		"roles":      []interface{}{"Viewer"},
+	}
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("password").(string); got != "" {
+		t.Fatalf("expected the password to be omitted from state, got %q", got)
+	}
+
+	raw["first_name"] = "updated"
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if d.HasChange("password") {
+		t.Fatal("expected no password diff when it didn't change")
+	}
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	raw["password"] = "changed"
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if !d.HasChange("password") {
+		t.Fatal("expected a password diff when it changed")
+	}
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	if len(patchedPasswords) != 2 || patchedPasswords[0] != nil || patchedPasswords[1] != "changed" {
+		t.Fatalf("expected the password to be patched only once it changed, got %v", patchedPasswords)
+	}
+}
+
+func TestResourceUser_fakeImportedPassword(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("users", map[string]interface{}{
+		"username":   "fake-imported",
+		"email":      "fake-imported@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}},
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+	d.SetId("fake-imported@example.com")
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	raw := map[string]interface{}{
+		"email":      "fake-imported@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "fake-imported",
+		"password":   "secret",
+		"roles":      []interface{}{"Viewer"},
+	}
+
+	diff, err := resourceUser().Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(raw), m)
+	if err != nil {
+		t.Fatalf("diff: %s", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Fatalf("expected no diff after import, got %#v", diff.Attributes)
+	}
+}
+
+func TestResourceUser_fakeServerManagedRoles(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	var patchedRoles []interface{}
+	fake.handle(http.MethodPatch, "/users/fake-managed", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		patchedRoles = append(patchedRoles, body["roles"])
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	raw := map[string]interface{}{
+		"email":                     "fake-managed@example.com",
+		"first_name":                "first",
+		"last_name":                 "last",
+		"username":                  "fake-managed",
+		"password":                  "secret",
+		"roles":                     []interface{}{"Viewer"},
+		"server_managed_attributes": []interface{}{"roles"},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// Cloud Composer appends a role after the user logs in.
+	user := fake.object("users", "fake-managed")
+	user["roles"] = []interface{}{
+		map[string]interface{}{"name": "Viewer"},
+		map[string]interface{}{"name": "Op"},
+	}
+	fake.seed("users", user)
+
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("roles").(*schema.Set).Len(); got != 1 {
+		t.Fatalf("expected the server managed roles to keep the configured value, got %d roles", got)
+	}
+
+	raw["first_name"] = "updated"
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if d.HasChange("roles") {
+		t.Fatal("expected no roles diff")
+	}
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	if len(patchedRoles) != 1 || patchedRoles[0] != nil {
+		t.Fatalf("expected the roles not to be sent, got %v", patchedRoles)
+	}
+}
+
+func TestResourceUser_fakeCreateMissingRoles(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("roles", map[string]interface{}{"name": "Viewer"})
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":                "fake-roles@example.com",
+		"first_name":           "first",
+		"last_name":            "last",
+		"username":             "fake-roles",
+		"password":             "secret",
+		"roles":                []interface{}{"Viewer", "Data Team"},
+		"create_missing_roles": true,
+	})
+
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if fake.object("roles", "Data Team") == nil {
+		t.Fatal("missing role was not created in Airflow")
+	}
+	if got := fake.requestCount(http.MethodPost, "/roles"); got != 1 {
+		t.Fatalf("expected only the missing role to be created, got %d requests", got)
+	}
+}
+
+func TestResourceUser_fakeDefaultUserRoles(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.DefaultUserRoles = []string{"Viewer"}
+
+	raw := map[string]interface{}{
+		"email":      "fake-defaults@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "fake-defaults",
+		"password":   "secret",
+		"roles":      []interface{}{"Op"},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, raw)
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if got := fake.object("users", "fake-defaults")["roles"].([]interface{}); len(got) != 2 {
+		t.Fatalf("expected the default roles to be added in Airflow, got %v", got)
+	}
+	if got := d.Get("roles").(*schema.Set).List(); len(got) != 1 || got[0] != "Op" {
+		t.Fatalf("expected the default roles to be left out of roles, got %v", got)
+	}
+	if got := d.Get("roles_all").(*schema.Set).Len(); got != 2 {
+		t.Fatalf("expected 2 roles in roles_all, got %d", got)
+	}
+
+	// A new default role updates existing users.
+	m.DefaultUserRoles = []string{"Viewer", "Auditor"}
+	d = testResourceDataUpdate(t, resourceUser(), d.State(), raw, m)
+	if !d.HasChange("roles_all") {
+		t.Fatal("expected a roles_all diff")
+	}
+	if err := resourceUserUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := fake.object("users", "fake-defaults")["roles"].([]interface{}); len(got) != 3 {
+		t.Fatalf("expected the new default role to be added in Airflow, got %v", got)
+	}
+	if got := d.Get("roles").(*schema.Set).Len(); got != 1 {
+		t.Fatalf("expected 1 configured role, got %d", got)
+	}
+}
+
+func TestResourceUser_fakeLocked(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.UserLockoutThreshold = 3
+
+	for username, u := range map[string]map[string]interface{}{
+		"active":   {"active": true, "failed_login_count": 2},
+		"failing":  {"active": true, "failed_login_count": 3},
+		"inactive": {"active": false, "failed_login_count": 0},
+	} {
+		u["username"] = username
+		u["email"] = username + "@example.com"
+		u["last_login"] = "2022-05-01T00:00:00+00:00"
+		u["login_count"] = 7
+		fake.seed("users", u)
+	}
+
+	for username, locked := range map[string]bool{"active": false, "failing": true, "inactive": true} {
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+		d.SetId(username + "@example.com")
+		if err := resourceUserRead(d, m); err != nil {
+			t.Fatalf("read: %s", err)
+		}
+		if got := d.Get("locked").(bool); got != locked {
+			t.Fatalf("expected %s to have locked = %t, got %t", username, locked, got)
+		}
+		if got := d.Get("login_count").(int); got != 7 {
+			t.Fatalf("expected login_count 7, got %d", got)
+		}
+		if got := d.Get("last_login").(string); got != "2022-05-01T00:00:00+00:00" {
+			t.Fatalf("unexpected last_login %q", got)
+		}
+	}
+}
+
+func TestResourceUserUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"email":       "user@example.com",
+		"login_count": "2022-05-01T00:00:00+00:00",
+	}
+
+	got, err := resourceUserUpgradeV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatalf("upgrade: %s", err)
+	}
+	if got["last_login"] != "2022-05-01T00:00:00+00:00" || got["login_count"] != 0 {
+		t.Fatalf("unexpected upgraded state %v", got)
+	}
+}
+
+func TestResourceUser_fakeSelfManagement(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	r := resourceUser()
+	wrapRefreshSkipping(r)
+	wrapSelfManagementWarning(r)
+
+	// The fake provider authenticates as admin.
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"email":      "admin@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "admin",
+		"password":   "secret",
+		"roles":      []interface{}{"Admin"},
+	})
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	diags := r.ReadContext(context.Background(), d, m)
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+
+	if err := resourceUserDelete(d, m); err == nil {
+		t.Fatal("expected deleting the provider user to be refused")
+	}
+	if fake.object("users", "admin") == nil {
+		t.Fatal("the provider user was deleted")
+	}
+
+	d.Set("allow_self_management", true)
+	if diags := r.ReadContext(context.Background(), d, m); len(diags) != 0 {
+		t.Fatalf("expected no warning with allow_self_management, got %v", diags)
+	}
+	if err := resourceUserDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("users", "admin") != nil {
+		t.Fatal("user still exists in Airflow")
+	}
+}
+
+func TestResourceUser_fakeTargetedRead(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":      "target@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "target",
+		"password":   "secret",
+		"roles":      []interface{}{"Viewer"},
+	})
+	if err := resourceUserCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// A user whose username is known is read without listing all users.
+	listed := 0
+	fake.handle(http.MethodGet, "/users", func(w http.ResponseWriter, r *http.Request) {
+		listed++
+		writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"users": []interface{}{}, "total_entries": 0})
+	})
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() == "" || listed != 0 {
+		t.Fatalf("expected the user to be read by username, got id %q after %d lists", d.Id(), listed)
+	}
+
+	// A user deleted outside of Terraform is gone on the next read, even
+	// after it was read before.
+	if _, err := m.ApiClient.UserApi.DeleteUser(m.AuthContext, "target").Execute(); err != nil {
+		t.Fatalf("delete outside of Terraform: %s", err)
+	}
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected the deleted user to be removed from state, got %q", d.Id())
+	}
+}
+
+func TestResourceUser_fakeRenamedRead(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	// Cloud Composer replaces the username on the first login.
+	fake.seed("users", map[string]interface{}{
+		"username":   "accounts.google.com:1234",
+		"email":      "renamed@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}},
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+		"email":      "renamed@example.com",
+		"first_name": "first",
+		"last_name":  "last",
+		"username":   "renamed@example.com",
+		"roles":      []interface{}{"Viewer"},
+	})
+	d.SetId("renamed@example.com")
+
+	if err := resourceUserRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("username").(string); got != "accounts.google.com:1234" {
+		t.Fatalf("expected the renamed user to be found by e-mail, got %q", got)
+	}
+}
+
+func TestResourceUser_fakeImport(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	fake.seed("users", map[string]interface{}{
+		"username":   "alice",
+		"email":      "alice@example.com",
+		"first_name": "Alice",
+		"last_name":  "Doe",
+		"roles":      []interface{}{map[string]interface{}{"name": "Viewer"}},
+	})
+
+	// A username takes a single call, an e-mail is searched for after it.
+	for id, calls := range map[string]int{"alice@example.com": 2, "alice": 1} {
+		d := resourceUser().Data(nil)
+		d.SetId(id)
+
+		before := fake.requestCount(http.MethodGet, "/users")
+		ds, err := resourceUserImport(context.Background(), d, m)
+		if err != nil {
+			t.Fatalf("import %s: %s", id, err)
+		}
+		if got := fake.requestCount(http.MethodGet, "/users") - before; got != calls {
+			t.Fatalf("expected %d calls importing %s, got %d", calls, id, got)
+		}
+		if got := ds[0].Id(); got != "alice@example.com" {
+			t.Fatalf("expected the e-mail as ID importing %s, got %q", id, got)
+		}
+		if got := ds[0].Get("username").(string); got != "alice" {
+			t.Fatalf("expected the username importing %s, got %q", id, got)
+		}
+	}
+
+	for _, id := range []string{"", "bob", "bob@example.com"} {
+		d := resourceUser().Data(nil)
+		d.SetId(id)
+		if _, err := resourceUserImport(context.Background(), d, m); err == nil {
+			t.Fatalf("expected an error importing %q", id)
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceUsers manages many users in a single resource. Users are matched
+// by e-mail like airflow_user and all of them are read with one paginated
+// list call instead of one call per user.
+func resourceUsers() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceUsersCreate,
+		Read:   resourceUsersRead,
+		Update: resourceUsersUpdate,
+		Delete: resourceUsersDelete,
+		CustomizeDiff: customdiff.All(
+			customizePendingChangesDiff("user", "email", expandAirflowUsers,
+				func(u airflow.User) string { return u.GetEmail() }, airflowUserEqual),
+			customizeUsersPasswordPolicyDiff,
+		),
+		Schema: map[string]*schema.Schema{
+			"user": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"email": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"username": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"first_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"last_name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"roles": {
+							Type:     schema.TypeSet,
+							Required: true,
+							MinItems: 1,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"password": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSensitiveStateListDiff("email"),
+						},
+						"active": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"last_login": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"login_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"failed_login_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"locked": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"authoritative_prefix":    authoritativePrefixSchema(),
+			"unmanaged":               unmanagedSchema(),
+			"pending_changes":         pendingChangesSchema(),
+			"sensitive_state_mode":    sensitiveStateModeSchema(),
+			"sensitive_state_digests": sensitiveStateDigestsSchema(),
+		},
+	}
+}
+
+func resourceUsersCreate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	users, err := expandAirflowUsers(d.Get("user").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	passwords := configuredUserPasswords(d)
+	for i, user := range users {
+		if password := passwords[user.GetEmail()]; password != "" {
+			users[i].SetPassword(password)
+		}
+		if err := checkPassword(m, user.GetEmail(), users[i].GetPassword()); err != nil {
+			return err
+		}
+	}
+
+	created := make([]bool, len(users))
+	calls := make([]func() error, 0, len(users))
+	for i, user := range users {
+		i, user := i, user
+		calls = append(calls, func() error {
+			if _, _, err := client.UserApi.PostUser(pcfg.AuthContext).User(user).Execute(); err != nil {
+				return fmt.Errorf("failed to create user `%s` from Airflow: %w", user.GetEmail(), err)
+			}
+			created[i] = true
+			return nil
+		})
+	}
+	err = runConcurrently(m, calls)
+
+	// When some of the users failed, the ones that were created are kept in
+	// state, so that they aren't left behind in Airflow.
+	mode := effectiveSensitiveStateMode(m, sensitiveStatePlain)
+	var tfList []interface{}
+	sent := map[string]string{}
+	for i, v := range d.Get("user").([]interface{}) {
+		if created[i] {
+			tfList = append(tfList, withUserStatePassword(v, mode, passwords))
+			if email := users[i].GetEmail(); passwords[email] != "" {
+				sent[email] = passwords[email]
+			}
+		}
+	}
+	if len(tfList) == 0 {
+		return err
+	}
+	d.SetId(resource.UniqueId())
+	d.Set("sensitive_state_mode", mode)
+	if err := recordSensitiveStateDigests(d, mode, sent); err != nil {
+		return err
+	}
+	if err := d.Set("user", tfList); err != nil {
+		return fmt.Errorf("error setting user: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Unmanaged users are only found by this read and deleted by the
+	// following apply, after the plan showed them.
+	return resourceUsersRead(d, m)
+}
+
+func resourceUsersRead(d *schema.ResourceData, m interface{}) error {
+	managed := d.Get("user").([]interface{})
+	wanted := make(map[string]bool, len(managed))
+	for _, v := range managed {
+		wanted[v.(map[string]interface{})["email"].(string)] = true
+	}
+
+	// Only keep the managed users and stop paging once all are found,
+	// unless all users under the authoritative_prefix are looked for.
+	prefix := d.Get("authoritative_prefix").(string)
+	remote := make(map[string]airflow.UserCollectionItem, len(managed))
+	unmanaged := []string{}
+	err := searchUsers(m, func(u airflow.UserCollectionItem) bool {
+		if wanted[u.GetEmail()] {
+			remote[u.GetEmail()] = u
+		} else if isUnmanaged(prefix, u.GetUsername(), false) {
+			unmanaged = append(unmanaged, u.GetEmail())
+		}
+		return prefix != "" || len(remote) < len(wanted)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Keep the order of the state so the list doesn't show a diff. Users
+	// that were removed outside of Terraform are dropped to be recreated.
+	var users []interface{}
+	for _, v := range managed {
+		tfMap := v.(map[string]interface{})
+		user, exists := remote[tfMap["email"].(string)]
+		if !exists {
+			log.Printf("[WARN] User `%s` not found in Airflow, removing it from state", tfMap["email"])
+			continue
+		}
+
+		users = append(users, map[string]interface{}{
+			"email":      user.GetEmail(),
+			"username":   user.GetUsername(),
+			"first_name": user.GetFirstName(),
+			"last_name":  user.GetLastName(),
+			"roles":      flattenAirflowUserRoles(user.GetRoles()),
+			"password":   tfMap["password"],
+			"active":     user.GetActive(),
+
+			"last_login":         user.GetLastLogin(),
+			"login_count":        user.GetLoginCount(),
+			"failed_login_count": user.GetFailedLoginCount(),
+			"locked":             airflowUserLocked(m, user),
+		})
+	}
+
+	if err := d.Set("user", users); err != nil {
+		return fmt.Errorf("error setting user: %w", err)
+	}
+	setUnmanaged(d, unmanaged)
+	d.Set("pending_changes", []interface{}{})
+	d.Set("sensitive_state_mode", effectiveSensitiveStateMode(m, sensitiveStatePlain))
+	keepSensitiveStateDigests(d)
+
+	return nil
+}
+
+func resourceUsersUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	o, n := d.GetChange("user")
+	oldUsers, err := expandAirflowUsers(o.([]interface{}))
+	if err != nil {
+		return err
+	}
+	newUsers, err := expandAirflowUsers(n.([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	oldByEmail := make(map[string]airflow.User, len(oldUsers))
+	for _, user := range oldUsers {
+		oldByEmail[user.GetEmail()] = user
+	}
+
+	// The new value of a suppressed password is its state representation,
+	// so the configured passwords are compared by that representation, or
+	// by their recorded digest when omitted, and sent as configured.
+	mode := d.Get("sensitive_state_mode").(string)
+	passwords := configuredUserPasswords(d)
+	var calls []func() error
+	for _, user := range newUsers {
+		user, email := user, user.GetEmail()
+		old, exists := oldByEmail[email]
+		delete(oldByEmail, email)
+
+		password := passwords[email]
+		passwordChanged := password != "" && !sensitiveStateUnchanged(d, email, mode, old.GetPassword(), password)
+		user.Password = old.Password
+
+		if !exists {
+			user.Password = nil
+			if password != "" {
+				user.SetPassword(password)
+			}
+			if err := checkPassword(m, email, user.GetPassword()); err != nil {
+				return err
+			}
+			calls = append(calls, func() error {
+				if _, _, err := client.UserApi.PostUser(pcfg.AuthContext).User(user).Execute(); err != nil {
+					return fmt.Errorf("failed to create user `%s` from Airflow: %w", email, err)
+				}
+				return nil
+			})
+			continue
+		}
+
+		if !passwordChanged && airflowUserEqual(old, user) {
+			continue
+		}
+
+		// Passwords are only sent when they changed, like on airflow_user.
+		if !passwordChanged {
+			user.Password = nil
+		} else {
+			user.SetPassword(password)
+			if err := checkPassword(m, email, password); err != nil {
+				return err
+			}
+		}
+
+		// Do use username and not the e-mail when making API calls.
+		calls = append(calls, func() error {
+			if _, _, err := client.UserApi.PatchUser(pcfg.AuthContext, old.GetUsername()).User(user).Execute(); err != nil {
+				return fmt.Errorf("failed to update user `%s` from Airflow: %w", email, err)
+			}
+			return nil
+		})
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	// Users are only deleted once the others were created and updated, as
+	// before, so that a username can move from one e-mail to another.
+	calls = nil
+	for email, user := range oldByEmail {
+		email, username := email, user.GetUsername()
+		calls = append(calls, func() error { return deleteAirflowUserByUsername(pcfg, email, username) })
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	if err := deleteUnmanagedUsers(d, m); err != nil {
+		return err
+	}
+
+	mode = effectiveSensitiveStateMode(m, sensitiveStatePlain)
+	var tfList []interface{}
+	sent := map[string]string{}
+	for _, v := range d.Get("user").([]interface{}) {
+		tfList = append(tfList, withUserStatePassword(v, mode, passwords))
+		if email := v.(map[string]interface{})["email"].(string); passwords[email] != "" {
+			sent[email] = passwords[email]
+		}
+	}
+	d.Set("sensitive_state_mode", mode)
+	if err := recordSensitiveStateDigests(d, mode, sent); err != nil {
+		return err
+	}
+	if err := d.Set("user", tfList); err != nil {
+		return fmt.Errorf("error setting user: %w", err)
+	}
+
+	return resourceUsersRead(d, m)
+}
+
+func resourceUsersDelete(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	var calls []func() error
+	for _, v := range d.Get("user").([]interface{}) {
+		tfMap := v.(map[string]interface{})
+		email, username := tfMap["email"].(string), tfMap["username"].(string)
+		calls = append(calls, func() error { return deleteAirflowUserByUsername(pcfg, email, username) })
+	}
+
+	return runConcurrently(m, calls)
+}
+
+// deleteUnmanagedUsers deletes the unmanaged users, which are tracked by
+// e-mail like the managed ones but are deleted by username.
+func deleteUnmanagedUsers(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+
+	usernames := map[string]string{}
+	if len(unmanagedKeys(d)) > 0 {
+		err := searchUsers(m, func(u airflow.UserCollectionItem) bool {
+			usernames[u.GetEmail()] = u.GetUsername()
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return deleteUnmanaged(d, m, "user", "email", func(email string) error {
+		username, exists := usernames[email]
+		if !exists {
+			return nil
+		}
+		return deleteAirflowUserByUsername(pcfg, email, username)
+	})
+}
+
+func deleteAirflowUserByUsername(pcfg ProviderConfig, email, username string) error {
+	resp, err := pcfg.ApiClient.UserApi.DeleteUser(pcfg.AuthContext, username).Execute()
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return fmt.Errorf("failed to delete user `%s` from Airflow: %w", email, err)
+	}
+	return nil
+}
+
+// configuredUserPasswords returns the configured passwords of the users by
+// e-mail. They are read from the raw configuration because a suppressed diff
+// leaves a password with its state value, which may be a digest or empty.
+func configuredUserPasswords(d configReader) map[string]string {
+	passwords := map[string]string{}
+
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute("user") {
+		for _, v := range d.Get("user").([]interface{}) {
+			tfMap := v.(map[string]interface{})
+			passwords[tfMap["email"].(string)] = tfMap["password"].(string)
+		}
+		return passwords
+	}
+
+	users := config.GetAttr("user")
+	if users.IsNull() || !users.IsKnown() {
+		return passwords
+	}
+	for it := users.ElementIterator(); it.Next(); {
+		_, user := it.Element()
+		if user.IsNull() || !user.IsKnown() {
+			continue
+		}
+		email, password := user.GetAttr("email"), user.GetAttr("password")
+		if email.IsNull() || !email.IsKnown() || password.IsNull() || !password.IsKnown() {
+			continue
+		}
+		passwords[email.AsString()] = password.AsString()
+	}
+
+	return passwords
+}
+
+// withUserStatePassword returns a user of the configuration with its
+// password in the representation that is stored in state.
+func withUserStatePassword(v interface{}, mode string, passwords map[string]string) interface{} {
+	tfMap := make(map[string]interface{})
+	for k, v := range v.(map[string]interface{}) {
+		tfMap[k] = v
+	}
+	tfMap["password"] = ""
+	if password := passwords[tfMap["email"].(string)]; password != "" {
+		tfMap["password"] = sensitiveStateValue(mode, password)
+	}
+	return tfMap
+}
+
+func expandAirflowUsers(tfList []interface{}) ([]airflow.User, error) {
+	apiObjects := make([]airflow.User, 0, len(tfList))
+	seen := make(map[string]bool, len(tfList))
+
+	for _, v := range tfList {
+		tfMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		email := tfMap["email"].(string)
+		if seen[email] {
+			return nil, fmt.Errorf("user `%s` is defined more than once", email)
+		}
+		seen[email] = true
+
+		username := tfMap["username"].(string)
+		firstName := tfMap["first_name"].(string)
+		lastName := tfMap["last_name"].(string)
+		roles := expandAirflowUserRoles(tfMap["roles"].(*schema.Set))
+
+		apiObject := airflow.User{
+			Email:     &email,
+			Username:  &username,
+			FirstName: &firstName,
+			LastName:  &lastName,
+			Roles:     &roles,
+		}
+		if password := tfMap["password"].(string); password != "" {
+			apiObject.SetPassword(password)
+		}
+
+		apiObjects = append(apiObjects, apiObject)
+	}
+
+	return apiObjects, nil
+}
+
+func airflowUserEqual(a, b airflow.User) bool {
+	if a.GetUsername() != b.GetUsername() || a.GetFirstName() != b.GetFirstName() ||
+		a.GetLastName() != b.GetLastName() || a.GetPassword() != b.GetPassword() {
+		return false
+	}
+
+	aRoles := flattenAirflowUserRoles(a.GetRoles())
+	bRoles := flattenAirflowUserRoles(b.GetRoles())
+	if len(aRoles) != len(bRoles) {
+		return false
+	}
+	for i := range aRoles {
+		if aRoles[i] != bRoles[i] {
+			return false
+		}
+	}
+
+	return true
+}
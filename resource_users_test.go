@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccAirflowUsers_basic(t *testing.T) {
+	rName := acctest.RandomWithPrefix("tf-acc-test")
+
+	resourceName := "airflow_users.test"
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAirflowUsersConfigBasic(rName, 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "user.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "user.0.email", rName+"-0@example.com"),
+					resource.TestCheckResourceAttr(resourceName, "user.0.active", "true"),
+					resource.TestCheckResourceAttr(resourceName, "user.1.roles.#", "1"),
+				),
+			},
+			{
+				Config: testAccAirflowUsersConfigBasic(rName, 3),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "user.#", "3"),
+					resource.TestCheckResourceAttr(resourceName, "user.2.email", rName+"-2@example.com"),
+				),
+			},
+		},
+	})
+}
+
+func testAccAirflowUsersConfigBasic(rName string, count int) string {
+	return fmt.Sprintf(`
+resource "airflow_users" "test" {
+  dynamic "user" {
+    for_each = range(%[2]d)
+
+    content {
+      email      = "%[1]s-${user.value}@example.com"
+      username   = "%[1]s-${user.value}"
+      first_name = "first"
+      last_name  = "last"
+      password   = %[1]q
+      roles      = ["Viewer"]
+    }
+  }
+}
+`, rName, count)
+}
+
+func TestResourceUsers_fakeReconcile(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	user := func(name, firstName string) map[string]interface{} {
+		return map[string]interface{}{
+			"email":      name + "@example.com",
+			"username":   name,
+			"first_name": firstName,
+			"last_name":  "last",
+			"roles":      []interface{}{"Viewer"},
+		}
+	}
+
+	raw := map[string]interface{}{
+		"user": []interface{}{user("fake-a", "a"), user("fake-b", "b")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceUsers().Schema, raw)
+	if err := resourceUsersCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("user.#").(int); got != 2 {
+		t.Fatalf("expected 2 users in state, got %d", got)
+	}
+
+	// Drop fake-a, update fake-b and add fake-c.
+	raw["user"] = []interface{}{user("fake-b", "updated"), user("fake-c", "c")}
+	d = testResourceDataUpdate(t, resourceUsers(), d.State(), raw, m)
+	if err := resourceUsersUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+
+	if fake.object("users", "fake-a") != nil {
+		t.Fatal("expected fake-a to be deleted")
+	}
+	if got := fake.object("users", "fake-b")["first_name"]; got != "updated" {
+		t.Fatalf("expected fake-b to be updated, got %v", got)
+	}
+	if fake.object("users", "fake-c") == nil {
+		t.Fatal("expected fake-c to be created")
+	}
+	if got := d.Get("user.0.email").(string); got != "fake-b@example.com" {
+		t.Fatalf("expected the state to keep the configured order, got %s first", got)
+	}
+
+	before := fake.requestCount("GET", "/users")
+	if err := resourceUsersRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := fake.requestCount("GET", "/users") - before; got != 1 {
+		t.Fatalf("expected a single list call to read all users, got %d", got)
+	}
+
+	if err := resourceUsersDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("users", "fake-b") != nil || fake.object("users", "fake-c") != nil {
+		t.Fatal("users still exist in Airflow")
+	}
+}
+
+func TestResourceUsers_duplicateEmail(t *testing.T) {
+	_, err := expandAirflowUsers([]interface{}{
+		map[string]interface{}{"email": "dup@example.com", "username": "a", "first_name": "a", "last_name": "a", "roles": schema.NewSet(schema.HashString, []interface{}{"Viewer"}), "password": ""},
+		map[string]interface{}{"email": "dup@example.com", "username": "b", "first_name": "b", "last_name": "b", "roles": schema.NewSet(schema.HashString, []interface{}{"Viewer"}), "password": ""},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a duplicate e-mail")
+	}
+}
+
+func TestResourceUsers_fakeAuthoritativePrefix(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	for _, username := range []string{"team-a-old", "team-b-bob"} {
+		fake.seed("users", map[string]interface{}{"username": username, "email": username + "@example.com"})
+	}
+
+	raw := map[string]interface{}{
+		"authoritative_prefix": "team-a-",
+		"user": []interface{}{map[string]interface{}{
+			"email":      "team-a-alice@example.com",
+			"username":   "team-a-alice",
+			"first_name": "alice",
+			"last_name":  "last",
+			"roles":      []interface{}{"Viewer"},
+		}},
+	}
+	d := schema.TestResourceDataRaw(t, resourceUsers().Schema, raw)
+	if err := resourceUsersCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	if fake.object("users", "team-a-old") == nil {
+		t.Fatal("expected the create to delete nothing when the prefix is first set")
+	}
+	if got := d.Get("unmanaged").([]interface{}); len(got) != 1 || got[0] != "team-a-old@example.com" {
+		t.Fatalf("unexpected unmanaged users: %v", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceUsers(), d.State(), raw, m)
+	if err := resourceUsersUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if fake.object("users", "team-a-old") != nil {
+		t.Fatal("expected the unmanaged user under the prefix to be deleted by the next apply")
+	}
+	if fake.object("users", "team-b-bob") == nil || fake.object("users", "team-a-alice") == nil {
+		t.Fatal("expected the managed user and the user of another team to be kept")
+	}
+}
+
+func TestResourceUsers_fakeSensitiveStateMode(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{
+		"max_retries":          0,
+		"bulk_parallelism":     1,
+		"sensitive_state_mode": sensitiveStateHash,
+	})
+
+	var patched []string
+	fake.handle(http.MethodPatch, "/users/fake-b", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		patched = append(patched, fmt.Sprint(body["password"]))
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	user := func(name, password string) map[string]interface{} {
+		return map[string]interface{}{
+			"email":      name + "@example.com",
+			"username":   name,
+			"first_name": name,
+			"last_name":  "last",
+			"roles":      []interface{}{"Viewer"},
+			"password":   password,
+		}
+	}
+
+	// The first user fails to be created, the second one is kept in state.
+	fake.failNext(http.MethodPost, "/users", http.StatusInternalServerError, 1)
+	raw := map[string]interface{}{
+		"user": []interface{}{user("fake-a", "secret-a"), user("fake-b", "secret-b")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceUsers().Schema, raw)
+	if err := resourceUsersCreate(d, m); err == nil {
+		t.Fatal("expected the create of fake-a to fail")
+	}
+	if d.Id() == "" {
+		t.Fatal("expected the created users to be recorded in state")
+	}
+	if got := d.Get("user.#").(int); got != 1 || d.Get("user.0.email").(string) != "fake-b@example.com" {
+		t.Fatalf("expected only fake-b in state, got %v", d.Get("user"))
+	}
+	if got := d.Get("user.0.password").(string); got != sha256Hex("secret-b") {
+		t.Fatalf("expected the hashed password in state, got %q", got)
+	}
+
+	// fake-a is created by the next apply, fake-b's password is unchanged.
+	d = testResourceDataUpdate(t, resourceUsers(), d.State(), raw, m)
+	if err := resourceUsersUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if fake.object("users", "fake-a") == nil {
+		t.Fatal("expected fake-a to be created")
+	}
+	if len(patched) != 0 {
+		t.Fatalf("expected fake-b not to be updated, got %v", patched)
+	}
+	for i := 0; i < 2; i++ {
+		if got := d.Get(fmt.Sprintf("user.%d.password", i)).(string); got != sha256Hex(raw["user"].([]interface{})[i].(map[string]interface{})["password"].(string)) {
+			t.Fatalf("expected the hashed password of user %d in state, got %q", i, got)
+		}
+	}
+
+	// A changed password is sent as configured.
+	raw["user"] = []interface{}{user("fake-a", "secret-a"), user("fake-b", "rotated")}
+	d = testResourceDataUpdate(t, resourceUsers(), d.State(), raw, m)
+	if err := resourceUsersUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if len(patched) != 1 || patched[0] != "rotated" {
+		t.Fatalf("expected the configured password to be sent, got %v", patched)
+	}
+	if got := d.Get("user.1.password").(string); got != sha256Hex("rotated") {
+		t.Fatalf("expected the hashed password in state, got %q", got)
+	}
+}
+
+// TestResourceUsers_fakeOmittedPasswords covers passwords that aren't stored
+// in state: only a changed password is planned and sent, by its digest.
+func TestResourceUsers_fakeOmittedPasswords(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{
+		"max_retries":          0,
+		"sensitive_state_mode": sensitiveStateOmit,
+	})
+
+	patched := map[string][]interface{}{}
+	for _, name := range []string{"fake-a", "fake-b"} {
+		name := name
+		fake.handle(http.MethodPatch, "/users/"+name, func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			patched[name] = append(patched[name], body["password"])
+			delete(body, "password")
+			writeFakeAirflowJSON(w, http.StatusOK, body)
+		})
+	}
+
+	user := func(name, lastName, password string) map[string]interface{} {
+		return map[string]interface{}{
+			"email":      name + "@example.com",
+			"username":   name,
+			"first_name": name,
+			"last_name":  lastName,
+			"roles":      []interface{}{"Viewer"},
+			"password":   password,
+		}
+	}
+
+	raw := map[string]interface{}{
+		"user": []interface{}{user("fake-a", "last", "secret-a"), user("fake-b", "last", "secret-b")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceUsers().Schema, raw)
+	if err := resourceUsersCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("user.0.password").(string); got != "" {
+		t.Fatalf("expected the password to be omitted from state, got %q", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceUsers(), d.State(), raw, m)
+	if d.HasChange("user") {
+		t.Fatalf("expected no changes, got %v", d.Get("user"))
+	}
+
+	// fake-a only changes its name, fake-b its password.
+	raw["user"] = []interface{}{user("fake-a", "renamed", "secret-a"), user("fake-b", "last", "rotated")}
+	d = testResourceDataUpdate(t, resourceUsers(), d.State(), raw, m)
+	if !d.HasChange("user.1.password") {
+		t.Fatal("expected a password change of fake-b")
+	}
+	if err := resourceUsersUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if len(patched["fake-a"]) != 1 || patched["fake-a"][0] != nil {
+		t.Fatalf("expected fake-a to be updated without its password, got %v", patched["fake-a"])
+	}
+	if len(patched["fake-b"]) != 1 || patched["fake-b"][0] != "rotated" {
+		t.Fatalf("expected the rotated password of fake-b to be sent, got %v", patched["fake-b"])
+	}
+}
@@ -1,18 +1,24 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/apache/airflow-client-go/airflow"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceVariable() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceVariableCreate,
-		Read:   resourceVariableRead,
-		Update: resourceVariableUpdate,
-		Delete: resourceVariableDelete,
+		Create:        resourceVariableCreate,
+		Read:          resourceVariableRead,
+		Update:        resourceVariableUpdate,
+		Delete:        resourceVariableDelete,
+		CustomizeDiff: customizeVariableFullKeyDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -20,12 +26,42 @@ func resourceVariable() *schema.Resource {
 			"key": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
-			"value": {
+			"key_prefix": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+			},
+			"full_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"value": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ExactlyOneOf:     []string{"value", "value_json"},
+				DiffSuppressFunc: suppressSensitiveStateDiff,
+			},
+			"value_json": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressVariableValueJsonDiff,
+			},
+			"store_value_in_state": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
 			},
+			"check_secrets_backend": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"sensitive_state_mode":    sensitiveStateModeSchema(),
+			"sensitive_state_digests": sensitiveStateDigestsSchema(),
+			"manage":                  manageSchema(),
+			"deletion_protection":     deletionProtectionSchema(),
+			"ui_url":                  uiUrlSchema(),
 		},
 	}
 }
@@ -34,20 +70,28 @@ func resourceVariableCreate(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
-	key := d.Get("key").(string)
-	val := d.Get("value").(string)
+	key := variableKeyPrefix(d.Get("key_prefix").(string), m) + d.Get("key").(string)
 	varApi := client.VariableApi
 
-	_, _, err := varApi.PostVariables(pcfg.AuthContext).Variable(airflow.Variable{
-		Key:   &key,
-		Value: &val,
-	}).Execute()
-	if err != nil {
+	if isObserveOnly(d) {
+		return observeExisting(d, m, "variable", key, resourceVariableRead)
+	}
+
+	if err := checkSecretsBackend(d, pcfg, "variable", key); err != nil {
+		return err
+	}
+
+	variable := expandAirflowVariable(d, key)
+	_, resp, err := varApi.PostVariables(pcfg.AuthContext).Variable(variable).Execute()
+	if err != nil && !createdDespiteError(d, m, "variable", key, resp, err, resourceVariableRead) {
 		return fmt.Errorf("failed to create variable `%s` from Airflow: %w", key, err)
 	}
 	d.SetId(key)
+	if err := recordVariableValueDigest(d, m, variable); err != nil {
+		return err
+	}
 
-	return resourceVariableRead(d, m)
+	return readAfterCreate(d, m, "variable", resourceVariableRead)
 }
 
 func resourceVariableRead(d *schema.ResourceData, m interface{}) error {
@@ -60,11 +104,21 @@ func resourceVariableRead(d *schema.ResourceData, m interface{}) error {
 		return nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get variable `%s` from Airflow: %w", d.Id(), err)
+		return fmt.Errorf("failed to get variable `%s` from Airflow: %w", d.Id(), apiPermissionError(resp, err, "can_read on Variables"))
 	}
 
-	d.Set("key", variable.Key)
-	d.Set("value", variable.Value)
+	mode := effectiveSensitiveStateMode(m, variableSensitiveStateMode(d))
+
+	d.Set("key", strings.TrimPrefix(variable.GetKey(), variableKeyPrefix(d.Get("key_prefix").(string), m)))
+	d.Set("full_key", variable.Key)
+	if _, ok := d.GetOk("value_json"); ok {
+		d.Set("value_json", sensitiveStateValue(mode, normalizeVariableJson(variable.GetValue())))
+	} else {
+		d.Set("value", sensitiveStateValue(mode, variable.GetValue()))
+	}
+	d.Set("sensitive_state_mode", mode)
+	keepSensitiveStateDigests(d)
+	d.Set("ui_url", airflowUiUrl(m, "variable", d.Id()))
 
 	return nil
 }
@@ -73,15 +127,20 @@ func resourceVariableUpdate(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
-	val := d.Get("value").(string)
 	key := d.Id()
-	_, _, err := client.VariableApi.PatchVariable(pcfg.AuthContext, key).Variable(airflow.Variable{
-		Key:   &key,
-		Value: &val,
-	}).Execute()
+	if isObserveOnly(d) {
+		observeOnly(d, m, "variable", "updating")
+		return resourceVariableRead(d, m)
+	}
+
+	variable := expandAirflowVariable(d, key)
+	_, _, err := client.VariableApi.PatchVariable(pcfg.AuthContext, key).Variable(variable).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to update variable `%s` from Airflow: %w", key, err)
 	}
+	if err := recordVariableValueDigest(d, m, variable); err != nil {
+		return err
+	}
 
 	return resourceVariableRead(d, m)
 }
@@ -90,6 +149,15 @@ func resourceVariableDelete(d *schema.ResourceData, m interface{}) error {
 	pcfg := m.(ProviderConfig)
 	client := pcfg.ApiClient
 
+	if err := checkDeletionProtection(d, "variable"); err != nil {
+		return err
+	}
+
+	if isObserveOnly(d) {
+		observeOnly(d, m, "variable", "deleting")
+		return nil
+	}
+
 	resp, err := client.VariableApi.DeleteVariable(pcfg.AuthContext, d.Id()).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to delete variable `%s` from Airflow: %w", d.Id(), err)
@@ -101,3 +169,104 @@ func resourceVariableDelete(d *schema.ResourceData, m interface{}) error {
 
 	return nil
 }
+
+// variableKeyPrefix returns the prefix of the keys of a variable: the
+// key_prefix of the resource or, when unset, the variable_key_prefix of the
+// provider.
+func variableKeyPrefix(keyPrefix string, m interface{}) string {
+	if keyPrefix != "" {
+		return keyPrefix
+	}
+	return m.(ProviderConfig).VariableKeyPrefix
+}
+
+// customizeVariableFullKeyDiff plans full_key as the prefixed key and replaces
+// the variable when it changes. Changes of key and key_prefix that keep the
+// full key, e.g. after importing a prefixed variable, are made in place.
+func customizeVariableFullKeyDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("key") || !d.NewValueKnown("key_prefix") {
+		if err := d.SetNewComputed("full_key"); err != nil {
+			return err
+		}
+		if d.Id() == "" {
+			return nil
+		}
+		return d.ForceNew("full_key")
+	}
+
+	fullKey := variableKeyPrefix(d.Get("key_prefix").(string), m) + d.Get("key").(string)
+	if fullKey != d.Get("full_key").(string) {
+		if err := d.SetNew("full_key", fullKey); err != nil {
+			return err
+		}
+	}
+	if d.Id() == "" || fullKey == d.Id() {
+		return nil
+	}
+	return d.ForceNew("full_key")
+}
+
+func expandAirflowVariable(d *schema.ResourceData, key string) airflow.Variable {
+	val := configString(d, "value")
+	if v := configString(d, "value_json"); v != "" {
+		val = normalizeVariableJson(v)
+	}
+
+	return airflow.Variable{
+		Key:   &key,
+		Value: &val,
+	}
+}
+
+// recordVariableValueDigest records the digest of the value that was written
+// to Airflow under value or value_json, whichever is configured.
+func recordVariableValueDigest(d *schema.ResourceData, m interface{}, variable airflow.Variable) error {
+	key, other := "value", "value_json"
+	if configString(d, "value_json") != "" {
+		key, other = other, key
+	}
+	if err := recordSensitiveStateDigest(d, other, sensitiveStatePlain, ""); err != nil {
+		return err
+	}
+	return recordSensitiveStateDigest(d, key, effectiveSensitiveStateMode(m, variableSensitiveStateMode(d)), variable.GetValue())
+}
+
+// variableSensitiveStateMode returns the mode requested by the resource. When
+// the value isn't stored in state only its SHA-256 digest is kept, so drift
+// is still detected by comparing the digests.
+func variableSensitiveStateMode(d *schema.ResourceData) string {
+	if !d.Get("store_value_in_state").(bool) {
+		return sensitiveStateHash
+	}
+	return sensitiveStatePlain
+}
+
+// normalizeVariableJson returns a JSON value with sorted object keys and
+// without insignificant whitespace, so that semantically identical values
+// are stored the same way. Numbers are kept as written and values that
+// aren't JSON are returned as is.
+func normalizeVariableJson(value string) string {
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return value
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return value
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// suppressVariableValueJsonDiff suppresses the diff of value_json when the
+// configured value is semantically identical to the one in state, also when
+// only its digest is stored.
+func suppressVariableValueJsonDiff(k, oldo, newo string, d *schema.ResourceData) bool {
+	newo = normalizeVariableJson(newo)
+	return suppressSensitiveStateDiff(k, oldo, newo, d) || (oldo != "" && oldo == newo)
+}
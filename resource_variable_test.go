@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -73,3 +77,284 @@ resource "airflow_variable" "test" {
 }
 `, rName, value)
 }
+
+func TestResourceVariable_fakeCRUD(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{
+		"key":   "fake-var",
+		"value": "foo",
+	})
+
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	d.Set("value", "bar")
+	if err := resourceVariableUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := fake.object("variables", "fake-var")["value"]; got != "bar" {
+		t.Fatalf("expected value bar, got %v", got)
+	}
+
+	if err := resourceVariableDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("variables", "fake-var") != nil {
+		t.Fatal("variable still exists in Airflow")
+	}
+}
+
+func TestResourceVariable_fakeHashedValue(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{
+		"key":                  "fake-hashed",
+		"value":                "secret",
+		"store_value_in_state": false,
+	})
+
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := fake.object("variables", "fake-hashed")["value"]; got != "secret" {
+		t.Fatalf("expected the plain value to be sent to Airflow, got %v", got)
+	}
+	if got := d.Get("value").(string); got != sha256Hex("secret") {
+		t.Fatalf("expected the value digest in state, got %q", got)
+	}
+
+	if !suppressSensitiveStateDiff("value", sha256Hex("secret"), "secret", d) {
+		t.Fatal("expected no diff when the digest matches the configured value")
+	}
+	if suppressSensitiveStateDiff("value", sha256Hex("secret"), "changed", d) {
+		t.Fatal("expected a diff when the configured value changed")
+	}
+}
+
+// TestResourceVariable_fakeOmittedValue covers a value that isn't stored in
+// state: the digest of the value that was sent detects configuration
+// changes.
+func TestResourceVariable_fakeOmittedValue(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0, "sensitive_state_mode": sensitiveStateOmit})
+
+	raw := map[string]interface{}{
+		"key":   "fake-omitted",
+		"value": "secret",
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, raw)
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("value").(string); got != "" {
+		t.Fatalf("expected the value to be omitted from state, got %q", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceVariable(), d.State(), raw, m)
+	if d.HasChange("value") {
+		t.Fatal("expected no diff when the configured value didn't change")
+	}
+
+	raw["value"] = "changed"
+	d = testResourceDataUpdate(t, resourceVariable(), d.State(), raw, m)
+	if !d.HasChange("value") {
+		t.Fatal("expected a diff when the configured value changed")
+	}
+	if err := resourceVariableUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := fake.object("variables", "fake-omitted")["value"]; got != "changed" {
+		t.Fatalf("expected the changed value to be sent to Airflow, got %v", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceVariable(), d.State(), raw, m)
+	if d.HasChange("value") {
+		t.Fatal("expected no diff after the update")
+	}
+}
+
+func TestResourceVariable_fakeValueJson(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	raw := map[string]interface{}{
+		"key":        "fake-json",
+		"value_json": `{"b": 10000000000000001, "a": ["<x>", true]}`,
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, raw)
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	want := `{"a":["<x>",true],"b":10000000000000001}`
+	if got := fake.object("variables", "fake-json")["value"]; got != want {
+		t.Fatalf("expected the normalized value to be sent to Airflow, got %v", got)
+	}
+	if got := d.Get("value_json").(string); got != want {
+		t.Fatalf("expected the normalized value in state, got %q", got)
+	}
+	if got := d.Get("value").(string); got != "" {
+		t.Fatalf("expected no value in state, got %q", got)
+	}
+
+	raw["value_json"] = "{\n  \"a\": [\"<x>\", true],\n  \"b\": 10000000000000001\n}"
+	d = testResourceDataUpdate(t, resourceVariable(), d.State(), raw, m)
+	if d.HasChange("value_json") {
+		t.Fatal("expected no diff for a reformatted value")
+	}
+
+	fake.object("variables", "fake-json")["value"] = `{"a":[],"b":1}`
+	if err := resourceVariableRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	d = testResourceDataUpdate(t, resourceVariable(), d.State(), raw, m)
+	if !d.HasChange("value_json") {
+		t.Fatal("expected a diff for a changed value")
+	}
+}
+
+func TestResourceVariable_fakeKeyPrefix(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	m.VariableKeyPrefix = "team_a__"
+
+	raw := map[string]interface{}{
+		"key":   "fake-var",
+		"value": "foo",
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, raw)
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if fake.object("variables", "team_a__fake-var") == nil {
+		t.Fatal("expected the variable to be created with the provider prefix")
+	}
+	if d.Id() != "team_a__fake-var" || d.Get("key").(string) != "fake-var" || d.Get("full_key").(string) != "team_a__fake-var" {
+		t.Fatalf("unexpected id %q, key %q and full_key %q", d.Id(), d.Get("key"), d.Get("full_key"))
+	}
+
+	// A variable imported by its full key keeps it with a resource prefix.
+	fake.seed("variables", map[string]interface{}{"key": "team_b__imported", "value": "foo"})
+	imported := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{})
+	imported.SetId("team_b__imported")
+	if err := resourceVariableRead(imported, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	raw = map[string]interface{}{
+		"key":        "imported",
+		"key_prefix": "team_b__",
+		"value":      "foo",
+	}
+	imported = testResourceDataUpdate(t, resourceVariable(), imported.State(), raw, m)
+	if imported.HasChange("full_key") {
+		t.Fatal("expected the imported variable to keep its full key")
+	}
+
+	raw["key_prefix"] = "team_c__"
+	diff, err := resourceVariable().Diff(context.Background(), imported.State(), terraform.NewResourceConfigRaw(raw), m)
+	if err != nil {
+		t.Fatalf("diff: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Fatal("expected a new prefix to replace the variable")
+	}
+}
+
+func TestResourceVariable_fakeObserveOnly(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	raw := map[string]interface{}{
+		"key":    "fake-observed",
+		"value":  "configured",
+		"manage": false,
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, raw)
+	if err := resourceVariableCreate(d, m); err == nil {
+		t.Fatal("expected an error for a missing observed variable")
+	}
+	if fake.object("variables", "fake-observed") != nil {
+		t.Fatal("expected the observed variable not to be created")
+	}
+
+	fake.seed("variables", map[string]interface{}{"key": "fake-observed", "value": "manual"})
+	d = schema.TestResourceDataRaw(t, resourceVariable().Schema, raw)
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("value").(string); got != "manual" {
+		t.Fatalf("expected the value of Airflow in state, got %q", got)
+	}
+
+	// The skipped update is reported as a warning of the apply.
+	r := resourceVariable()
+	wrapResourceOperations("airflow_variable", r)
+	diags := r.UpdateContext(context.Background(), d, m)
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a warning for the skipped update, got %v", diags)
+	}
+	if err := resourceVariableDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if got := fake.object("variables", "fake-observed")["value"]; got != "manual" {
+		t.Fatalf("expected the observed variable to be left alone, got %v", got)
+	}
+	if n := fake.requestCount(http.MethodPatch, "/variables/fake-observed") + fake.requestCount(http.MethodDelete, "/variables/fake-observed"); n != 0 {
+		t.Fatalf("expected no writes, got %d", n)
+	}
+}
+
+func TestResourceVariable_fakeCreateRecovery(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	// The proxy times out, but Airflow created the variable.
+	fake.seed("variables", map[string]interface{}{"key": "created", "value": "bar"})
+	fake.failNext(http.MethodPost, "/variables", http.StatusGatewayTimeout, 1)
+
+	d := schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{
+		"key":   "created",
+		"value": "bar",
+	})
+	if err := resourceVariableCreate(d, m); err != nil {
+		t.Fatalf("expected the created variable to be adopted, got %s", err)
+	}
+	if d.Id() != "created" {
+		t.Fatalf("expected ID created, got %q", d.Id())
+	}
+
+	// The proxy times out before Airflow created the variable.
+	fake.failNext(http.MethodPost, "/variables", http.StatusGatewayTimeout, 1)
+	d = schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{
+		"key":   "missing",
+		"value": "bar",
+	})
+	if err := resourceVariableCreate(d, m); err == nil {
+		t.Fatal("expected create to fail")
+	}
+	if d.Id() != "" {
+		t.Fatalf("expected no ID, got %q", d.Id())
+	}
+
+	// Reading the created variable back fails.
+	fake.failNext(http.MethodGet, "/variables/readback", http.StatusBadGateway, 1)
+	d = schema.TestResourceDataRaw(t, resourceVariable().Schema, map[string]interface{}{
+		"key":   "readback",
+		"value": "bar",
+	})
+	if err := resourceVariableCreate(d, m); err == nil {
+		t.Fatal("expected create to fail")
+	}
+	if d.Id() != "readback" {
+		t.Fatalf("expected the ID to be kept, got %q", d.Id())
+	}
+	if got := fake.requestCount(http.MethodPost, "/variables"); got != 3 {
+		t.Fatalf("expected 3 creates, got %d", got)
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceVariables manages many variables in a single resource, e.g. the
+// settings of a tenant. Airflow doesn't list variable values, so they are
+// read one by one with bulk_parallelism concurrent calls.
+func resourceVariables() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceVariablesCreate,
+		Read:   resourceVariablesRead,
+		Update: resourceVariablesUpdate,
+		Delete: resourceVariablesDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceVariablesImport,
+		},
+		CustomizeDiff: customizeVariablesPendingChangesDiff,
+		Schema: map[string]*schema.Schema{
+			"variable": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": {
+							Type:             schema.TypeString,
+							Required:         true,
+							Sensitive:        true,
+							DiffSuppressFunc: suppressSensitiveStateListDiff("key"),
+						},
+					},
+				},
+			},
+			"key_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"adopt_existing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+			"authoritative_prefix":    authoritativePrefixSchema(),
+			"unmanaged":               unmanagedSchema(),
+			"pending_changes":         pendingChangesSchema(),
+			"sensitive_state_mode":    sensitiveStateModeSchema(),
+			"sensitive_state_digests": sensitiveStateDigestsSchema(),
+		},
+	}
+}
+
+func resourceVariablesCreate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	variables, err := expandAirflowVariables(d.Get("variable").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	prefix := variableKeyPrefix(d.Get("key_prefix").(string), m)
+	adopt := d.Get("adopt_existing").(bool)
+	d.SetId(resource.UniqueId())
+	calls := make([]func() error, 0, len(variables))
+	for _, variable := range variables {
+		key, value := prefix+variable.GetKey(), variable.GetValue()
+		calls = append(calls, func() error {
+			_, resp, err := client.VariableApi.PostVariables(pcfg.AuthContext).Variable(airflow.Variable{Key: &key, Value: &value}).Execute()
+			if err == nil {
+				return nil
+			}
+			if !adopt || resp == nil || resp.StatusCode != http.StatusConflict {
+				return fmt.Errorf("failed to create variable `%s` from Airflow: %w", key, err)
+			}
+
+			// The variable already exists, e.g. of an airflow_variable
+			// resource that was removed from state, and is adopted.
+			log.Printf("[INFO] Variable `%s` already exists, adopting it", key)
+			if _, _, err := client.VariableApi.PatchVariable(pcfg.AuthContext, key).Variable(airflow.Variable{Key: &key, Value: &value}).Execute(); err != nil {
+				return fmt.Errorf("failed to update variable `%s` from Airflow: %w", key, err)
+			}
+			return nil
+		})
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+	if err := recordVariablesDigests(d, m, variables); err != nil {
+		return err
+	}
+
+	// Unmanaged variables are only found by this read and deleted by the
+	// following apply, after the plan showed them.
+	return resourceVariablesRead(d, m)
+}
+
+func resourceVariablesRead(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	prefix := variableKeyPrefix(d.Get("key_prefix").(string), m)
+
+	var mu sync.Mutex
+	remote := map[string]string{}
+	var calls []func() error
+	for _, v := range d.Get("variable").([]interface{}) {
+		key := prefix + v.(map[string]interface{})["key"].(string)
+		calls = append(calls, func() error {
+			variable, resp, err := client.VariableApi.GetVariable(pcfg.AuthContext, key).Execute()
+			if resp != nil && resp.StatusCode == 404 {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get variable `%s` from Airflow: %w", key, apiPermissionError(resp, err, "can_read on Variables"))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			remote[key] = variable.GetValue()
+			return nil
+		})
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+
+	// Keep the order of the state so the list doesn't show a diff. Variables
+	// that were removed outside of Terraform are dropped to be recreated.
+	mode := effectiveSensitiveStateMode(m, sensitiveStatePlain)
+	var variables []interface{}
+	managed := map[string]bool{}
+	for _, v := range d.Get("variable").([]interface{}) {
+		key := v.(map[string]interface{})["key"].(string)
+		managed[key] = true
+		value, exists := remote[prefix+key]
+		if !exists {
+			log.Printf("[WARN] Variable `%s` not found in Airflow, removing it from state", prefix+key)
+			continue
+		}
+
+		variables = append(variables, map[string]interface{}{
+			"key":   key,
+			"value": sensitiveStateValue(mode, value),
+		})
+	}
+
+	if err := d.Set("variable", variables); err != nil {
+		return fmt.Errorf("error setting variable: %w", err)
+	}
+	d.Set("sensitive_state_mode", mode)
+	keepSensitiveStateDigests(d)
+
+	// Only the keys of the variables can be listed, so they are only listed
+	// when looking for unmanaged ones. Keys outside of the key_prefix can't
+	// be configured here and are left alone.
+	unmanaged := []string{}
+	if authoritative := d.Get("authoritative_prefix").(string); authoritative != "" {
+		err := listAirflowVariableKeys(pcfg, func(key string) bool {
+			if strings.HasPrefix(key, prefix) && isUnmanaged(authoritative, key, managed[strings.TrimPrefix(key, prefix)]) {
+				unmanaged = append(unmanaged, strings.TrimPrefix(key, prefix))
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	setUnmanaged(d, unmanaged)
+	d.Set("pending_changes", []interface{}{})
+
+	return nil
+}
+
+// listAirflowVariableKeys calls fn for the key of every variable of Airflow.
+func listAirflowVariableKeys(pcfg ProviderConfig, fn func(key string) bool) error {
+	key := func(v airflow.VariableCollectionItem) string { return v.GetKey() }
+	return forEachPage("variables", key, func(limit, offset int32) ([]airflow.VariableCollectionItem, int32, error) {
+		page, resp, err := pcfg.ApiClient.VariableApi.GetVariables(pcfg.AuthContext).Limit(limit).Offset(offset).Execute()
+		return page.GetVariables(), page.GetTotalEntries(), apiPermissionError(resp, err, "can_read on Variables")
+	}, func(v airflow.VariableCollectionItem) bool {
+		return fn(v.GetKey())
+	})
+}
+
+func resourceVariablesUpdate(d *schema.ResourceData, m interface{}) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	o, n := d.GetChange("variable")
+	oldVariables, err := expandAirflowVariables(o.([]interface{}))
+	if err != nil {
+		return err
+	}
+	newVariables, err := expandAirflowVariables(n.([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	// The new value of a suppressed value is its state representation, so
+	// the configured values are compared by that representation and sent as
+	// configured.
+	mode := d.Get("sensitive_state_mode").(string)
+	values := configuredVariableValues(d)
+	oldByKey := make(map[string]string, len(oldVariables))
+	for _, variable := range oldVariables {
+		oldByKey[variable.GetKey()] = variable.GetValue()
+	}
+
+	prefix := variableKeyPrefix(d.Get("key_prefix").(string), m)
+	var calls []func() error
+	for i, variable := range newVariables {
+		key, value := prefix+variable.GetKey(), variable.GetValue()
+		if configured, ok := values[variable.GetKey()]; ok {
+			value = configured
+			newVariables[i].SetValue(value)
+		}
+		old, exists := oldByKey[variable.GetKey()]
+		delete(oldByKey, variable.GetKey())
+
+		if !exists {
+			calls = append(calls, func() error {
+				if _, _, err := client.VariableApi.PostVariables(pcfg.AuthContext).Variable(airflow.Variable{Key: &key, Value: &value}).Execute(); err != nil {
+					return fmt.Errorf("failed to create variable `%s` from Airflow: %w", key, err)
+				}
+				return nil
+			})
+			continue
+		}
+
+		if sensitiveStateUnchanged(d, variable.GetKey(), mode, old, value) {
+			continue
+		}
+
+		calls = append(calls, func() error {
+			if _, _, err := client.VariableApi.PatchVariable(pcfg.AuthContext, key).Variable(airflow.Variable{Key: &key, Value: &value}).Execute(); err != nil {
+				return fmt.Errorf("failed to update variable `%s` from Airflow: %w", key, err)
+			}
+			return nil
+		})
+	}
+
+	for key := range oldByKey {
+		key := prefix + key
+		calls = append(calls, func() error { return deleteAirflowVariable(m, key) })
+	}
+	if err := runConcurrently(m, calls); err != nil {
+		return err
+	}
+	if err := recordVariablesDigests(d, m, newVariables); err != nil {
+		return err
+	}
+
+	if err := deleteUnmanaged(d, m, "variable", "key", func(key string) error { return deleteAirflowVariable(m, prefix+key) }); err != nil {
+		return err
+	}
+
+	return resourceVariablesRead(d, m)
+}
+
+func resourceVariablesDelete(d *schema.ResourceData, m interface{}) error {
+	prefix := variableKeyPrefix(d.Get("key_prefix").(string), m)
+
+	var calls []func() error
+	for _, v := range d.Get("variable").([]interface{}) {
+		key := prefix + v.(map[string]interface{})["key"].(string)
+		calls = append(calls, func() error { return deleteAirflowVariable(m, key) })
+	}
+
+	return runConcurrently(m, calls)
+}
+
+// resourceVariablesImport imports the variables with the comma separated
+// keys of the ID, e.g. to move variables of airflow_variable resources into
+// a single airflow_variables resource without recreating them. The keys
+// don't include the prefix, which is given before a colon, e.g.
+// `tenant_:a,b`, and defaults to the variable_key_prefix of the provider.
+func resourceVariablesImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	keys := d.Id()
+	if prefix, rest, ok := strings.Cut(keys, ":"); ok {
+		keys = rest
+		d.Set("key_prefix", prefix)
+	}
+
+	var variables []interface{}
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			variables = append(variables, map[string]interface{}{"key": key, "value": ""})
+		}
+	}
+	if len(variables) == 0 {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected KEY,KEY,... or KEY-PREFIX:KEY,KEY,...", d.Id())
+	}
+
+	d.SetId(resource.UniqueId())
+	if err := d.Set("variable", variables); err != nil {
+		return nil, fmt.Errorf("error setting variable: %w", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// recordVariablesDigests records the digests of the values of the variables
+// that were written to Airflow, by key.
+func recordVariablesDigests(d *schema.ResourceData, m interface{}, variables []airflow.Variable) error {
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		values[variable.GetKey()] = variable.GetValue()
+	}
+	return recordSensitiveStateDigests(d, effectiveSensitiveStateMode(m, sensitiveStatePlain), values)
+}
+
+// configuredVariableValues returns the configured values of the variables by
+// key. They are read from the raw configuration because a suppressed diff
+// leaves a value with its state value, which may be a digest or empty.
+func configuredVariableValues(d configReader) map[string]string {
+	values := map[string]string{}
+
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute("variable") {
+		for _, v := range d.Get("variable").([]interface{}) {
+			tfMap := v.(map[string]interface{})
+			values[tfMap["key"].(string)] = tfMap["value"].(string)
+		}
+		return values
+	}
+
+	variables := config.GetAttr("variable")
+	if variables.IsNull() || !variables.IsKnown() {
+		return values
+	}
+	for it := variables.ElementIterator(); it.Next(); {
+		_, variable := it.Element()
+		if variable.IsNull() || !variable.IsKnown() {
+			continue
+		}
+		key, value := variable.GetAttr("key"), variable.GetAttr("value")
+		if key.IsNull() || !key.IsKnown() || value.IsNull() || !value.IsKnown() {
+			continue
+		}
+		values[key.AsString()] = value.AsString()
+	}
+
+	return values
+}
+
+func deleteAirflowVariable(m interface{}, key string) error {
+	pcfg := m.(ProviderConfig)
+	client := pcfg.ApiClient
+
+	resp, err := client.VariableApi.DeleteVariable(pcfg.AuthContext, key).Execute()
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return fmt.Errorf("failed to delete variable `%s` from Airflow: %w", key, err)
+	}
+	return nil
+}
+
+// customizeVariablesPendingChangesDiff plans pending_changes like the update
+// compares the variables: the configured values against their representation
+// in state, by key.
+func customizeVariablesPendingChangesDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	mode := d.Get("sensitive_state_mode").(string)
+	values := configuredVariableValues(d)
+
+	return customizePendingChangesDiff("variable", "key", expandAirflowVariables,
+		func(v airflow.Variable) string { return v.GetKey() },
+		func(old, variable airflow.Variable) bool {
+			value, ok := values[variable.GetKey()]
+			if !ok {
+				value = variable.GetValue()
+			}
+			return sensitiveStateUnchanged(d, variable.GetKey(), mode, old.GetValue(), value)
+		})(ctx, d, m)
+}
+
+func expandAirflowVariables(tfList []interface{}) ([]airflow.Variable, error) {
+	apiObjects := make([]airflow.Variable, 0, len(tfList))
+	seen := make(map[string]bool, len(tfList))
+
+	for _, v := range tfList {
+		tfMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		key := tfMap["key"].(string)
+		if seen[key] {
+			return nil, fmt.Errorf("variable `%s` is defined more than once", key)
+		}
+		seen[key] = true
+
+		value := tfMap["value"].(string)
+		apiObjects = append(apiObjects, airflow.Variable{
+			Key:   &key,
+			Value: &value,
+		})
+	}
+
+	return apiObjects, nil
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceVariables_fakeReconcile(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+
+	variable := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": value}
+	}
+
+	raw := map[string]interface{}{
+		"key_prefix": "tenant_",
+		"variable":   []interface{}{variable("a", "1"), variable("b", "2")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := fake.object("variables", "tenant_a")["value"]; got != "1" {
+		t.Fatalf("expected tenant_a to be created, got %v", got)
+	}
+
+	// Drop a, change b and add c.
+	raw["variable"] = []interface{}{variable("b", "20"), variable("c", "3")}
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	if err := resourceVariablesUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if fake.object("variables", "tenant_a") != nil {
+		t.Fatal("expected tenant_a to be deleted")
+	}
+	if got := fake.object("variables", "tenant_b")["value"]; got != "20" {
+		t.Fatalf("expected tenant_b to be updated, got %v", got)
+	}
+	if fake.object("variables", "tenant_c") == nil {
+		t.Fatal("expected tenant_c to be created")
+	}
+
+	if err := resourceVariablesDelete(d, m); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if fake.object("variables", "tenant_b") != nil || fake.object("variables", "tenant_c") != nil {
+		t.Fatal("variables still exist in Airflow")
+	}
+}
+
+// TestResourceVariables_fakeImport covers moving variables of airflow_variable
+// resources into an airflow_variables resource: importing them doesn't plan
+// any change to the variables.
+func TestResourceVariables_fakeImport(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0, "sensitive_state_mode": sensitiveStateHash})
+	fake.seed("variables", map[string]interface{}{"key": "a", "value": "1"})
+	fake.seed("variables", map[string]interface{}{"key": "b", "value": "2"})
+
+	d := resourceVariables().Data(nil)
+	d.SetId("a, b")
+	imported, err := resourceVariablesImport(context.Background(), d, m)
+	if err != nil {
+		t.Fatalf("import: %s", err)
+	}
+	d = imported[0]
+	if err := resourceVariablesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("variable.1.value").(string); got != sha256Hex("2") {
+		t.Fatalf("expected the digest of the value in state, got %q", got)
+	}
+
+	raw := map[string]interface{}{
+		"variable": []interface{}{
+			map[string]interface{}{"key": "a", "value": "1"},
+			map[string]interface{}{"key": "b", "value": "2"},
+		},
+	}
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	if d.HasChange("variable") {
+		t.Fatalf("expected no changes after the import, got %v", d.Get("variable"))
+	}
+
+	if _, err := resourceVariablesImport(context.Background(), resourceVariables().Data(nil), m); err == nil {
+		t.Fatal("expected an error for an import without keys")
+	}
+}
+
+// TestResourceVariables_fakeImportKeyPrefix covers importing variables with a
+// key_prefix, which must not plan a replacement of the imported resource.
+func TestResourceVariables_fakeImportKeyPrefix(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0})
+	fake.seed("variables", map[string]interface{}{"key": "tenant_a", "value": "1"})
+
+	d := resourceVariables().Data(nil)
+	d.SetId("tenant_:a")
+	imported, err := resourceVariablesImport(context.Background(), d, m)
+	if err != nil {
+		t.Fatalf("import: %s", err)
+	}
+	d = imported[0]
+	if err := resourceVariablesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if got := d.Get("variable.#").(int); got != 1 {
+		t.Fatalf("expected the prefixed variable to be read, got %v", d.Get("variable"))
+	}
+
+	raw := map[string]interface{}{
+		"key_prefix": "tenant_",
+		"variable":   []interface{}{map[string]interface{}{"key": "a", "value": "1"}},
+	}
+	diff, err := resourceVariables().Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(raw), m)
+	if err != nil {
+		t.Fatalf("diff: %s", err)
+	}
+	if diff != nil && !diff.Empty() {
+		t.Fatalf("expected no changes after the import, got %v", diff.Attributes)
+	}
+}
+
+// TestResourceVariables_fakeSensitiveStateMode covers an update in hash mode
+// that changes one variable: the unchanged one keeps its value in Airflow
+// instead of getting its digest from state.
+func TestResourceVariables_fakeSensitiveStateMode(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0, "sensitive_state_mode": sensitiveStateHash})
+
+	var patched []string
+	fake.handle(http.MethodPatch, "/variables/b", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		patched = append(patched, fmt.Sprint(body["value"]))
+		fake.seed("variables", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	variable := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": value}
+	}
+
+	raw := map[string]interface{}{
+		"variable": []interface{}{variable("a", "1"), variable("b", "2")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("variable.0.value").(string); got != sha256Hex("1") {
+		t.Fatalf("expected the digest of the value in state, got %q", got)
+	}
+
+	raw["variable"] = []interface{}{variable("a", "1"), variable("b", "20")}
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	if err := resourceVariablesUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if len(patched) != 1 || patched[0] != "20" {
+		t.Fatalf("expected only b to be sent as configured, got %v", patched)
+	}
+	if got := fake.object("variables", "a")["value"]; got != "1" {
+		t.Fatalf("expected a to keep its value, got %v", got)
+	}
+	if got := d.Get("variable.1.value").(string); got != sha256Hex("20") {
+		t.Fatalf("expected the digest of the new value in state, got %q", got)
+	}
+}
+
+// TestResourceVariables_fakeAdoptExisting covers moving variables of
+// airflow_variable resources that were removed from state into an
+// airflow_variables resource without an import.
+func TestResourceVariables_fakeAdoptExisting(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0})
+	fake.seed("variables", map[string]interface{}{"key": "a", "value": "1"})
+
+	raw := map[string]interface{}{
+		"variable": []interface{}{
+			map[string]interface{}{"key": "a", "value": "10"},
+			map[string]interface{}{"key": "b", "value": "2"},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err == nil {
+		t.Fatal("expected the create of an existing variable to fail without adopt_existing")
+	}
+
+	raw["adopt_existing"] = true
+	d = schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := fake.object("variables", "a")["value"]; got != "10" {
+		t.Fatalf("expected a to be adopted and updated, got %v", got)
+	}
+	if got := d.Get("variable.#").(int); got != 2 {
+		t.Fatalf("expected both variables in state, got %v", d.Get("variable"))
+	}
+}
+
+// TestResourceVariables_fakeOmittedValues covers values that aren't stored in
+// state: the digests of the values that were sent detect changes.
+func TestResourceVariables_fakeOmittedValues(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"max_retries": 0, "sensitive_state_mode": sensitiveStateOmit})
+
+	var patched []string
+	fake.handle(http.MethodPatch, "/variables/b", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		patched = append(patched, fmt.Sprint(body["value"]))
+		fake.seed("variables", body)
+		writeFakeAirflowJSON(w, http.StatusOK, body)
+	})
+
+	variable := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": value}
+	}
+
+	raw := map[string]interface{}{
+		"variable": []interface{}{variable("a", "1"), variable("b", "2")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+	if got := d.Get("variable.1.value").(string); got != "" {
+		t.Fatalf("expected the value to be omitted from state, got %q", got)
+	}
+
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	if d.HasChange("variable") {
+		t.Fatalf("expected no changes, got %v", d.Get("variable"))
+	}
+
+	raw["variable"] = []interface{}{variable("a", "1"), variable("b", "20")}
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	if !d.HasChange("variable") {
+		t.Fatal("expected a change of b")
+	}
+	if err := resourceVariablesUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if len(patched) != 1 || patched[0] != "20" {
+		t.Fatalf("expected only b to be sent, got %v", patched)
+	}
+	if got := fake.object("variables", "a")["value"]; got != "1" {
+		t.Fatalf("expected a to keep its value, got %v", got)
+	}
+}
+
+func TestResourceVariables_fakeAuthoritativePrefix(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfig(t)
+	fake.seed("variables", map[string]interface{}{"key": "tenant_a_old", "value": "1"})
+	fake.seed("variables", map[string]interface{}{"key": "tenant_b_setting", "value": "1"})
+
+	raw := map[string]interface{}{
+		"key_prefix":           "tenant_",
+		"authoritative_prefix": "tenant_a_",
+		"variable": []interface{}{
+			map[string]interface{}{"key": "a_setting", "value": "2"},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// The first apply deletes nothing, as no plan showed the deletion yet.
+	if fake.object("variables", "tenant_a_old") == nil {
+		t.Fatal("expected the unmanaged variable to be kept by the create")
+	}
+	if got := d.Get("unmanaged").([]interface{}); !reflect.DeepEqual(got, []interface{}{"a_old"}) {
+		t.Fatalf("unexpected unmanaged variables: %v", got)
+	}
+
+	// A variable created outside of Terraform is found on refresh and
+	// deleted by the next apply, one appearing after the plan is kept.
+	fake.seed("variables", map[string]interface{}{"key": "tenant_a_stray", "value": "1"})
+	if err := resourceVariablesRead(d, m); err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	expected := []interface{}{map[string]interface{}{
+		"create": []interface{}{},
+		"update": []interface{}{},
+		"delete": []interface{}{"a_old", "a_stray"},
+	}}
+	if got := d.Get("pending_changes"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected pending_changes: %v", got)
+	}
+	fake.seed("variables", map[string]interface{}{"key": "tenant_a_late", "value": "1"})
+	if err := resourceVariablesUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if fake.object("variables", "tenant_a_old") != nil || fake.object("variables", "tenant_a_stray") != nil {
+		t.Fatal("expected the unmanaged variables to be deleted")
+	}
+	if fake.object("variables", "tenant_a_late") == nil {
+		t.Fatal("expected the variable missing from the plan to be kept")
+	}
+	if fake.object("variables", "tenant_b_setting") == nil {
+		t.Fatal("expected the variable of another tenant to be kept")
+	}
+	if fake.object("variables", "tenant_a_setting") == nil {
+		t.Fatal("expected the configured variable to be kept")
+	}
+	if got := d.Get("unmanaged").([]interface{}); !reflect.DeepEqual(got, []interface{}{"a_late"}) {
+		t.Fatalf("unexpected unmanaged variables: %v", got)
+	}
+}
+
+func TestResourceVariables_fakePendingChanges(t *testing.T) {
+	fake := newFakeAirflow(t)
+	m := fake.providerConfigWith(t, map[string]interface{}{"sensitive_state_mode": "hash"})
+
+	variable := func(key, value string) map[string]interface{} {
+		return map[string]interface{}{"key": key, "value": value}
+	}
+
+	raw := map[string]interface{}{
+		"key_prefix": "tenant_",
+		"variable":   []interface{}{variable("a", "1"), variable("b", "2"), variable("c", "3")},
+	}
+	d := schema.TestResourceDataRaw(t, resourceVariables().Schema, raw)
+	if err := resourceVariablesCreate(d, m); err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// The values are hashed in state, so an unchanged value isn't planned
+	// as an update.
+	raw["variable"] = []interface{}{variable("b", "20"), variable("c", "3"), variable("d", "4")}
+	d = testResourceDataUpdate(t, resourceVariables(), d.State(), raw, m)
+	expected := []interface{}{map[string]interface{}{
+		"create": []interface{}{"d"},
+		"update": []interface{}{"b"},
+		"delete": []interface{}{"a"},
+	}}
+	if got := d.Get("pending_changes"); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected pending_changes: %v", got)
+	}
+
+	if err := resourceVariablesUpdate(d, m); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if got := d.Get("pending_changes.#"); got != 0 {
+		t.Fatalf("expected no pending_changes in state, got %v", d.Get("pending_changes"))
+	}
+	if got := fake.object("variables", "tenant_b")["value"]; got != "20" {
+		t.Fatalf("expected tenant_b to be updated, got %v", got)
+	}
+}
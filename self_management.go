@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apache/airflow-client-go/airflow"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// providerUsername returns the username the provider authenticates as with
+// basic auth, or "" with a token, whose user isn't known.
+func providerUsername(m interface{}) string {
+	pcfg, ok := m.(ProviderConfig)
+	if !ok || pcfg.AuthContext == nil {
+		return ""
+	}
+	if auth, ok := pcfg.AuthContext.Value(airflow.ContextBasicAuth).(airflow.BasicAuth); ok {
+		return auth.UserName
+	}
+	return ""
+}
+
+// isProviderUser returns whether an airflow_user is the account the provider
+// authenticates as, which Terraform could lock itself out of. Airflow looks
+// usernames up case-insensitively on most databases, so they are compared
+// the same way.
+func isProviderUser(d *schema.ResourceData, m interface{}) bool {
+	self := providerUsername(m)
+	return self != "" && strings.EqualFold(d.Get("username").(string), self)
+}
+
+// checkSelfManagement refuses deleting the account the provider authenticates
+// as, including replacing it, unless allow_self_management is set.
+func checkSelfManagement(d *schema.ResourceData, m interface{}) error {
+	if isProviderUser(d, m) && !d.Get("allow_self_management").(bool) {
+		return fmt.Errorf("cannot delete user `%s`, the provider authenticates as it and would lock itself out, set allow_self_management to true and apply before destroying or replacing it", d.Get("username").(string))
+	}
+	return nil
+}
+
+// wrapSelfManagementWarning adds a warning to the refresh of an airflow_user
+// that is the account the provider authenticates as, as changing its roles,
+// password or active flag can lock out the rest of the apply. It must be
+// applied after wrapRefreshSkipping, which sets ReadContext.
+func wrapSelfManagementWarning(r *schema.Resource) {
+	read := r.ReadContext
+	if read == nil {
+		return
+	}
+
+	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		diags := read(ctx, d, m)
+		if diags.HasError() || d.Id() == "" || !isProviderUser(d, m) || d.Get("allow_self_management").(bool) {
+			return diags
+		}
+
+		return append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("The provider authenticates as the managed user `%s`", d.Get("username").(string)),
+			Detail:   "Changing the roles or password of this user can lock the provider out of Airflow in the middle of an apply, and destroying it is refused. Set allow_self_management to true to acknowledge this.",
+		})
+	}
+}
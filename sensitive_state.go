@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Modes controlling how secret-bearing attributes are stored in state.
+const (
+	sensitiveStatePlain = "plain"
+	sensitiveStateHash  = "hash"
+	sensitiveStateOmit  = "omit"
+)
+
+var sensitiveStateModes = []string{sensitiveStatePlain, sensitiveStateHash, sensitiveStateOmit}
+
+// sensitiveStateModeSchema is the computed attribute recording the mode a
+// resource's secrets were stored with, so diffs can be compared accordingly.
+func sensitiveStateModeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+}
+
+// sensitiveStateDigestsSchema is the computed attribute recording the digests
+// of the secrets last written in omit mode, by attribute, so that changes to
+// their configuration still show up in plans.
+func sensitiveStateDigestsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// setSensitiveStateValue stores a secret value in state for the given mode,
+// recording its digest in omit mode.
+func setSensitiveStateValue(d *schema.ResourceData, key, mode, value string) error {
+	if err := recordSensitiveStateDigest(d, key, mode, value); err != nil {
+		return err
+	}
+	return d.Set(key, sensitiveStateValue(mode, value))
+}
+
+// recordSensitiveStateDigest records the digest of a secret value that was
+// written to Airflow in sensitive_state_digests in omit mode, and clears it
+// for the other modes, which compare the value in state itself.
+func recordSensitiveStateDigest(d *schema.ResourceData, key, mode, value string) error {
+	digests := map[string]interface{}{}
+	for k, v := range d.Get("sensitive_state_digests").(map[string]interface{}) {
+		digests[k] = v
+	}
+	delete(digests, key)
+	if mode == sensitiveStateOmit {
+		digests[key] = sha256Hex(value)
+	}
+	return d.Set("sensitive_state_digests", digests)
+}
+
+// recordSensitiveStateDigests records the digests of the secrets of the
+// elements of a bulk resource that were written to Airflow, by the ID of
+// their element, replacing the digests of previous writes.
+func recordSensitiveStateDigests(d *schema.ResourceData, mode string, values map[string]string) error {
+	digests := map[string]interface{}{}
+	if mode == sensitiveStateOmit {
+		for id, value := range values {
+			digests[id] = sha256Hex(value)
+		}
+	}
+	return d.Set("sensitive_state_digests", digests)
+}
+
+// keepSensitiveStateDigests stores the recorded digests in state as they are,
+// so that resources that were only read, e.g. by an import, don't plan them
+// as unknown.
+func keepSensitiveStateDigests(d *schema.ResourceData) {
+	d.Set("sensitive_state_digests", d.Get("sensitive_state_digests"))
+}
+
+// sensitiveStateDigest returns the digest recorded for a secret in omit mode.
+func sensitiveStateDigest(d configReader, key string) (string, bool) {
+	digests, _ := d.Get("sensitive_state_digests").(map[string]interface{})
+	digest, ok := digests[key].(string)
+	return digest, ok
+}
+
+// sensitiveStateUnchanged reports whether a configured secret matches the one
+// last written to Airflow, by its representation in state or, in omit mode,
+// by the digest recorded for it. Omitted secrets without a digest, e.g. of
+// imported resources, don't match, so that they are written once.
+func sensitiveStateUnchanged(d configReader, digestKey, mode, state, value string) bool {
+	if mode == sensitiveStateOmit {
+		digest, ok := sensitiveStateDigest(d, digestKey)
+		return ok && digest == sha256Hex(value)
+	}
+	return state == sensitiveStateValue(mode, value)
+}
+
+// effectiveSensitiveStateMode returns the strictest of the provider-wide mode
+// and the mode requested by the resource itself.
+func effectiveSensitiveStateMode(m interface{}, resourceMode string) string {
+	mode := sensitiveStatePlain
+	if pcfg, ok := m.(ProviderConfig); ok && pcfg.SensitiveStateMode != "" {
+		mode = pcfg.SensitiveStateMode
+	}
+
+	if sensitiveStateStrictness(resourceMode) > sensitiveStateStrictness(mode) {
+		return resourceMode
+	}
+	return mode
+}
+
+func sensitiveStateStrictness(mode string) int {
+	switch mode {
+	case sensitiveStateHash:
+		return 1
+	case sensitiveStateOmit:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// sensitiveStateValue returns the representation of a secret value that is
+// stored in state for the given mode.
+func sensitiveStateValue(mode, value string) string {
+	switch mode {
+	case sensitiveStateHash:
+		return sha256Hex(value)
+	case sensitiveStateOmit:
+		return ""
+	default:
+		return value
+	}
+}
+
+// sha256Hex returns the hex encoded SHA-256 digest of s. It is stored in
+// state instead of secret values when they must not be persisted.
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// suppressSensitiveStateDiff suppresses the diff of a secret-bearing
+// attribute of an existing resource when its state representation matches
+// the configured value. Hashed values are compared by digest, and omitted
+// values by the digest recorded in sensitive_state_digests.
+func suppressSensitiveStateDiff(k, oldo, newo string, d *schema.ResourceData) bool {
+	if d.Id() == "" {
+		return false
+	}
+	return sensitiveStateUnchanged(d, k, d.Get("sensitive_state_mode").(string), oldo, newo)
+}
+
+// suppressSensitiveStateListDiff is suppressSensitiveStateDiff for the secret
+// of the elements of a bulk resource, whose digests are recorded by the
+// idAttr of their element.
+func suppressSensitiveStateListDiff(idAttr string) schema.SchemaDiffSuppressFunc {
+	return func(k, oldo, newo string, d *schema.ResourceData) bool {
+		if d.Id() == "" {
+			return false
+		}
+		id, _ := d.Get(k[:strings.LastIndex(k, ".")+1] + idAttr).(string)
+		return sensitiveStateUnchanged(d, id, d.Get("sensitive_state_mode").(string), oldo, newo)
+	}
+}
+
+// suppressWriteOnlyDiff suppresses every diff of a write-only attribute of an
+// existing resource. Write-only attributes are never stored in state, so
+// they are only sent on create or when an accompanying version changes.
+func suppressWriteOnlyDiff(k, oldo, newo string, d *schema.ResourceData) bool {
+	return d.Id() != ""
+}
+
+// configReader is implemented by both schema.ResourceData and
+// schema.ResourceDiff, so that configured values can be read during plan too.
+type configReader interface {
+	Get(key string) interface{}
+	GetRawConfig() cty.Value
+}
+
+// configString returns the configured value of an attribute. The value is
+// read from the raw configuration because a suppressed diff leaves the
+// attribute with its state value, which may be a digest or empty.
+func configString(d configReader, key string) string {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(key) {
+		return d.Get(key).(string)
+	}
+
+	v := config.GetAttr(key)
+	if v.IsNull() || !v.IsKnown() {
+		return ""
+	}
+
+	return v.AsString()
+}
+
+// configSet reports whether an attribute is set in the configuration, even if
+// to its zero value.
+func configSet(d configReader, key string) bool {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(key) {
+		v, _ := d.Get(key).(string)
+		return v != ""
+	}
+
+	return !config.GetAttr(key).IsNull()
+}
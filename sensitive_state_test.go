@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestEffectiveSensitiveStateMode(t *testing.T) {
+	cases := []struct {
+		provider, resource, expected string
+	}{
+		{"", sensitiveStatePlain, sensitiveStatePlain},
+		{sensitiveStatePlain, sensitiveStateHash, sensitiveStateHash},
+		{sensitiveStateHash, sensitiveStatePlain, sensitiveStateHash},
+		{sensitiveStateOmit, sensitiveStateHash, sensitiveStateOmit},
+		{sensitiveStateHash, sensitiveStateOmit, sensitiveStateOmit},
+	}
+
+	for _, c := range cases {
+		m := ProviderConfig{SensitiveStateMode: c.provider}
+		if got := effectiveSensitiveStateMode(m, c.resource); got != c.expected {
+			t.Errorf("effectiveSensitiveStateMode(%q, %q) = %q, expected %q", c.provider, c.resource, got, c.expected)
+		}
+	}
+}
+
+func TestSensitiveStateValue(t *testing.T) {
+	if got := sensitiveStateValue(sensitiveStatePlain, "secret"); got != "secret" {
+		t.Errorf("unexpected plain value %q", got)
+	}
+	if got := sensitiveStateValue(sensitiveStateHash, "secret"); got != "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b" {
+		t.Errorf("unexpected hashed value %q", got)
+	}
+	if got := sensitiveStateValue(sensitiveStateOmit, "secret"); got != "" {
+		t.Errorf("unexpected omitted value %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// serverManagedAttributesSchema lists the attributes of a resource that are
+// also changed by Airflow or a system in front of it, e.g. roles appended by
+// Cloud Composer or extras injected by a secrets manager. attrs are the
+// attributes that may be listed.
+func serverManagedAttributesSchema(attrs ...string) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringInSlice(attrs, false),
+		},
+	}
+}
+
+func isServerManaged(d *schema.ResourceData, key string) bool {
+	return d.Get("server_managed_attributes").(*schema.Set).Contains(key)
+}
+
+// setUnlessServerManaged sets an attribute read from Airflow. Server managed
+// attributes keep the value that was last written by Terraform, so that
+// drift in them doesn't show up in plans. They are only set when there's no
+// value yet, e.g. after an import.
+func setUnlessServerManaged(d *schema.ResourceData, key string, value interface{}) error {
+	if isServerManaged(d, key) {
+		if _, ok := d.GetOk(key); ok {
+			log.Printf("[DEBUG] Ignoring the value of server managed attribute `%s` of `%s` read from Airflow", key, d.Id())
+			return nil
+		}
+	}
+
+	return d.Set(key, value)
+}
+
+// sendServerManaged reports whether an attribute is sent on update. Server
+// managed attributes are left out unless their configuration changed, so
+// that the values set by the server are kept.
+func sendServerManaged(d *schema.ResourceData, key string) bool {
+	return !isServerManaged(d, key) || d.HasChange(key)
+}
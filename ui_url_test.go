@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAirflowUiUrl_fake(t *testing.T) {
+	cases := []struct {
+		version string
+		kind    string
+		ids     []string
+		path    string
+	}{
+		{"2.9.3", "dag", []string{"example"}, "/dags/example/grid"},
+		{"2.9.3", "dag_run", []string{"example", "manual__2024-01-01T00:00:00+00:00"}, "/dags/example/grid?dag_run_id=manual__2024-01-01T00%3A00%3A00%2B00%3A00"},
+		{"2.9.3", "user", []string{"jane doe"}, "/users/list/?_flt_0_username=jane+doe"},
+		{"3.0.2", "dag_run", []string{"example", "run 1"}, "/dags/example/runs/run%201"},
+		{"3.0.2", "variable", []string{"foo"}, "/variables?search=foo"},
+	}
+
+	for _, c := range cases {
+		version := c.version
+		fake := newFakeAirflow(t)
+		if strings.HasPrefix(version, "3.") {
+			fake.playAirflow3(version)
+		} else {
+			fake.handle(http.MethodGet, "/version", func(w http.ResponseWriter, r *http.Request) {
+				writeFakeAirflowJSON(w, http.StatusOK, map[string]interface{}{"version": version})
+			})
+		}
+		m := fake.providerConfig(t)
+
+		if got, want := airflowUiUrl(m, c.kind, c.ids...), fake.URL+c.path; got != want {
+			t.Errorf("%s %s on %s: expected %s, got %s", c.kind, c.ids, c.version, want, got)
+		}
+	}
+}